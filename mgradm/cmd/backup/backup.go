@@ -7,13 +7,19 @@ package backup
 import (
 	"errors"
 	"fmt"
+	"path"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/create"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/inspect"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/prune"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/restore"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/schedule"
 	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/verify"
+	cmd_shared "github.com/uyuni-project/uyuni-tools/shared"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
@@ -23,10 +29,10 @@ func newCreateCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.F
 	var flags shared.Flagpole
 
 	createCmd := &cobra.Command{
-		Use:   "create output-directory",
+		Use:   "create output-path",
 		Args:  cobra.ExactArgs(1),
 		Short: L("Create backup"),
-		Long:  L("Create backup of the already configured Uyuni system"),
+		Long:  L("Create backup of the already configured Uyuni system in a directory or an s3://bucket/prefix URL"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, run)
 		},
@@ -35,10 +41,30 @@ func newCreateCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.F
 	createCmd.Flags().StringSlice("skipvolumes", []string{}, L("Skip backup of selected volumes"))
 	createCmd.Flags().StringSlice("extravolumes", []string{}, L("Backup additional volumes to the build-in ones"))
 	createCmd.Flags().Bool("skipdatabase", false, L("Do not backup database volume, allow online backup."))
+	createCmd.Flags().Bool("onlinedatabase", false,
+		L("Backup the database with pg_basebackup while the server is running instead of stopping it"),
+	)
 	createCmd.Flags().Bool("skipimages", false, L("Do not backup container images"))
 	createCmd.Flags().Bool("skipconfig", false, L("Do not backup podman configuration. On restore defaults will be used"))
 	createCmd.Flags().Bool("norestart", false, L("Do not restart services after backup is done"))
 	createCmd.Flags().Bool("dryrun", false, L("Print expected actions, but no action is done"))
+	createCmd.Flags().String("incremental", "",
+		L("Only backup the volumes changes since the backup in the given directory. Restoring requires all the chain"),
+	)
+	createCmd.Flags().Bool("archive", false,
+		L("Write the backup as a single compressed and encrypted archive file instead of a directory"),
+	)
+	createCmd.Flags().String("compression", shared.CompressionZstd,
+		L("Compression of the archive. One of zstd, gzip or none"),
+	)
+	createCmd.Flags().String("agerecipient", "", L("age recipient to encrypt the archive for"))
+	createCmd.Flags().String("gpgrecipient", "", L("GPG key to encrypt the archive for"))
+	addWorkersFlag(createCmd)
+	addS3Flags(createCmd)
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(createCmd)
+	}
 
 	return createCmd
 }
@@ -47,10 +73,14 @@ func newRestoreCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.
 	var flags shared.Flagpole
 
 	restoreCmd := &cobra.Command{
-		Use:   "restore directory",
+		Use:   "restore path",
 		Args:  cobra.ExactArgs(1),
-		Short: L("Restore backup from the directory"),
-		Long:  L("Restore backup of the previously configured Uyuni system from a specified directory"),
+		Short: L("Restore backup from the directory, archive or S3 bucket"),
+		Long: L(`Restore backup of the previously configured Uyuni system from a specified directory,
+archive file or s3://bucket/prefix URL.
+
+Unless --skipverify is set, each volume and image of an S3 backup is downloaded and verified
+before being imported: the staging folder needs enough free space for the largest of them.`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, run)
 		},
@@ -65,10 +95,155 @@ func newRestoreCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.
 	restoreCmd.Flags().Bool("force", false, L("Force overwrite of existing items"))
 	restoreCmd.Flags().Bool("continue", false, L("Skip existing items and restore the rest"))
 	restoreCmd.Flags().Bool("skipverify", false, L("Skip verification of the backup files"))
+	restoreCmd.Flags().String("ageidentity", "", L("Path to the age identity file to decrypt the archive"))
+	restoreCmd.Flags().String("volume", "",
+		L("Only restore this volume, without stopping the server unless it is the database"),
+	)
+	restoreCmd.Flags().StringSlice("path", []string{},
+		L("Only restore these paths relative to the root of the volume. Requires --volume"),
+	)
+	restoreCmd.Flags().String("target", "",
+		L("Extract the volume in this directory instead of the live volume. Requires --volume"),
+	)
+	restoreCmd.Flags().String("new-fqdn", "",
+		L("Change the FQDN of the restored server and generate its certificate using the existing CA"),
+	)
+	addS3Flags(restoreCmd)
+	restoreCmd.Flags().String("s3-stagingdir", "",
+		L("Folder to download the volumes and images to verify them before restoring. Defaults to the temporary folder"),
+	)
+	_ = utils.AddFlagToHelpGroupID(restoreCmd, "s3-stagingdir", "s3")
+	addNewFQDNSSLFlags(restoreCmd)
+
+	if utils.KubernetesBuilt {
+		utils.AddBackendFlag(restoreCmd)
+	}
 
 	return restoreCmd
 }
 
+// addWorkersFlag adds the flag setting how many volumes or images are exported at the same time.
+func addWorkersFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("workers", 4, L("Number of volumes or images to export at the same time"))
+}
+
+// addNewFQDNSSLFlags adds the flags to generate the server certificate when changing the FQDN.
+func addNewFQDNSSLFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ssl-password", "", L("Password of the CA key to generate the server certificate"))
+	cmd.Flags().StringSlice("ssl-cname", []string{}, L("SSL certificate cnames separated by commas"))
+	cmd.Flags().String("ssl-email", "", L("SSL certificate email"))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "ssl", Title: L("SSL Certificate Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-password", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-cname", "ssl")
+	_ = utils.AddFlagToHelpGroupID(cmd, "ssl-email", "ssl")
+}
+
+// addS3Flags adds the flags to connect to the object storage when the backup path is an s3://bucket/prefix URL.
+func addS3Flags(cmd *cobra.Command) {
+	cmd.Flags().String("s3-endpoint", "",
+		L("URL of the S3-compatible object storage. Defaults to the AWS endpoint of the region"),
+	)
+	cmd.Flags().String("s3-region", "", L("Region of the S3 bucket"))
+	cmd.Flags().String("s3-accesskey", "", fmt.Sprintf(
+		L("Access key of the S3 object storage. The secret key is read from the %s environment variable"),
+		shared.S3SecretKeyEnv,
+	))
+
+	_ = utils.AddFlagHelpGroup(cmd, &utils.Group{ID: "s3", Title: L("S3 Storage Flags")})
+	_ = utils.AddFlagToHelpGroupID(cmd, "s3-endpoint", "s3")
+	_ = utils.AddFlagToHelpGroupID(cmd, "s3-region", "s3")
+	_ = utils.AddFlagToHelpGroupID(cmd, "s3-accesskey", "s3")
+}
+
+func newInspectCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.Flagpole]) *cobra.Command {
+	var flags shared.Flagpole
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect path",
+		Args:  cobra.ExactArgs(1),
+		Short: L("Show the manifest of a backup"),
+		Long:  L("Show the server details, images, volumes and files recorded in the manifest of a backup directory"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, run)
+		},
+	}
+	return inspectCmd
+}
+
+func newVerifyCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.Flagpole]) *cobra.Command {
+	var flags shared.Flagpole
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify path",
+		Args:  cobra.ExactArgs(1),
+		Short: L("Verify a backup"),
+		Long:  L("Check that all the files of a backup directory are present and have the expected checksum"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, run)
+		},
+	}
+	return verifyCmd
+}
+
+func newScheduleCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.Flagpole]) *cobra.Command {
+	var flags shared.Flagpole
+
+	scheduleCmd := &cobra.Command{
+		Use:   "schedule [output-path]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: L("Schedule backups"),
+		Long: L(`Generate and enable a systemd timer creating a timestamped backup in the output directory.
+
+Old backups are pruned according to the retention policy after each successful backup.`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, run)
+		},
+	}
+
+	scheduleCmd.Flags().String("schedule", "daily", L("When to run the backups in systemd calendar events format"))
+	addRetentionFlags(scheduleCmd)
+	scheduleCmd.Flags().Bool("disable", false, L("Disable the scheduled backups. Existing backups are kept"))
+	scheduleCmd.Flags().StringSlice("skipvolumes", []string{}, L("Skip backup of selected volumes"))
+	scheduleCmd.Flags().StringSlice("extravolumes", []string{}, L("Backup additional volumes to the build-in ones"))
+	scheduleCmd.Flags().Bool("skipdatabase", false, L("Do not backup database volume, allow online backup."))
+	scheduleCmd.Flags().Bool("onlinedatabase", false,
+		L("Backup the database with pg_basebackup while the server is running instead of stopping it"),
+	)
+	scheduleCmd.Flags().Bool("skipimages", false, L("Do not backup container images"))
+	scheduleCmd.Flags().Bool("skipconfig", false,
+		L("Do not backup podman configuration. On restore defaults will be used"),
+	)
+	addWorkersFlag(scheduleCmd)
+	scheduleCmd.Flags().Bool("dryrun", false, L("Print expected actions, but no action is done"))
+
+	return scheduleCmd
+}
+
+func newPruneCmd(globalFlags *types.GlobalFlags, run utils.CommandFunc[shared.Flagpole]) *cobra.Command {
+	var flags shared.Flagpole
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune path",
+		Args:  cobra.ExactArgs(1),
+		Short: L("Remove old scheduled backups"),
+		Long:  L("Remove the timestamped backups of a directory not matching the retention policy"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, run)
+		},
+	}
+
+	addRetentionFlags(pruneCmd)
+	pruneCmd.Flags().Bool("dryrun", false, L("Print expected actions, but no action is done"))
+
+	return pruneCmd
+}
+
+func addRetentionFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("keepdaily", 7, L("Number of days to keep the latest backup of"))
+	cmd.Flags().Int("keepweekly", 4, L("Number of weeks to keep the latest backup of"))
+}
+
 // NewCommand command for distribution management.
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	backupCmd := &cobra.Command{
@@ -79,6 +254,10 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 	backupCmd.AddCommand(newCreateCmd(globalFlags, doBackup))
 	backupCmd.AddCommand(newRestoreCmd(globalFlags, doRestore))
+	backupCmd.AddCommand(newInspectCmd(globalFlags, inspect.Inspect))
+	backupCmd.AddCommand(newVerifyCmd(globalFlags, verify.Verify))
+	backupCmd.AddCommand(newScheduleCmd(globalFlags, schedule.Schedule))
+	backupCmd.AddCommand(newPruneCmd(globalFlags, prune.Prune))
 	return backupCmd
 }
 
@@ -90,7 +269,11 @@ func doBackup(
 	args []string,
 ) error {
 	outputDirectory := args[0]
-	err := create.Create(global, flags, cmd, args)
+	fn, err := cmd_shared.ChoosePodmanOrKubernetes(cmd.Flags(), create.Create, create.KubernetesCreate)
+	if err != nil {
+		return err
+	}
+	err = fn(global, flags, cmd, args)
 	if err != nil {
 		var backupError *shared.BackupError
 		ok := errors.As(err, &backupError)
@@ -117,7 +300,22 @@ func doRestore(
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	err := restore.Restore(global, flags, cmd, args)
+	// There is no server to detect the backend from on a fresh host: guess it from the backup content
+	if utils.KubernetesBuilt && flags.Backend == "" {
+		backend := "podman"
+		if !shared.IsArchive(args[0]) && utils.FileExists(path.Join(args[0], shared.KubernetesBackupFile)) {
+			backend = "kubectl"
+		}
+		if err := cmd.Flags().Set("backend", backend); err != nil {
+			return err
+		}
+	}
+
+	fn, err := cmd_shared.ChoosePodmanOrKubernetes(cmd.Flags(), restore.Restore, restore.KubernetesRestore)
+	if err != nil {
+		return err
+	}
+	err = fn(global, flags, cmd, args)
 	if err != nil {
 		var backupError *shared.BackupError
 		ok := errors.As(err, &backupError)
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestCreateParamsParsing(t *testing.T) {
+	args := []string{
+		"--skipvolumes", "var-cache,var-log",
+		"--extravolumes", "extra",
+		"--skipdatabase",
+		"--onlinedatabase",
+		"--skipimages",
+		"--skipconfig",
+		"--norestart",
+		"--dryrun",
+		"--incremental", "/backup/base",
+		"--archive",
+		"--workers", "2",
+		"--compression", "gzip",
+		"--agerecipient", "age1xyz",
+		"--gpgrecipient", "admin@example.com",
+		"--s3-endpoint", "http://minio:9000",
+		"--s3-region", "eu-west-1",
+		"--s3-accesskey", "access",
+	}
+	if utils.KubernetesBuilt {
+		args = append(args, "--backend", "kubectl")
+	}
+
+	// Test function asserting that the args are properly parsed
+	tester := func(_ *types.GlobalFlags, flags *shared.Flagpole, _ *cobra.Command, _ []string) error {
+		testutils.AssertEquals(t, "Error parsing --skipvolumes", []string{"var-cache", "var-log"}, flags.SkipVolumes)
+		testutils.AssertEquals(t, "Error parsing --extravolumes", []string{"extra"}, flags.ExtraVolumes)
+		testutils.AssertTrue(t, "Error parsing --skipdatabase", flags.SkipDatabase)
+		testutils.AssertTrue(t, "Error parsing --onlinedatabase", flags.OnlineDatabase)
+		testutils.AssertTrue(t, "Error parsing --skipimages", flags.SkipImages)
+		testutils.AssertTrue(t, "Error parsing --skipconfig", flags.SkipConfig)
+		testutils.AssertTrue(t, "Error parsing --norestart", flags.NoRestart)
+		testutils.AssertTrue(t, "Error parsing --dryrun", flags.DryRun)
+		testutils.AssertEquals(t, "Error parsing --incremental", "/backup/base", flags.Incremental)
+		testutils.AssertTrue(t, "Error parsing --archive", flags.Archive)
+		testutils.AssertEquals(t, "Error parsing --workers", 2, flags.Workers)
+		testutils.AssertEquals(t, "Error parsing --compression", "gzip", flags.Compression)
+		testutils.AssertEquals(t, "Error parsing --agerecipient", "age1xyz", flags.AgeRecipient)
+		testutils.AssertEquals(t, "Error parsing --gpgrecipient", "admin@example.com", flags.GPGRecipient)
+		testutils.AssertEquals(t, "Error parsing --s3-endpoint", "http://minio:9000", flags.S3.Endpoint)
+		testutils.AssertEquals(t, "Error parsing --s3-region", "eu-west-1", flags.S3.Region)
+		testutils.AssertEquals(t, "Error parsing --s3-accesskey", "access", flags.S3.AccessKey)
+		if utils.KubernetesBuilt {
+			testutils.AssertEquals(t, "Error parsing --backend", "kubectl", flags.Backend)
+		}
+		return nil
+	}
+
+	globalFlags := types.GlobalFlags{}
+	cmd := newCreateCmd(&globalFlags, tester)
+
+	testutils.AssertHasAllFlags(t, cmd, args)
+
+	cmd.SetArgs(append(args, "/backup"))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("command failed with error: %s", err)
+	}
+}
+
+func TestRestoreParamsParsing(t *testing.T) {
+	args := []string{
+		"--skipvolumes", "var-cache",
+		"--skipdatabase",
+		"--skipimages",
+		"--skipconfig",
+		"--restart",
+		"--dryRun",
+		"--force",
+		"--continue",
+		"--skipverify",
+		"--ageidentity", "/root/key.txt",
+		"--volume", "srv-pillar",
+		"--path", "some/file,other",
+		"--target", "/tmp/restored",
+		"--new-fqdn", "uyuni.example.com",
+		"--ssl-password", "capass",
+		"--ssl-cname", "uyuni,server",
+		"--ssl-email", "admin@example.com",
+		"--s3-endpoint", "http://minio:9000",
+		"--s3-region", "eu-west-1",
+		"--s3-accesskey", "access",
+		"--s3-stagingdir", "/var/tmp",
+	}
+	if utils.KubernetesBuilt {
+		args = append(args, "--backend", "kubectl")
+	}
+
+	// Test function asserting that the args are properly parsed
+	tester := func(_ *types.GlobalFlags, flags *shared.Flagpole, _ *cobra.Command, _ []string) error {
+		testutils.AssertEquals(t, "Error parsing --skipvolumes", []string{"var-cache"}, flags.SkipVolumes)
+		testutils.AssertTrue(t, "Error parsing --skipdatabase", flags.SkipDatabase)
+		testutils.AssertTrue(t, "Error parsing --skipimages", flags.SkipImages)
+		testutils.AssertTrue(t, "Error parsing --skipconfig", flags.SkipConfig)
+		testutils.AssertTrue(t, "Error parsing --restart", flags.Restart)
+		testutils.AssertTrue(t, "Error parsing --dryRun", flags.DryRun)
+		testutils.AssertTrue(t, "Error parsing --force", flags.ForceRestore)
+		testutils.AssertTrue(t, "Error parsing --continue", flags.SkipExisting)
+		testutils.AssertTrue(t, "Error parsing --skipverify", flags.SkipVerify)
+		testutils.AssertEquals(t, "Error parsing --ageidentity", "/root/key.txt", flags.AgeIdentity)
+		testutils.AssertEquals(t, "Error parsing --volume", "srv-pillar", flags.Volume)
+		testutils.AssertEquals(t, "Error parsing --path", []string{"some/file", "other"}, flags.Paths)
+		testutils.AssertEquals(t, "Error parsing --target", "/tmp/restored", flags.Target)
+		testutils.AssertEquals(t, "Error parsing --new-fqdn", "uyuni.example.com", flags.New.FQDN)
+		testutils.AssertEquals(t, "Error parsing --ssl-password", "capass", flags.SSL.Password)
+		testutils.AssertEquals(t, "Error parsing --ssl-cname", []string{"uyuni", "server"}, flags.SSL.Cnames)
+		testutils.AssertEquals(t, "Error parsing --ssl-email", "admin@example.com", flags.SSL.Email)
+		testutils.AssertEquals(t, "Error parsing --s3-endpoint", "http://minio:9000", flags.S3.Endpoint)
+		testutils.AssertEquals(t, "Error parsing --s3-region", "eu-west-1", flags.S3.Region)
+		testutils.AssertEquals(t, "Error parsing --s3-accesskey", "access", flags.S3.AccessKey)
+		testutils.AssertEquals(t, "Error parsing --s3-stagingdir", "/var/tmp", flags.S3.StagingDir)
+		if utils.KubernetesBuilt {
+			testutils.AssertEquals(t, "Error parsing --backend", "kubectl", flags.Backend)
+		}
+		return nil
+	}
+
+	globalFlags := types.GlobalFlags{}
+	cmd := newRestoreCmd(&globalFlags, tester)
+
+	testutils.AssertHasAllFlags(t, cmd, args)
+
+	cmd.SetArgs(append(args, "/backup"))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("command failed with error: %s", err)
+	}
+}
+
+func TestScheduleParamsParsing(t *testing.T) {
+	args := []string{
+		"--schedule", "Sun 02:00",
+		"--keepdaily", "3",
+		"--keepweekly", "2",
+		"--disable",
+		"--skipvolumes", "var-cache",
+		"--extravolumes", "extra",
+		"--skipdatabase",
+		"--onlinedatabase",
+		"--skipimages",
+		"--skipconfig",
+		"--workers", "2",
+		"--dryrun",
+	}
+
+	// Test function asserting that the args are properly parsed
+	tester := func(_ *types.GlobalFlags, flags *shared.Flagpole, _ *cobra.Command, _ []string) error {
+		testutils.AssertEquals(t, "Error parsing --schedule", "Sun 02:00", flags.Schedule)
+		testutils.AssertEquals(t, "Error parsing --keepdaily", 3, flags.KeepDaily)
+		testutils.AssertEquals(t, "Error parsing --keepweekly", 2, flags.KeepWeekly)
+		testutils.AssertTrue(t, "Error parsing --disable", flags.Disable)
+		testutils.AssertEquals(t, "Error parsing --skipvolumes", []string{"var-cache"}, flags.SkipVolumes)
+		testutils.AssertEquals(t, "Error parsing --extravolumes", []string{"extra"}, flags.ExtraVolumes)
+		testutils.AssertTrue(t, "Error parsing --skipdatabase", flags.SkipDatabase)
+		testutils.AssertTrue(t, "Error parsing --onlinedatabase", flags.OnlineDatabase)
+		testutils.AssertTrue(t, "Error parsing --skipimages", flags.SkipImages)
+		testutils.AssertTrue(t, "Error parsing --skipconfig", flags.SkipConfig)
+		testutils.AssertEquals(t, "Error parsing --workers", 2, flags.Workers)
+		testutils.AssertTrue(t, "Error parsing --dryrun", flags.DryRun)
+		return nil
+	}
+
+	globalFlags := types.GlobalFlags{}
+	cmd := newScheduleCmd(&globalFlags, tester)
+
+	testutils.AssertHasAllFlags(t, cmd, args)
+
+	cmd.SetArgs(append(args, "/backup"))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("command failed with error: %s", err)
+	}
+}
+
+func TestPruneParamsParsing(t *testing.T) {
+	args := []string{
+		"--keepdaily", "3",
+		"--keepweekly", "2",
+		"--dryrun",
+	}
+
+	// Test function asserting that the args are properly parsed
+	tester := func(_ *types.GlobalFlags, flags *shared.Flagpole, _ *cobra.Command, _ []string) error {
+		testutils.AssertEquals(t, "Error parsing --keepdaily", 3, flags.KeepDaily)
+		testutils.AssertEquals(t, "Error parsing --keepweekly", 2, flags.KeepWeekly)
+		testutils.AssertTrue(t, "Error parsing --dryrun", flags.DryRun)
+		return nil
+	}
+
+	globalFlags := types.GlobalFlags{}
+	cmd := newPruneCmd(&globalFlags, tester)
+
+	testutils.AssertHasAllFlags(t, cmd, args)
+
+	cmd.SetArgs(append(args, "/backup"))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("command failed with error: %s", err)
+	}
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	podman_mgradm "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// createArchive writes the backup into a single compressed and encrypted archive.
+//
+// Nothing is written unencrypted on the disk, except the container images exported
+// temporarily before being added to the archive.
+func createArchive(flags *shared.Flagpole, archivePath string) error {
+	dryRun := flags.DryRun
+
+	if err := archiveSanityChecks(archivePath, flags); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	volumes := gatherVolumesToBackup(flags.ExtraVolumes, flags.SkipVolumes, flags.SkipDatabase)
+	images := gatherContainerImagesToBackup(flags.SkipImages)
+
+	if dryRun {
+		log.Info().Msgf(L("Would write volumes %[1]s and images %[2]s into the encrypted archive %[3]s"),
+			volumes, images, archivePath,
+		)
+		return nil
+	}
+
+	// The compression reduces the actual size: that's an over estimation
+	if _, err := shared.StorageCheck(volumes, images, path.Dir(archivePath), ""); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	archive, err := shared.NewArchiveWriter(archivePath, flags.Compression, flags.AgeRecipient, flags.GPGRecipient)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	// stop service if database is to be backed up. Otherwise do a live backup
+	serviceStopped := false
+	if !flags.SkipDatabase {
+		log.Info().Msg(L("Stopping server service"))
+		if err := podman_mgradm.StopServices(); err != nil {
+			archive.Abort()
+			return shared.AbortError(err, false)
+		}
+		serviceStopped = true
+	}
+
+	if err := backupVolumesToArchive(archive, volumes); err != nil {
+		archive.Abort()
+		return shared.AbortError(err, false)
+	}
+
+	// Remaining backups are not critical, restore can create default values
+	// so let's only track if there was an error
+	hasError := backupImagesToArchive(archive, images)
+	hasError = utils.JoinErrors(hasError, backupSystemdServicesToArchive(archive))
+	hasError = utils.JoinErrors(hasError, backupPodmanConfigurationToArchive(archive))
+
+	if err := archive.Close(); err != nil {
+		return shared.AbortError(utils.Errorf(err, L("failed to write the backup archive")), true)
+	}
+
+	// start service if it was stopped before
+	if serviceStopped && !flags.NoRestart {
+		log.Info().Msg(L("Restarting server service"))
+		hasError = utils.JoinErrors(hasError, podman_mgradm.StartServices())
+	}
+
+	log.Info().Msgf(L("Backup finished into %s"), archivePath)
+	return shared.ReportError(hasError)
+}
+
+func archiveSanityChecks(archivePath string, flags *shared.Flagpole) error {
+	if err := shared.SanityChecks(); err != nil {
+		return err
+	}
+
+	if flags.Incremental != "" {
+		return errors.New(L("incremental backups cannot be written as archive"))
+	}
+
+	if flags.OnlineDatabase {
+		return errors.New(L("online database backups cannot be written as archive"))
+	}
+
+	if utils.FileExists(archivePath) {
+		return fmt.Errorf(L("output archive %s already exists"), archivePath)
+	}
+
+	if flags.AgeRecipient == "" && flags.GPGRecipient == "" {
+		return errors.New(L("an age or GPG recipient is required to encrypt the archive"))
+	}
+
+	hostData, err := podman.InspectHost()
+	if err != nil {
+		return err
+	}
+
+	if !hostData.HasUyuniServer {
+		return errors.New(L("server is not initialized."))
+	}
+	return nil
+}
+
+func backupVolumesToArchive(archive *shared.ArchiveWriter, volumes []string) error {
+	log.Info().Msg(L("Backing up container volumes"))
+	for _, volume := range volumes {
+		if !podman.IsVolumePresent(volume) {
+			continue
+		}
+		log.Debug().Msgf("Backing up %s volume", volume)
+		mountPoint, err := podman.GetVolumeMountPoint(volume)
+		if err != nil {
+			return err
+		}
+		if err := archive.AddVolume(volume, mountPoint); err != nil {
+			return utils.Errorf(err, L("Failed to export volume %s"), volume)
+		}
+	}
+	return nil
+}
+
+func backupImagesToArchive(archive *shared.ArchiveWriter, images []string) error {
+	if len(images) == 0 {
+		return nil
+	}
+	log.Info().Msg(L("Backing up container images"))
+
+	// Images are public: they can be temporarily exported before being added to the archive
+	tempDir, cleaner, err := utils.TempDir()
+	if err != nil {
+		return err
+	}
+	defer cleaner()
+
+	var hasError error
+	for _, image := range images {
+		log.Debug().Msgf("Backing up image %s", image)
+		imageFile := path.Join(tempDir, image+".tar")
+		err := podman.ExportImage(image, tempDir, false)
+		if err == nil {
+			err = archive.AddFile(path.Join(shared.ImagesSubdir, image+".tar"), imageFile)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf(L("Not backing up image %s"), image)
+			hasError = utils.JoinErrors(hasError, err)
+		}
+	}
+	return hasError
+}
+
+func backupSystemdServicesToArchive(archive *shared.ArchiveWriter) error {
+	log.Info().Msg(L("Backing up Systemd services"))
+	var buffer bytes.Buffer
+	err := writeSystemdConfiguration(&buffer, gatherSystemdItems())
+	if err == nil {
+		err = archive.AddBytes(shared.SystemdConfBackupFile, buffer.Bytes())
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Systemd services and configuration was not backed up"))
+	}
+	return err
+}
+
+// backupPodmanConfigurationToArchive adds the podman network and secrets to the archive.
+// The configuration is prepared in memory to never write the secrets unencrypted.
+func backupPodmanConfigurationToArchive(archive *shared.ArchiveWriter) error {
+	log.Info().Msg(L("Backing up podman configuration"))
+	network, errNetwork := backupPodmanNetwork(false)
+	secrets, errPodman := backupPodmanSecrets(false)
+
+	var buffer bytes.Buffer
+	hasError := writePodmanConfiguration(&buffer, network, errNetwork, secrets, errPodman)
+	if err := archive.AddBytes(shared.PodmanConfBackupFile, buffer.Bytes()); err != nil {
+		hasError = utils.JoinErrors(hasError, err)
+	}
+	if hasError != nil {
+		log.Warn().Err(hasError).Msg(L("Podman configuration was not backed up"))
+	}
+	return hasError
+}
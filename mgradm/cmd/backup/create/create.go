@@ -33,10 +33,26 @@ func Create(
 	outputDirectory := args[0]
 	printIntro(outputDirectory, flags)
 
+	if shared.IsS3URL(outputDirectory) {
+		return createS3Backup(flags, outputDirectory)
+	}
+
+	if flags.Archive {
+		return createArchive(flags, outputDirectory)
+	}
+
 	if err := SanityChecks(outputDirectory); err != nil {
 		return shared.AbortError(err, false)
 	}
 
+	baseDirectory := ""
+	if flags.Incremental != "" {
+		var err error
+		if baseDirectory, err = prepareIncrementalBackup(flags.Incremental, outputDirectory); err != nil {
+			return shared.AbortError(err, false)
+		}
+	}
+
 	volumesBackupPath := path.Join(outputDirectory, shared.VolumesSubdir)
 	imagesBackupPath := path.Join(outputDirectory, shared.ImagesSubdir)
 
@@ -44,18 +60,38 @@ func Create(
 		return shared.AbortError(err, false)
 	}
 
+	if baseDirectory != "" {
+		if err := writeIncrementalData(baseDirectory, outputDirectory, dryRun); err != nil {
+			return shared.AbortError(err, true)
+		}
+	}
+
 	volumes := gatherVolumesToBackup(flags.ExtraVolumes, flags.SkipVolumes, flags.SkipDatabase)
 	images := gatherContainerImagesToBackup(flags.SkipImages)
 
+	sizes := map[string]int64{}
 	if !dryRun {
-		if err := shared.StorageCheck(volumes, images, outputDirectory); err != nil {
+		var err error
+		if sizes, err = shared.StorageCheck(volumes, images, outputDirectory, baseDirectory); err != nil {
 			return shared.AbortError(err, false)
 		}
 	}
 
-	// stop service if database is to be backed up. Otherwise do a live backup
+	// An online database backup is taken with pg_basebackup rather than exporting the volume
+	onlineDatabase := flags.OnlineDatabase && !flags.SkipDatabase
+	if onlineDatabase {
+		volumes = gatherVolumesToBackup(flags.ExtraVolumes, flags.SkipVolumes, true)
+		if err := backupDatabaseOnline(volumesBackupPath, outputDirectory, dryRun); err != nil {
+			return shared.AbortError(err, true)
+		}
+	}
+
+	// The server details can only be inspected while it is running
+	manifest := newPodmanManifest(flags, baseDirectory, onlineDatabase)
+
+	// stop service if database is to be backed up offline. Otherwise do a live backup
 	serviceStopped := false
-	if !flags.SkipDatabase && !dryRun {
+	if !flags.SkipDatabase && !onlineDatabase && !dryRun {
 		log.Info().Msg(L("Stopping server service"))
 		if err := podman_mgradm.StopServices(); err != nil {
 			return shared.AbortError(err, false)
@@ -63,13 +99,13 @@ func Create(
 		serviceStopped = true
 	}
 
-	if err := backupVolumes(volumes, volumesBackupPath, dryRun); err != nil {
+	if err := backupVolumes(volumes, volumesBackupPath, baseDirectory, sizes, flags.Workers, dryRun); err != nil {
 		return shared.AbortError(err, true)
 	}
 
 	// Remaining backups are not critical, restore can create default values
 	// so let's only track if there was an error
-	hasError := backupContainerImages(images, imagesBackupPath, dryRun)
+	hasError := backupContainerImages(images, imagesBackupPath, sizes, flags.Workers, dryRun)
 
 	// systemd configuration backup is optional as we have defaults to use
 	hasError = utils.JoinErrors(hasError, backupSystemdServices(outputDirectory, dryRun))
@@ -83,6 +119,9 @@ func Create(
 		hasError = utils.JoinErrors(hasError, podman_mgradm.StartServices())
 	}
 
+	// The manifest lists all the files: it has to be written last
+	hasError = utils.JoinErrors(hasError, writeManifest(manifest, outputDirectory, dryRun))
+
 	log.Info().Msgf(L("Backup finished into %s"), outputDirectory)
 	return shared.ReportError(hasError)
 }
@@ -92,11 +131,15 @@ func printIntro(outputDir string, flags *shared.Flagpole) {
 	log.Debug().Msgf("output directory: %s", outputDir)
 	log.Debug().Msgf("dry run: %t", flags.DryRun)
 	log.Debug().Msgf("skip database: %t", flags.SkipDatabase)
+	log.Debug().Msgf("online database: %t", flags.OnlineDatabase)
 	log.Debug().Msgf("skip config: %t", flags.SkipConfig)
 	log.Debug().Msgf("skip restart: %t", flags.NoRestart)
 	log.Debug().Msgf("skip images: %t", flags.SkipImages)
 	log.Debug().Msgf("skip volumes: %s", flags.SkipVolumes)
 	log.Debug().Msgf("extra volumes: %s", flags.ExtraVolumes)
+	log.Debug().Msgf("incremental base: %s", flags.Incremental)
+	log.Debug().Msgf("archive: %t", flags.Archive)
+	log.Debug().Msgf("workers: %d", flags.Workers)
 }
 
 func prepareOuputDirs(outputDirs []string, dryRun bool) error {
@@ -145,15 +188,45 @@ func gatherVolumesToBackup(extraVolumes []string, skipVolumes []string, skipData
 	return uniqueVolumes
 }
 
-func backupVolumes(volumes []string, outputDirectory string, dryRun bool) error {
+// backupVolumes exports the volumes and their manifest using several workers.
+// If baseDirectory is set, only the changes since the backup in this folder are exported.
+// No volume export is started after a failure.
+func backupVolumes(
+	volumes []string,
+	outputDirectory string,
+	baseDirectory string,
+	sizes map[string]int64,
+	workers int,
+	dryRun bool,
+) error {
 	log.Info().Msg(L("Backing up container volumes"))
+	tasks := []exportTask{}
 	for _, volume := range volumes {
-		log.Debug().Msgf("Backing up %s volume", volume)
-		if err := podman.ExportVolume(volume, outputDirectory, dryRun); err != nil {
-			return err
+		volume := volume
+		if !dryRun && !podman.IsVolumePresent(volume) {
+			continue
 		}
+		tasks = append(tasks, exportTask{
+			name: volume,
+			file: path.Join(outputDirectory, volume+".tar"),
+			size: sizes[volume],
+			export: func() error {
+				return backupVolume(volume, outputDirectory, baseDirectory, dryRun)
+			},
+		})
 	}
-	return nil
+	return runExportTasks(tasks, workers, true, dryRun)
+}
+
+func backupVolume(volume string, outputDirectory string, baseDirectory string, dryRun bool) error {
+	log.Debug().Msgf("Backing up %s volume", volume)
+	if baseDirectory != "" {
+		return exportVolumeDelta(volume, baseDirectory, outputDirectory, dryRun)
+	}
+	if dryRun || !podman.IsVolumePresent(volume) {
+		return podman.ExportVolume(volume, outputDirectory, dryRun)
+	}
+	return exportFullVolume(volume, outputDirectory)
 }
 
 func gatherContainerImagesToBackup(skipImages bool) []string {
@@ -169,17 +242,33 @@ func gatherContainerImagesToBackup(skipImages bool) []string {
 	return images
 }
 
-func backupContainerImages(images []string, outputDirectory string, dryRun bool) error {
+// backupContainerImages exports the images using several workers.
+func backupContainerImages(
+	images []string,
+	outputDirectory string,
+	sizes map[string]int64,
+	workers int,
+	dryRun bool,
+) error {
 	log.Info().Msg(L("Backing up container images"))
-	var hasError error
+	tasks := []exportTask{}
 	for _, image := range images {
-		log.Debug().Msgf("Backing up image %s", image)
-		if err := podman.ExportImage(image, outputDirectory, dryRun); err != nil {
-			log.Warn().Err(err).Msgf(L("Not backing up image %s"), image)
-			hasError = utils.JoinErrors(hasError, err)
-		}
+		image := image
+		tasks = append(tasks, exportTask{
+			name: image,
+			file: path.Join(outputDirectory, image+".tar"),
+			size: sizes[image],
+			export: func() error {
+				log.Debug().Msgf("Backing up image %s", image)
+				err := podman.ExportImage(image, outputDirectory, dryRun)
+				if err != nil {
+					log.Warn().Err(err).Msgf(L("Not backing up image %s"), image)
+				}
+				return err
+			},
+		})
 	}
-	return hasError
+	return runExportTasks(tasks, workers, false, dryRun)
 }
 
 func backupSystemdServices(outputDirectory string, dryRun bool) error {
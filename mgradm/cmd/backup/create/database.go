@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// backupDatabaseOnline runs pg_basebackup in the database container while the server is running.
+//
+// The result is stored as the database volume export since it is a copy of the data directory
+// with the write-ahead log files needed to make it consistent.
+func backupDatabaseOnline(volumesBackupPath string, outputDirectory string, dryRun bool) error {
+	volume := utils.VarPgsqlDataVolumeMount.Name
+	outputFile := path.Join(volumesBackupPath, volume+".tar")
+	command := []string{
+		"podman", "exec", "-u", "postgres", podman.DBContainerName,
+		"pg_basebackup", "-D", "-", "-F", "tar", "-X", "fetch", "--checkpoint=fast", "-l", "uyuni backup",
+	}
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), strings.Join(command, " "))
+		return nil
+	}
+
+	log.Info().Msg(L("Backing up the database online"))
+	out, err := os.OpenFile(outputFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return utils.Errorf(err, L("failed to create %s"), outputFile)
+	}
+	_, err = utils.NewRunner(command[0], command[1:]...).Spinner(L("Backing up the database")).Stdout(out).Exec()
+	out.Close()
+	if err != nil {
+		return utils.Errorf(err, L("failed to backup the database"))
+	}
+	if err := utils.CreateChecksum(outputFile); err != nil {
+		return utils.Errorf(err, L("Failed to write checksum of volume %[1]s to the %[2]s"), volume, outputFile+".sha256sum")
+	}
+
+	data, err := shared.ReadBackupLabel(outputFile)
+	if err != nil {
+		return err
+	}
+	data.Method = "pg_basebackup"
+	log.Info().Msgf(L("Database backed up at write-ahead log location %s"), data.StartWALLocation)
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	dataPath := path.Join(outputDirectory, shared.DatabaseBackupFile)
+	if err := os.WriteFile(dataPath, content, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write %s"), dataPath)
+	}
+	return nil
+}
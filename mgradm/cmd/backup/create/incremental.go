@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// prepareIncrementalBackup checks the base backup and returns its absolute path.
+func prepareIncrementalBackup(baseDirectory string, outputDirectory string) (string, error) {
+	absBase, err := filepath.Abs(baseDirectory)
+	if err != nil {
+		return "", err
+	}
+	absOutput, err := filepath.Abs(outputDirectory)
+	if err != nil {
+		return "", err
+	}
+	if absBase == absOutput {
+		return "", errors.New(L("the base backup cannot be the output directory"))
+	}
+	if !utils.FileExists(path.Join(absBase, shared.VolumesSubdir)) {
+		return "", fmt.Errorf(L("%s is not a backup directory"), baseDirectory)
+	}
+	return absBase, nil
+}
+
+// writeIncrementalData stores the reference to the base backup in the output directory.
+//
+// The base path is relative to the output directory to allow moving the backups together.
+func writeIncrementalData(baseDirectory string, outputDirectory string, dryRun bool) error {
+	if dryRun {
+		log.Info().Msgf(L("Would write an incremental backup based on %s"), baseDirectory)
+		return nil
+	}
+	absOutput, err := filepath.Abs(outputDirectory)
+	if err != nil {
+		return err
+	}
+	base, err := filepath.Rel(absOutput, baseDirectory)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(shared.IncrementalData{Base: base})
+	if err != nil {
+		return err
+	}
+	dataPath := path.Join(outputDirectory, shared.IncrementalBackupFile)
+	if err := os.WriteFile(dataPath, content, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write %s"), dataPath)
+	}
+	return nil
+}
+
+// exportFullVolume exports a volume and stores the list of its files for future incremental backups.
+//
+// The hashes of the files are computed from the exported stream to avoid reading the volume twice.
+func exportFullVolume(volume string, outputDirectory string) error {
+	mountPoint, err := podman.GetVolumeMountPoint(volume)
+	if err != nil {
+		return err
+	}
+	manifest, err := shared.ScanVolume(mountPoint)
+	if err != nil {
+		return utils.Errorf(err, L("failed to list the files of volume %s"), volume)
+	}
+	manifest.Full = true
+
+	outputFile := path.Join(outputDirectory, volume+".tar")
+	out, err := os.Create(outputFile)
+	if err != nil {
+		return utils.Errorf(err, L("failed to create %s"), outputFile)
+	}
+	defer out.Close()
+
+	reader, writer := io.Pipe()
+	hashed := make(chan error, 1)
+	go func() {
+		err := shared.HashTarball(manifest, reader)
+		// Keep reading to not block the export
+		if _, drainErr := io.Copy(io.Discard, reader); err == nil {
+			err = drainErr
+		}
+		hashed <- err
+	}()
+
+	log.Info().Msgf(L("Exporting volume %s"), volume)
+	_, err = utils.NewRunner("podman", "volume", "export", volume).Stdout(io.MultiWriter(out, writer)).Exec()
+	writer.CloseWithError(err)
+	hashErr := <-hashed
+	if err != nil {
+		return utils.Errorf(err, L("Failed to export volume %s"), volume)
+	}
+	if hashErr != nil {
+		return utils.Errorf(hashErr, L("failed to compute the hashes of the files of volume %s"), volume)
+	}
+	if err := out.Close(); err != nil {
+		return utils.Errorf(err, L("Failed to export volume %s"), volume)
+	}
+
+	if err := utils.CreateChecksum(outputFile); err != nil {
+		return utils.Errorf(err, L("Failed to write checksum of volume %[1]s to the %[2]s"), volume, outputFile+".sha256sum")
+	}
+	return shared.WriteManifest(manifest, shared.ManifestPath(outputDirectory, volume))
+}
+
+// exportVolumeDelta writes the changes of a volume since the base backup with the new manifest.
+func exportVolumeDelta(volume string, baseDirectory string, outputDirectory string, dryRun bool) error {
+	if dryRun {
+		log.Info().Msgf(L("Would export the changes of volume %[1]s since %[2]s"), volume, baseDirectory)
+		return nil
+	}
+	if !podman.IsVolumePresent(volume) {
+		return nil
+	}
+
+	mountPoint, err := podman.GetVolumeMountPoint(volume)
+	if err != nil {
+		return err
+	}
+	base, err := shared.ReadBaseManifest(baseDirectory, volume)
+	if err != nil {
+		return err
+	}
+	delta, err := shared.DiffVolume(base, mountPoint)
+	if err != nil {
+		return utils.Errorf(err, L("failed to compute the changes of volume %s"), volume)
+	}
+	// Without base files, the delta contains the whole volume
+	delta.Manifest.Full = len(base.Files) == 0
+	log.Info().Msgf(L("Exporting %[1]d changed and %[2]d deleted files of volume %[3]s"),
+		len(delta.Changed), len(delta.Deleted), volume,
+	)
+
+	outputFile := path.Join(outputDirectory, volume+".tar")
+	if err := shared.WriteDelta(delta, outputFile); err != nil {
+		return utils.Errorf(err, L("Failed to export volume %s"), volume)
+	}
+	if err := utils.CreateChecksum(outputFile); err != nil {
+		return utils.Errorf(err, L("Failed to write checksum of volume %[1]s to the %[2]s"), volume, outputFile+".sha256sum")
+	}
+	return shared.WriteManifest(delta.Manifest, shared.ManifestPath(outputDirectory, volume))
+}
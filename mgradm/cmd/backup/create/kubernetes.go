@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package create
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	adm_kubernetes "github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
+	cmd_shared "github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// KubernetesCreate creates a backup of a server running on a kubernetes cluster.
+//
+// The volumes are streamed from a job mounting the persistent volume claims and
+// the server objects are stored to be recreated on restore.
+func KubernetesCreate(
+	_ *types.GlobalFlags,
+	flags *shared.Flagpole,
+	_ *cobra.Command,
+	args []string,
+) error {
+	dryRun := flags.DryRun
+	outputDirectory := args[0]
+	printIntro(outputDirectory, flags)
+
+	if flags.Incremental != "" {
+		return shared.AbortError(errors.New(L("incremental backups are not supported on kubernetes")), false)
+	}
+	if shared.IsS3URL(outputDirectory) {
+		return shared.AbortError(errors.New(L("S3 backups are not supported on kubernetes")), false)
+	}
+	if flags.Archive {
+		return shared.AbortError(errors.New(L("archive backups are not supported on kubernetes")), false)
+	}
+	if flags.OnlineDatabase {
+		return shared.AbortError(errors.New(L("online database backups are not supported on kubernetes")), false)
+	}
+
+	if err := kubernetesSanityChecks(outputDirectory); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	cnx := cmd_shared.NewConnection("kubectl", "", kubernetes.ServerFilter)
+	namespace, err := cnx.GetNamespace("")
+	if err != nil {
+		return shared.AbortError(utils.Errorf(err, L("failed retrieving namespace")), false)
+	}
+
+	volumesBackupPath := path.Join(outputDirectory, shared.VolumesSubdir)
+	if err := prepareOuputDirs([]string{outputDirectory, volumesBackupPath}, dryRun); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	if !flags.SkipImages {
+		log.Info().Msg(L("Container images are not backed up on kubernetes, they will be pulled again on restore"))
+	}
+
+	// Only the volumes with a claim in the namespace can be backed up
+	volumes := []string{}
+	for _, volume := range gatherVolumesToBackup(flags.ExtraVolumes, flags.SkipVolumes, flags.SkipDatabase) {
+		if kubernetes.HasVolume(namespace, volume) {
+			volumes = append(volumes, volume)
+		} else {
+			log.Debug().Msgf("No bound claim for volume %s, skipping", volume)
+		}
+	}
+
+	image, err := kubernetes.GetRunningImage("uyuni")
+	if err != nil {
+		return shared.AbortError(utils.Errorf(err, L("failed to find the server image")), true)
+	}
+
+	var manifest *shared.BackupManifest
+	if dryRun {
+		manifest = shared.NewBackupManifest("kubectl")
+	} else {
+		manifest = prepareManifest(cnx, "kubectl")
+	}
+	manifest.Images = []shared.ImageManifestData{{Container: "uyuni", Image: image}}
+
+	// The server objects are needed to know the replicas before scaling down
+	hasError := backupKubernetesConfiguration(namespace, image, outputDirectory, dryRun)
+
+	pullSecret, err := kubernetes.GetDeploymentImagePullSecret(namespace, kubernetes.ServerFilter)
+	if err != nil {
+		return shared.AbortError(err, true)
+	}
+
+	// The job needs to run on the node of the server to access ReadWriteOnce volumes.
+	node, err := kubernetes.GetNode(namespace, kubernetes.ServerFilter)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to get the server node, letting the cluster schedule the backup job")
+		node = ""
+	}
+
+	// Scale the deployments down if database is to be backed up. Otherwise do a live backup
+	var replicas map[string]int
+	if !flags.SkipDatabase && !dryRun {
+		log.Info().Msg(L("Stopping server deployments"))
+		replicas, err = adm_kubernetes.GetDeploymentsReplicas(namespace)
+		if err != nil {
+			return shared.AbortError(err, true)
+		}
+		stopped := map[string]int{}
+		for name := range replicas {
+			stopped[name] = 0
+		}
+		if err := adm_kubernetes.ScaleDeployments(namespace, stopped); err != nil {
+			return shared.AbortError(err, true)
+		}
+	}
+
+	if err := backupKubernetesVolumes(
+		namespace, image, pullSecret, node, volumes, volumesBackupPath, outputDirectory, dryRun,
+	); err != nil {
+		return shared.AbortError(err, true)
+	}
+
+	// Scale the deployments back if they were stopped before
+	if replicas != nil && !flags.NoRestart {
+		log.Info().Msg(L("Restarting server deployments"))
+		hasError = utils.JoinErrors(hasError, adm_kubernetes.ScaleDeployments(namespace, replicas))
+	}
+
+	// The manifest lists all the files: it has to be written last
+	hasError = utils.JoinErrors(hasError, writeManifest(manifest, outputDirectory, dryRun))
+
+	log.Info().Msgf(L("Backup finished into %s"), outputDirectory)
+	return shared.ReportError(hasError)
+}
+
+func backupKubernetesVolumes(
+	namespace string,
+	image string,
+	pullSecret string,
+	node string,
+	volumes []string,
+	volumesBackupPath string,
+	outputDirectory string,
+	dryRun bool,
+) error {
+	if dryRun {
+		log.Info().Msgf(L("Would start a job mounting volumes %s"), volumes)
+		for _, volume := range volumes {
+			if err := adm_kubernetes.ExportVolume(namespace, "", volume, volumesBackupPath, dryRun); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	jobName, podName, err := adm_kubernetes.StartVolumesAccessJob(
+		namespace, image, utils.DefaultPullPolicy, pullSecret, node, volumes,
+	)
+	defer func() {
+		if jobName == "" {
+			return
+		}
+		if err := adm_kubernetes.DeleteJob(namespace, jobName); err != nil {
+			log.Warn().Err(err).Msgf(L("failed to delete the %s job"), jobName)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	var spaceRequired int64
+	for _, volume := range volumes {
+		size, err := adm_kubernetes.GetVolumeSize(namespace, podName, volume)
+		if err != nil {
+			return err
+		}
+		spaceRequired += size
+	}
+	if err := shared.CheckFreeSpace(outputDirectory, spaceRequired); err != nil {
+		return err
+	}
+
+	log.Info().Msg(L("Backing up persistent volumes"))
+	for _, volume := range volumes {
+		log.Debug().Msgf("Backing up %s volume", volume)
+		if err := adm_kubernetes.ExportVolume(namespace, podName, volume, volumesBackupPath, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupKubernetesConfiguration stores the server objects and helm releases values.
+func backupKubernetesConfiguration(namespace string, serverImage string, outputDirectory string, dryRun bool) error {
+	errorMessage := L("Kubernetes objects were not backed up")
+	log.Info().Msg(L("Backing up kubernetes objects"))
+	if dryRun {
+		log.Info().Msgf(L("Would store the kubernetes objects and helm values of namespace %s"), namespace)
+		return nil
+	}
+
+	data, err := getKubernetesBackupData(namespace, serverImage)
+	if err != nil {
+		log.Warn().Err(err).Msg(errorMessage)
+		return err
+	}
+
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		log.Warn().Err(err).Msg(errorMessage)
+		return err
+	}
+
+	backupFile := path.Join(outputDirectory, shared.KubernetesBackupFile)
+	if err := os.WriteFile(backupFile, output, 0600); err != nil {
+		log.Warn().Err(err).Msg(errorMessage)
+		return err
+	}
+	if err := utils.CreateChecksum(backupFile); err != nil {
+		log.Warn().Err(err).Msg(errorMessage)
+		return err
+	}
+	return nil
+}
+
+func getKubernetesBackupData(namespace string, serverImage string) (*shared.KubernetesBackupData, error) {
+	objects, err := adm_kubernetes.GetBackupObjects(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data := shared.KubernetesBackupData{
+		Namespace:   namespace,
+		ServerImage: serverImage,
+		Objects:     objects,
+	}
+
+	if !utils.IsInstalled("helm") {
+		log.Debug().Msg("helm is not installed, not storing the releases values")
+		return &data, nil
+	}
+
+	clusterInfos, err := kubernetes.CheckCluster()
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig := clusterInfos.GetKubeconfig()
+	releases, err := kubernetes.GetHelmReleases(namespace, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		values, err := kubernetes.GetHelmValues(namespace, kubeconfig, release.Name)
+		if err != nil {
+			return nil, err
+		}
+		data.HelmReleases = append(data.HelmReleases, shared.HelmReleaseData{
+			Name:       release.Name,
+			Chart:      release.Chart,
+			AppVersion: release.AppVersion,
+			Values:     values,
+		})
+	}
+	return &data, nil
+}
+
+func kubernetesSanityChecks(outputDirectory string) error {
+	if err := shared.KubernetesSanityChecks(); err != nil {
+		return err
+	}
+
+	if utils.FileExists(outputDirectory) {
+		if !utils.IsEmptyDirectory(outputDirectory) {
+			return fmt.Errorf(L("output directory %s already exists and is not empty"), outputDirectory)
+		}
+	}
+
+	cnx := cmd_shared.NewConnection("kubectl", "", kubernetes.ServerFilter)
+	namespace, err := cnx.GetNamespace("")
+	if err != nil || namespace == "" {
+		return errors.New(L("server is not initialized."))
+	}
+	if !kubernetes.HasDeployment(namespace, kubernetes.ServerFilter) {
+		return errors.New(L("server is not initialized."))
+	}
+	return nil
+}
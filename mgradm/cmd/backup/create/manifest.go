@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	cmd_shared "github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+)
+
+// prepareManifest collects the server details needed for the manifest while the server is still running.
+//
+// Failing to get those details is not critical: the manifest will only miss them.
+func prepareManifest(cnx *cmd_shared.Connection, backend string) *shared.BackupManifest {
+	manifest := shared.NewBackupManifest(backend)
+	data, err := shared.InspectRunningServer(cnx)
+	if err != nil {
+		log.Warn().Err(err).Msg(L("The server details will be missing from the backup manifest"))
+	} else {
+		manifest.SetServerData(data)
+	}
+	return manifest
+}
+
+// addPodmanImagesToManifest records the images and digests of the running containers.
+func addPodmanImagesToManifest(manifest *shared.BackupManifest) {
+	for _, container := range []string{podman.ServerContainerName, podman.DBContainerName} {
+		image, err := podman.GetRunningImage(container)
+		image = strings.Trim(image, "'")
+		if err != nil || image == "" {
+			log.Debug().Err(err).Msgf("No running image for container %s", container)
+			continue
+		}
+		digest, err := podman.GetImageDigest(image)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Failed to get the digest of image %s", image)
+		}
+		manifest.Images = append(manifest.Images, shared.ImageManifestData{
+			Container: container,
+			Image:     image,
+			Digest:    digest,
+		})
+	}
+}
+
+// writeManifest writes the backup manifest once all the files are in the output directory.
+func writeManifest(manifest *shared.BackupManifest, outputDirectory string, dryRun bool) error {
+	if dryRun {
+		log.Info().Msgf(L("Would write the backup manifest in %s"), outputDirectory)
+		return nil
+	}
+	log.Info().Msg(L("Writing the backup manifest"))
+	if err := shared.WriteBackupManifest(manifest, outputDirectory); err != nil {
+		log.Warn().Err(err).Msg(L("Backup manifest was not written"))
+		return err
+	}
+	return nil
+}
+
+// newPodmanManifest prepares the manifest of a backup of a podman server.
+func newPodmanManifest(flags *shared.Flagpole, baseDirectory string, onlineDatabase bool) *shared.BackupManifest {
+	if flags.DryRun {
+		return shared.NewBackupManifest("podman")
+	}
+	cnx := cmd_shared.NewConnection("podman", podman.ServerContainerName, "")
+	manifest := prepareManifest(cnx, "podman")
+	addPodmanImagesToManifest(manifest)
+	manifest.Incremental = baseDirectory
+	manifest.OnlineDatabase = onlineDatabase
+	return manifest
+}
@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nok8s
+
+package create
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+// KubernetesCreate is not available without kubernetes support.
+func KubernetesCreate(
+	_ *types.GlobalFlags,
+	_ *shared.Flagpole,
+	_ *cobra.Command,
+	_ []string,
+) error {
+	return errors.New(L("built without kubernetes support"))
+}
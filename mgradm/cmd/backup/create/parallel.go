@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"sync"
+
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// exportTask is the export of a volume or an image into a file.
+type exportTask struct {
+	name   string
+	file   string
+	size   int64
+	export func() error
+}
+
+// runExportTasks runs the tasks with several workers and shows their progress.
+//
+// If stopOnError is set, the remaining tasks are not started after a failure.
+// The errors of all the failed tasks are returned.
+func runExportTasks(tasks []exportTask, workers int, stopOnError bool, dryRun bool) error {
+	var hasError error
+	if dryRun {
+		for _, task := range tasks {
+			hasError = utils.JoinErrors(hasError, task.export())
+		}
+		return hasError
+	}
+
+	progress := utils.NewProgressBars()
+	progress.Start()
+	defer progress.Stop()
+
+	var mutex sync.Mutex
+	failed := false
+	queue := make(chan exportTask)
+	var wg sync.WaitGroup
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				item := progress.Add(task.name, task.size, utils.FileSizeProgress(task.file))
+				err := task.export()
+				progress.Done(item, err)
+				if err != nil {
+					mutex.Lock()
+					hasError = utils.JoinErrors(hasError, err)
+					failed = true
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		mutex.Lock()
+		stop := failed && stopOnError
+		mutex.Unlock()
+		if stop {
+			break
+		}
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+	return hasError
+}
@@ -8,6 +8,7 @@ import (
 	"archive/tar"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
@@ -37,6 +38,11 @@ func exportPodmanConfiguration(outputDir string, dryRun bool) error {
 	}
 	defer out.Close()
 
+	return writePodmanConfiguration(out, network, errNetwork, secrets, errPodman)
+}
+
+// writePodmanConfiguration writes the podman configuration tarball into out.
+func writePodmanConfiguration(out io.Writer, network []byte, errNetwork error, secrets []byte, errPodman error) error {
 	// Prepare tar buffer
 	tw := tar.NewWriter(out)
 	defer tw.Close()
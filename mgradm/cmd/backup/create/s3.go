@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	podman_mgradm "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// createS3Backup uploads the backup to an S3-compatible object storage.
+//
+// The volumes and images exports are streamed to the bucket without being written on the disk.
+func createS3Backup(flags *shared.Flagpole, target string) error {
+	dryRun := flags.DryRun
+
+	client, err := s3SanityChecks(target, flags)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	volumes := gatherVolumesToBackup(flags.ExtraVolumes, flags.SkipVolumes, flags.SkipDatabase)
+	images := gatherContainerImagesToBackup(flags.SkipImages)
+
+	if dryRun {
+		log.Info().Msgf(L("Would upload volumes %[1]s and images %[2]s to %[3]s"), volumes, images, target)
+		return nil
+	}
+
+	// The server details can only be inspected while it is running
+	manifest := newPodmanManifest(flags, "", false)
+	manifest.Files = map[string]string{}
+
+	// stop service if database is to be backed up. Otherwise do a live backup
+	serviceStopped := false
+	if !flags.SkipDatabase {
+		log.Info().Msg(L("Stopping server service"))
+		if err := podman_mgradm.StopServices(); err != nil {
+			return shared.AbortError(err, false)
+		}
+		serviceStopped = true
+	}
+
+	if err := uploadVolumes(client, volumes, manifest); err != nil {
+		return shared.AbortError(err, true)
+	}
+
+	// Remaining backups are not critical, restore can create default values
+	// so let's only track if there was an error
+	hasError := uploadImages(client, images, manifest)
+	hasError = utils.JoinErrors(hasError, uploadSystemdServices(client, manifest))
+	hasError = utils.JoinErrors(hasError, uploadPodmanConfiguration(client, manifest))
+
+	// start service if it was stopped before
+	if serviceStopped && !flags.NoRestart {
+		log.Info().Msg(L("Restarting server service"))
+		hasError = utils.JoinErrors(hasError, podman_mgradm.StartServices())
+	}
+
+	// The manifest lists all the files: it has to be written last
+	log.Info().Msg(L("Writing the backup manifest"))
+	content, err := manifest.Marshal()
+	if err == nil {
+		err = client.PutBytes(shared.ManifestFile, content)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Backup manifest was not written"))
+		hasError = utils.JoinErrors(hasError, err)
+	}
+
+	log.Info().Msgf(L("Backup finished into %s"), target)
+	return shared.ReportError(hasError)
+}
+
+func s3SanityChecks(target string, flags *shared.Flagpole) (*shared.S3Client, error) {
+	if err := shared.SanityChecks(); err != nil {
+		return nil, err
+	}
+
+	if flags.Incremental != "" || flags.Archive || flags.OnlineDatabase {
+		return nil, errors.New(L("incremental, archive and online database backups cannot be uploaded to S3"))
+	}
+
+	client, err := shared.NewS3Client(target, &flags.S3)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := client.List()
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the content of %s"), target)
+	}
+	if len(objects) > 0 {
+		return nil, fmt.Errorf(L("%s already contains files"), target)
+	}
+
+	hostData, err := podman.InspectHost()
+	if err != nil {
+		return nil, err
+	}
+
+	if !hostData.HasUyuniServer {
+		return nil, errors.New(L("server is not initialized."))
+	}
+	return client, nil
+}
+
+func uploadVolumes(client *shared.S3Client, volumes []string, manifest *shared.BackupManifest) error {
+	log.Info().Msg(L("Backing up container volumes"))
+	for _, volume := range volumes {
+		if !podman.IsVolumePresent(volume) {
+			continue
+		}
+		log.Info().Msgf(L("Uploading volume %s"), volume)
+		sizeHint, err := shared.VolumeSize(volume)
+		if err != nil {
+			return err
+		}
+		name := path.Join(shared.VolumesSubdir, volume+".tar")
+		size, err := uploadCommandOutput(client, name, manifest, sizeHint, "podman", "volume", "export", volume)
+		if err != nil {
+			return utils.Errorf(err, L("Failed to export volume %s"), volume)
+		}
+		manifest.Volumes = append(manifest.Volumes, shared.VolumeManifestData{Name: volume, Size: size})
+	}
+	return nil
+}
+
+func uploadImages(client *shared.S3Client, images []string, manifest *shared.BackupManifest) error {
+	log.Info().Msg(L("Backing up container images"))
+	var hasError error
+	for _, image := range images {
+		log.Info().Msgf(L("Uploading image %s"), image)
+		sizeHint, err := podman.GetImageVirtualSize(image)
+		if err == nil {
+			name := path.Join(shared.ImagesSubdir, image+".tar")
+			_, err = uploadCommandOutput(client, name, manifest, sizeHint, "podman", "image", "save", "--quiet", image)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf(L("Not backing up image %s"), image)
+			hasError = utils.JoinErrors(hasError, err)
+		}
+	}
+	return hasError
+}
+
+func uploadSystemdServices(client *shared.S3Client, manifest *shared.BackupManifest) error {
+	log.Info().Msg(L("Backing up Systemd services"))
+	var buffer bytes.Buffer
+	err := writeSystemdConfiguration(&buffer, gatherSystemdItems())
+	if err == nil {
+		err = uploadBytes(client, shared.SystemdConfBackupFile, buffer.Bytes(), manifest)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg(L("Systemd services and configuration was not backed up"))
+	}
+	return err
+}
+
+func uploadPodmanConfiguration(client *shared.S3Client, manifest *shared.BackupManifest) error {
+	log.Info().Msg(L("Backing up podman configuration"))
+	network, errNetwork := backupPodmanNetwork(false)
+	secrets, errPodman := backupPodmanSecrets(false)
+
+	var buffer bytes.Buffer
+	hasError := writePodmanConfiguration(&buffer, network, errNetwork, secrets, errPodman)
+	if err := uploadBytes(client, shared.PodmanConfBackupFile, buffer.Bytes(), manifest); err != nil {
+		hasError = utils.JoinErrors(hasError, err)
+	}
+	if hasError != nil {
+		log.Warn().Err(hasError).Msg(L("Podman configuration was not backed up"))
+	}
+	return hasError
+}
+
+// uploadCommandOutput streams the output of a command to an object and uploads its checksum.
+func uploadCommandOutput(
+	client *shared.S3Client,
+	name string,
+	manifest *shared.BackupManifest,
+	sizeHint int64,
+	command string,
+	args ...string,
+) (int64, error) {
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := utils.NewRunner(command, args...).Stdout(writer).Exec()
+		// Let the upload know the data is complete or the command failed
+		writer.CloseWithError(err)
+		done <- err
+	}()
+
+	size, checksum, err := client.Upload(name, reader, sizeHint)
+	// Unblock the command if the upload stopped before the end
+	reader.CloseWithError(err)
+	if cmdErr := <-done; cmdErr != nil {
+		return size, cmdErr
+	}
+	if err != nil {
+		return size, err
+	}
+	return size, uploadChecksum(client, name, checksum, manifest)
+}
+
+func uploadBytes(client *shared.S3Client, name string, content []byte, manifest *shared.BackupManifest) error {
+	_, checksum, err := client.Upload(name, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return err
+	}
+	return uploadChecksum(client, name, checksum, manifest)
+}
+
+// uploadChecksum writes the checksum file of an object in the sha256sum format.
+func uploadChecksum(client *shared.S3Client, name string, checksum string, manifest *shared.BackupManifest) error {
+	manifest.Files[name] = checksum
+	content := fmt.Sprintf("%s  %s\n", checksum, path.Base(name))
+	return client.PutBytes(name+".sha256sum", []byte(content))
+}
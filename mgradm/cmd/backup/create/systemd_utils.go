@@ -34,6 +34,11 @@ func exportSystemdConfiguration(outputDir string, dryRun bool) error {
 	}
 	defer out.Close()
 
+	return writeSystemdConfiguration(out, filesToBackup)
+}
+
+// writeSystemdConfiguration writes a tarball with the systemd files into out.
+func writeSystemdConfiguration(out io.Writer, filesToBackup []string) error {
 	// Prepare tar buffer
 	tw := tar.NewWriter(out)
 	defer tw.Close()
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inspect
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Inspect prints the manifest of a backup directory.
+func Inspect(
+	_ *types.GlobalFlags,
+	_ *shared.Flagpole,
+	_ *cobra.Command,
+	args []string,
+) error {
+	backupDir := args[0]
+	if shared.IsArchive(backupDir) {
+		return errors.New(L("inspecting a backup archive is not supported"))
+	}
+
+	manifest, err := shared.ReadBackupManifest(backupDir)
+	if err != nil {
+		return err
+	}
+
+	prettyOutput, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return utils.Errorf(err, L("cannot print the backup manifest"))
+	}
+
+	outputString := "\n" + string(prettyOutput)
+	log.Info().Msg(outputString)
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prune
+
+import (
+	"errors"
+	"os"
+	"path"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Prune removes the scheduled backups not matching the retention policy.
+func Prune(
+	_ *types.GlobalFlags,
+	flags *shared.Flagpole,
+	_ *cobra.Command,
+	args []string,
+) error {
+	baseDirectory := args[0]
+	if flags.KeepDaily < 0 || flags.KeepWeekly < 0 {
+		return errors.New(L("the number of backups to keep cannot be negative"))
+	}
+
+	backups, err := shared.ListScheduledBackups(baseDirectory)
+	if err != nil {
+		return err
+	}
+
+	expired := shared.ExpiredBackups(backups, flags.KeepDaily, flags.KeepWeekly)
+	if len(expired) == 0 {
+		log.Info().Msg(L("No backup to prune"))
+		return nil
+	}
+
+	var hasError error
+	for _, backup := range expired {
+		backupPath := path.Join(baseDirectory, backup.Name)
+		if flags.DryRun {
+			log.Info().Msgf(L("Would remove %s"), backupPath)
+			continue
+		}
+		log.Info().Msgf(L("Removing expired backup %s"), backupPath)
+		if err := os.RemoveAll(backupPath); err != nil {
+			hasError = utils.JoinErrors(hasError, utils.Errorf(err, L("failed to remove %s"), backupPath))
+		}
+	}
+	return hasError
+}
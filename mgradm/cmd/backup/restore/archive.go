@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restore
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	podman_mgradm "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// volumeImporter streams the files of a volume from the archive into podman volume import.
+type volumeImporter struct {
+	name   string
+	writer *io.PipeWriter
+	tw     *tar.Writer
+	done   chan error
+	// hashes contains the sha256 of the imported regular files, nil if they are not verified.
+	hashes map[string]string
+}
+
+func newVolumeImporter(name string, skipVerify bool, dryRun bool) (*volumeImporter, error) {
+	importer := volumeImporter{name: name}
+	if dryRun {
+		log.Info().Msgf(L("Would restore volume %s"), name)
+		return &importer, nil
+	}
+
+	if err := runCmd("podman", "volume", "create", "--ignore", name); err != nil {
+		return nil, utils.Errorf(err, L("Failed to precreate empty volume %s"), name)
+	}
+
+	log.Info().Msgf(L("Restoring volume %s"), name)
+	reader, writer := io.Pipe()
+	importer.writer = writer
+	importer.tw = tar.NewWriter(writer)
+	importer.done = make(chan error, 1)
+	if !skipVerify {
+		importer.hashes = map[string]string{}
+	}
+	go func() {
+		_, err := utils.NewRunner("podman", "volume", "import", name, "-").Stdin(reader).Exec()
+		// Unblock the writer if the import stopped before the end
+		reader.CloseWithError(err)
+		importer.done <- err
+	}()
+	return &importer, nil
+}
+
+// add copies a volume entry of the archive into the volume, name is relative to the volume root.
+func (i *volumeImporter) add(name string, header *tar.Header, reader io.Reader) error {
+	if i.tw == nil {
+		return nil
+	}
+	header.Name = name
+	if err := i.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if i.hashes == nil || header.Typeflag != tar.TypeReg {
+		_, err := io.Copy(i.tw, reader)
+		return err
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(i.tw, hasher), reader); err != nil {
+		return err
+	}
+	i.hashes[name] = hex.EncodeToString(hasher.Sum(nil))
+	return nil
+}
+
+// verify checks the imported files against the volume manifest read from the archive.
+func (i *volumeImporter) verify(reader io.Reader) error {
+	if i.hashes == nil {
+		return nil
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	var manifest shared.VolumeManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return utils.Errorf(err, L("failed to parse the manifest of volume %s"), i.name)
+	}
+	if err := manifest.VerifyHashes(i.hashes); err != nil {
+		return utils.Errorf(err, L("the content of volume %s does not match the backup manifest"), i.name)
+	}
+	return nil
+}
+
+// close finishes the volume import and waits for it to complete.
+func (i *volumeImporter) close() error {
+	if i.tw == nil {
+		return nil
+	}
+	hasError := i.tw.Close()
+	hasError = utils.JoinErrors(hasError, i.writer.Close())
+	if err := <-i.done; err != nil {
+		return handleVolumeHacks(i.name, utils.Errorf(err, L("Failed to import volume %s"), i.name))
+	}
+	return hasError
+}
+
+// restoreArchive restores a backup from a single compressed and encrypted archive.
+//
+// The archive is decrypted on the fly: only the container images are temporarily written on the disk.
+func restoreArchive(flags *shared.Flagpole, archivePath string) error {
+	if err := sanityChecks(archivePath, flags); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	archive, err := shared.NewArchiveReader(archivePath, flags.AgeIdentity)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	hasError, err := restoreArchiveEntries(archive, flags)
+	if err != nil {
+		_ = archive.Close()
+		return shared.AbortError(err, true)
+	}
+	if err := archive.Close(); err != nil {
+		return shared.AbortError(utils.Errorf(err, L("failed to read the backup archive")), true)
+	}
+	hasError = utils.JoinErrors(hasError, changeFQDN(flags))
+
+	if flags.Restart {
+		hasError = utils.JoinErrors(hasError, podman_mgradm.StartServices())
+	}
+	return shared.ReportError(hasError)
+}
+
+// restoreArchiveEntries restores the content of the archive in the order it was written.
+//
+// The returned error is set for failures requiring to abort while hasError
+// collects the problems that can be fixed using default values.
+func restoreArchiveEntries(archive *shared.ArchiveReader, flags *shared.Flagpole) (hasError error, err error) {
+	skipAllVolumes := len(flags.SkipVolumes) == 1 && flags.SkipVolumes[0] == "all"
+	var importer *volumeImporter
+	podmanRestored := false
+	systemdRestored := false
+
+	// Images need to be written to the disk to be imported
+	tempDir, cleaner, err := utils.TempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer cleaner()
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return hasError, utils.Errorf(err, L("failed to read the backup archive"))
+		}
+
+		volumesPrefix := shared.VolumesSubdir + "/"
+		if strings.HasPrefix(header.Name, volumesPrefix) {
+			parts := strings.SplitN(strings.TrimPrefix(header.Name, volumesPrefix), "/", 2)
+			// The manifest follows the files of its volume and is only used to verify them
+			if len(parts) == 1 {
+				if err := verifyVolumeImport(importer, parts[0], archive); err != nil {
+					return hasError, err
+				}
+				continue
+			}
+			if importer == nil || importer.name != parts[0] {
+				if importer, err = switchVolumeImporter(importer, parts[0], skipAllVolumes, flags); err != nil {
+					return hasError, err
+				}
+			}
+			name := parts[1]
+			if name == "" {
+				// The volume root folder holds the owner and permissions of the volume
+				name = "./"
+			}
+			if err := importer.add(name, header, archive); err != nil {
+				return hasError, utils.Errorf(err, L("Failed to import volume %s"), importer.name)
+			}
+			continue
+		}
+
+		// Volumes are the first entries of the archive
+		if importer != nil {
+			if err := importer.close(); err != nil {
+				return hasError, err
+			}
+			importer = nil
+		}
+
+		switch {
+		case strings.HasPrefix(header.Name, shared.ImagesSubdir+"/"):
+			hasError = utils.JoinErrors(hasError, restoreArchiveImage(header, archive, tempDir, flags))
+		case header.Name == shared.PodmanConfBackupFile:
+			podmanRestored = true
+			hasError = utils.JoinErrors(hasError, restorePodmanConfigurationFrom(archive, flags))
+		case header.Name == shared.SystemdConfBackupFile:
+			systemdRestored = true
+			log.Info().Msgf(L("Restoring systemd configuration"))
+			if err := restoreSystemdConfigurationFrom(archive, flags); err != nil {
+				hasError = utils.JoinErrors(hasError, err)
+			} else {
+				hasError = utils.JoinErrors(hasError, systemd.ReloadDaemon(flags.DryRun))
+			}
+		default:
+			log.Warn().Msgf(L("Ignoring unexpected file in the backup archive %s"), header.Name)
+		}
+	}
+
+	if importer != nil {
+		if err := importer.close(); err != nil {
+			return hasError, err
+		}
+	}
+
+	if !podmanRestored {
+		log.Warn().Msg(L("podman config backup not found in the backup location, trying defaults"))
+		hasError = utils.JoinErrors(hasError, defaultPodmanNetwork(flags))
+	}
+	if !systemdRestored {
+		log.Warn().Msg(L("systemd backup not found in the backup location, generating defaults"))
+		hasError = utils.JoinErrors(hasError, generateDefaltSystemdServices(flags))
+	}
+	return hasError, nil
+}
+
+// verifyVolumeImport checks the files imported by the importer against the manifest named manifestName.
+//
+// The volume import is finished before the verification.
+func verifyVolumeImport(importer *volumeImporter, manifestName string, reader io.Reader) error {
+	if importer == nil || importer.name+shared.ManifestSuffix != manifestName {
+		log.Warn().Msgf(L("Ignoring unexpected file in the backup archive %s"), path.Join(shared.VolumesSubdir, manifestName))
+		return nil
+	}
+	if err := importer.close(); err != nil {
+		return err
+	}
+	// Keep the importer to ignore any other file of the volume
+	importer.tw = nil
+	return importer.verify(reader)
+}
+
+// switchVolumeImporter finishes the current volume import and prepares the next one.
+// If the volume is not to be restored, the returned importer ignores the files.
+func switchVolumeImporter(
+	current *volumeImporter,
+	volume string,
+	skipAllVolumes bool,
+	flags *shared.Flagpole,
+) (*volumeImporter, error) {
+	if current != nil {
+		if err := current.close(); err != nil {
+			return nil, err
+		}
+	}
+
+	restore := false
+	if !skipAllVolumes {
+		var err error
+		if restore, err = shouldRestoreVolume(volume, flags, podman.IsVolumePresent); err != nil {
+			return nil, err
+		}
+	}
+	if !restore {
+		return &volumeImporter{name: volume}, nil
+	}
+	return newVolumeImporter(volume, flags.SkipVerify, flags.DryRun)
+}
+
+func restoreArchiveImage(header *tar.Header, reader io.Reader, tempDir string, flags *shared.Flagpole) error {
+	if flags.SkipImages {
+		return nil
+	}
+	imageFile := path.Join(tempDir, path.Base(header.Name))
+	if flags.DryRun {
+		return podman.RestoreImage(imageFile, true)
+	}
+
+	out, err := os.Create(imageFile)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, reader)
+	out.Close()
+	if err != nil {
+		return utils.JoinErrors(err, errors.New(L("failed to extract the image from the archive")))
+	}
+	defer os.Remove(imageFile)
+	return podman.RestoreImage(imageFile, false)
+}
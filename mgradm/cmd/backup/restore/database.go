@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restore
+
+import (
+	"os"
+	"path"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// prepareDatabaseVolume empties the database volume before restoring an online backup.
+//
+// The data directory is rebuilt from the pg_basebackup tarball: files left from the existing
+// database, like newer write-ahead log segments, would break the recovery.
+func prepareDatabaseVolume(volName string, backupDir string, flags *shared.Flagpole) error {
+	data, err := shared.ReadDatabaseBackupData(backupDir)
+	if err != nil || data == nil {
+		return err
+	}
+	log.Info().Msgf(L("Restoring the database from an online backup at write-ahead log location %[1]s (timeline %[2]s)"),
+		data.StartWALLocation, data.Timeline,
+	)
+
+	if !podman.IsVolumePresent(volName) {
+		return nil
+	}
+	if flags.DryRun {
+		log.Info().Msgf(L("Would remove the content of volume %s"), volName)
+		return nil
+	}
+
+	mountPoint, err := podman.GetVolumeMountPoint(volName)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(mountPoint)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read %s"), mountPoint)
+	}
+	log.Debug().Msgf("Removing the content of volume %s", volName)
+	for _, entry := range entries {
+		if err := os.RemoveAll(path.Join(mountPoint, entry.Name())); err != nil {
+			return utils.Errorf(err, L("failed to remove the content of volume %s"), volName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restore
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	podman_mgradm "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// changeFQDN rewrites the restored configuration and database with the new FQDN
+// and generates new server and report database certificates.
+//
+// This needs to run after the volumes, podman secrets and systemd services are restored.
+func changeFQDN(flags *shared.Flagpole) error {
+	newFQDN := flags.New.FQDN
+	if newFQDN == "" {
+		return nil
+	}
+	if flags.DryRun {
+		log.Info().Msgf(L("Would change the server FQDN to %s and generate new certificates"), newFQDN)
+		return nil
+	}
+
+	etcRhn, err := podman.GetVolumeMountPoint(utils.EtcRhnVolumeMount.Name)
+	if err != nil {
+		return err
+	}
+	rhnConf := path.Join(etcRhn, "rhn.conf")
+	oldFQDN, err := shared.ReadServerFQDN(rhnConf)
+	if err != nil {
+		return err
+	}
+	if oldFQDN == newFQDN {
+		log.Info().Msgf(L("The restored server already uses %s FQDN"), newFQDN)
+		return nil
+	}
+
+	log.Info().Msgf(L("Changing the server FQDN from %[1]s to %[2]s"), oldFQDN, newFQDN)
+	for _, file := range shared.FQDNFiles {
+		if !podman.IsVolumePresent(file.Volume) {
+			continue
+		}
+		mountPoint, err := podman.GetVolumeMountPoint(file.Volume)
+		if err != nil {
+			return err
+		}
+		changed, err := shared.RewriteFQDN(mountPoint, file.Pattern, oldFQDN, newFQDN)
+		if err != nil {
+			return utils.Errorf(err, L("failed to change the FQDN in volume %s"), file.Volume)
+		}
+		for _, changedFile := range changed {
+			log.Info().Msgf(L("Changed the FQDN in %s"), changedFile)
+		}
+	}
+
+	dbName, err := shared.ReadRhnConfValue(rhnConf, "db_name")
+	if err != nil {
+		return err
+	}
+	if err := renameInDatabase(dbName, oldFQDN, newFQDN, flags.Restart); err != nil {
+		return err
+	}
+
+	image := podman.GetServiceImage(podman.ServerService)
+	if image == "" {
+		return errors.New(L("failed to find the server image to generate the certificates"))
+	}
+	tz := utils.GetLocalTimezone()
+	if err := podman_mgradm.RegenerateServerCertificate(image, &flags.SSL, tz, newFQDN); err != nil {
+		return err
+	}
+	return podman_mgradm.RegenerateDatabaseCertificate(image, &flags.SSL, tz, newFQDN)
+}
+
+// renameInDatabase replaces the old FQDN by the new one in the database, starting it if needed.
+//
+// The database is stopped afterwards unless the services are started at the end of the restore.
+func renameInDatabase(dbName string, oldFQDN string, newFQDN string, keepRunning bool) error {
+	// Older servers have the database in the server container
+	service := podman.DBService
+	container := podman.DBContainerName
+	if !systemd.HasService(service) {
+		service = podman.ServerService
+		container = podman.ServerContainerName
+	}
+
+	if err := systemd.StartService(service); err != nil {
+		return utils.Errorf(err, L("failed to start the database to change the FQDN"))
+	}
+	if !keepRunning {
+		defer func() {
+			if err := systemd.StopService(service); err != nil {
+				log.Warn().Err(err).Msgf(L("Failed to stop %s service"), service)
+			}
+		}()
+	}
+
+	ready, _ := utils.WaitFor(time.Minute, time.Second, func() (bool, error) {
+		_, err := utils.NewRunner("podman", "exec", "-u", "postgres", container, "pg_isready", "-q").Exec()
+		return err == nil, nil
+	})
+	if !ready {
+		return errors.New(L("the database is not ready to change the FQDN"))
+	}
+
+	log.Info().Msg(L("Changing the server FQDN in the database"))
+	_, err := utils.NewRunner("podman", "exec", "-i", "-u", "postgres", container,
+		"psql", "--quiet", "-v", "ON_ERROR_STOP=1", "-v", "old_fqdn="+oldFQDN, "-v", "new_fqdn="+newFQDN, "-d", dbName,
+	).Stdin(strings.NewReader(shared.FQDNRenameSQL)).Exec()
+	if err != nil {
+		return utils.Errorf(err, L("failed to change the FQDN in the database"))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restore
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// restoreVolumeChain imports a volume from each backup of the chain and removes the files deleted by each step.
+//
+// The chain starts with the full backup and ends with the most recent incremental backup.
+// The backups older than the last complete export of the volume are ignored.
+func restoreVolumeChain(volName string, chain []string, flags *shared.Flagpole) error {
+	start := getVolumeChainStart(volName, chain)
+	if isDatabaseVolume(volName) {
+		if err := prepareDatabaseVolume(volName, chain[start], flags); err != nil {
+			return err
+		}
+	}
+
+	for i := start; i < len(chain); i++ {
+		backupDir := chain[i]
+		volumesDir := path.Join(backupDir, shared.VolumesSubdir)
+		volumePath := path.Join(volumesDir, volName+".tar")
+		if !utils.FileExists(volumePath) {
+			log.Debug().Msgf("No %s volume in backup %s", volName, backupDir)
+			continue
+		}
+		if err := podman.ImportVolume(volName, volumePath, flags.SkipVerify, flags.DryRun); err != nil {
+			if err := handleVolumeHacks(volName, err); err != nil {
+				return err
+			}
+		}
+
+		// The complete export has no deleted file
+		if i == start {
+			continue
+		}
+		if err := applyDeletedFiles(volName, shared.ManifestPath(volumesDir, volName), flags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getVolumeChainStart returns the index of the most recent backup with a complete export of the volume.
+func getVolumeChainStart(volName string, chain []string) int {
+	for i := len(chain) - 1; i > 0; i-- {
+		volumesDir := path.Join(chain[i], shared.VolumesSubdir)
+		if !utils.FileExists(path.Join(volumesDir, volName+".tar")) {
+			continue
+		}
+		manifestPath := shared.ManifestPath(volumesDir, volName)
+		// Volumes without manifest are either full or online database exports
+		if !utils.FileExists(manifestPath) {
+			return i
+		}
+		if manifest, err := shared.ReadManifest(manifestPath); err == nil && manifest.Full {
+			return i
+		}
+	}
+	return 0
+}
+
+func applyDeletedFiles(volName string, manifestPath string, flags *shared.Flagpole) error {
+	if flags.DryRun {
+		log.Info().Msgf(L("Would remove the files deleted in %[1]s from volume %[2]s"), manifestPath, volName)
+		return nil
+	}
+	if !flags.SkipVerify {
+		if err := utils.ValidateChecksum(manifestPath); err != nil {
+			return utils.Errorf(err, L("Checksum does not match for %s"), manifestPath)
+		}
+	}
+	manifest, err := shared.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Deleted) == 0 {
+		return nil
+	}
+
+	mountPoint, err := podman.GetVolumeMountPoint(volName)
+	if err != nil {
+		return err
+	}
+	log.Debug().Msgf("Removing %d deleted files from volume %s", len(manifest.Deleted), volName)
+	if err := shared.ApplyDeletions(mountPoint, manifest.Deleted); err != nil {
+		return utils.Errorf(err, L("failed to remove the deleted files from volume %s"), volName)
+	}
+	return nil
+}
+
+// getImagesSource returns the most recent backup of the chain containing images.
+func getImagesSource(chain []string) string {
+	for i := len(chain) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(path.Join(chain[i], shared.ImagesSubdir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".tar") {
+				return chain[i]
+			}
+		}
+	}
+	return chain[len(chain)-1]
+}
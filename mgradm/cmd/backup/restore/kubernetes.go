@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package restore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	adm_kubernetes "github.com/uyuni-project/uyuni-tools/mgradm/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// KubernetesRestore restores a backup on a kubernetes cluster.
+//
+// The server objects are recreated with the deployments scaled down,
+// the volumes are streamed into the persistent volume claims and the deployments are
+// scaled up if restart is requested.
+func KubernetesRestore(
+	_ *types.GlobalFlags,
+	flags *shared.Flagpole,
+	_ *cobra.Command,
+	args []string,
+) error {
+	inputDirectory := args[0]
+	printIntro(inputDirectory, flags)
+	dryRun := flags.DryRun
+
+	if shared.IsArchive(inputDirectory) {
+		return shared.AbortError(errors.New(L("archive backups are not supported on kubernetes")), false)
+	}
+	if shared.IsS3URL(inputDirectory) {
+		return shared.AbortError(errors.New(L("S3 backups are not supported on kubernetes")), false)
+	}
+	if flags.Volume != "" {
+		return shared.AbortError(errors.New(L("restoring a single volume is not supported on kubernetes")), false)
+	}
+	if flags.New.FQDN != "" {
+		return shared.AbortError(errors.New(L("changing the FQDN is not supported on kubernetes")), false)
+	}
+
+	if err := kubernetesSanityChecks(inputDirectory); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	data, err := readKubernetesBackupData(inputDirectory, flags.SkipVerify)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+	namespace := data.Namespace
+
+	// The server deployments need to be stopped before touching the volumes
+	hasDeployment := kubernetes.HasDeployment(namespace, kubernetes.ServerFilter)
+	if hasDeployment {
+		if !flags.ForceRestore {
+			return shared.AbortError(errors.New(L("server is already initialized. Use force to overwrite")), false)
+		}
+		log.Warn().Msg(L("Restoring over already initialized server"))
+	}
+
+	volumes, err := gatherVolumesToRestore(inputDirectory, flags, func(volume string) bool {
+		return kubernetes.HasVolume(namespace, volume)
+	})
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	if hasDeployment && !dryRun {
+		log.Info().Msg(L("Stopping server deployments"))
+		replicas, err := adm_kubernetes.GetDeploymentsReplicas(namespace)
+		if err != nil {
+			return shared.AbortError(err, false)
+		}
+		stopped := map[string]int{}
+		for name := range replicas {
+			stopped[name] = 0
+		}
+		if err := adm_kubernetes.ScaleDeployments(namespace, stopped); err != nil {
+			return shared.AbortError(err, false)
+		}
+	}
+
+	// The claims are needed to restore the volumes, the other objects are only the configuration
+	// which could be generated again by an installation.
+	kinds := []string{}
+	if flags.SkipConfig {
+		kinds = append(kinds, "PersistentVolumeClaim")
+	}
+	if err := restoreKubernetesObjects(namespace, data.Objects, kinds, dryRun); err != nil {
+		return shared.AbortError(err, true)
+	}
+
+	if err := restoreKubernetesVolumes(namespace, data.ServerImage, volumes, flags); err != nil {
+		return shared.AbortError(err, true)
+	}
+
+	// The helm releases records are part of the configuration objects
+	for _, release := range data.HelmReleases {
+		if flags.SkipConfig {
+			log.Warn().Msgf(L("Helm release %[1]s of chart %[2]s was not restored, its values are in %[3]s"),
+				release.Name, release.Chart, path.Join(inputDirectory, shared.KubernetesBackupFile),
+			)
+		} else {
+			log.Info().Msgf(L("Helm release %[1]s of chart %[2]s was restored"), release.Name, release.Chart)
+		}
+	}
+
+	var hasError error
+	if flags.Restart && !flags.SkipConfig && !dryRun {
+		replicas, err := adm_kubernetes.GetBackupObjectsReplicas(data.Objects)
+		if err != nil {
+			return shared.ReportError(err)
+		}
+		log.Info().Msg(L("Starting server deployments"))
+		hasError = adm_kubernetes.ScaleDeployments(namespace, replicas)
+	}
+
+	return shared.ReportError(hasError)
+}
+
+func kubernetesSanityChecks(inputDirectory string) error {
+	if err := shared.KubernetesSanityChecks(); err != nil {
+		return err
+	}
+
+	if !utils.FileExists(inputDirectory) {
+		return fmt.Errorf(L("input directory %s does not exists"), inputDirectory)
+	}
+	return nil
+}
+
+func readKubernetesBackupData(inputDirectory string, skipVerify bool) (*shared.KubernetesBackupData, error) {
+	backupFile := path.Join(inputDirectory, shared.KubernetesBackupFile)
+	if !utils.FileExists(backupFile) {
+		return nil, errors.New(L("kubernetes objects backup not found in the backup location"))
+	}
+	if !skipVerify {
+		if err := utils.ValidateChecksum(backupFile); err != nil {
+			return nil, utils.JoinErrors(err, errors.New(L("Unable to validate kubernetes backup file")))
+		}
+	}
+
+	content, err := os.ReadFile(backupFile)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %s"), backupFile)
+	}
+	var data shared.KubernetesBackupData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %s"), backupFile)
+	}
+	if data.Namespace == "" {
+		return nil, fmt.Errorf(L("no namespace defined in %s"), backupFile)
+	}
+	return &data, nil
+}
+
+func restoreKubernetesObjects(namespace string, objects []byte, kinds []string, dryRun bool) error {
+	if dryRun {
+		log.Info().Msgf(L("Would restore the kubernetes objects in namespace %s"), namespace)
+		return nil
+	}
+	log.Info().Msg(L("Restoring kubernetes objects"))
+	if err := adm_kubernetes.CreateNamespace(namespace); err != nil {
+		return err
+	}
+	return adm_kubernetes.ApplyBackupObjects(objects, kinds)
+}
+
+func restoreKubernetesVolumes(namespace string, image string, volumes []string, flags *shared.Flagpole) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	volumeNames := []string{}
+	for _, volume := range volumes {
+		volName := strings.TrimSuffix(path.Base(volume), ".tar")
+		volumeNames = append(volumeNames, volName)
+	}
+
+	if flags.DryRun {
+		log.Info().Msgf(L("Would start a job mounting volumes %s"), volumeNames)
+		for i, volume := range volumes {
+			if err := adm_kubernetes.ImportVolume(
+				namespace, "", volumeNames[i], volume, flags.SkipVerify, flags.DryRun,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if image == "" {
+		image = fmt.Sprintf("%s%s:%s", utils.ServerImage.Registry, utils.ServerImage.Name, utils.ServerImage.Tag)
+	}
+	pullSecret, err := kubernetes.GetDeploymentImagePullSecret(namespace, kubernetes.ServerFilter)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to get the server pull secret")
+		pullSecret = ""
+	}
+
+	jobName, podName, err := adm_kubernetes.StartVolumesAccessJob(
+		namespace, image, utils.DefaultPullPolicy, pullSecret, "", volumeNames,
+	)
+	defer func() {
+		if jobName == "" {
+			return
+		}
+		if err := adm_kubernetes.DeleteJob(namespace, jobName); err != nil {
+			log.Warn().Err(err).Msgf(L("failed to delete the %s job"), jobName)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	var hasError error
+	for i, volume := range volumes {
+		if err := adm_kubernetes.ImportVolume(
+			namespace, podName, volumeNames[i], volume, flags.SkipVerify, flags.DryRun,
+		); err != nil {
+			hasError = utils.JoinErrors(hasError, err)
+		}
+	}
+	return hasError
+}
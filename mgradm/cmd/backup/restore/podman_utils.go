@@ -28,9 +28,14 @@ func restorePodmanConfiguration(podmanBackupFile string, flags *shared.Flagpole)
 	}
 	defer backupFile.Close()
 
+	return restorePodmanConfigurationFrom(backupFile, flags)
+}
+
+// restorePodmanConfigurationFrom restores the network and secrets from a podman configuration tarball.
+func restorePodmanConfigurationFrom(reader io.Reader, flags *shared.Flagpole) error {
 	var hasError error
 
-	tr := tar.NewReader(backupFile)
+	tr := tar.NewReader(reader)
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
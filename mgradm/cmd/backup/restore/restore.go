@@ -36,19 +36,43 @@ func Restore(
 	inputDirectory := args[0]
 	printIntro(inputDirectory, flags)
 	dryRun := flags.DryRun
+
+	if flags.Volume != "" {
+		return restoreSelection(flags, inputDirectory)
+	}
+	if len(flags.Paths) > 0 || flags.Target != "" {
+		return shared.AbortError(errors.New(L("a volume is required to restore paths or into a target directory")), false)
+	}
+	if flags.New.FQDN != "" && !dryRun {
+		utils.AskPasswordIfMissing(&flags.SSL.Password, L("Password of the CA key to generate the certificates"), 0, 0)
+	}
+
+	if shared.IsS3URL(inputDirectory) {
+		return restoreS3Backup(flags, inputDirectory)
+	}
+
+	if shared.IsArchive(inputDirectory) {
+		return restoreArchive(flags, inputDirectory)
+	}
 	// SanityCheck
 	if err := sanityChecks(inputDirectory, flags); err != nil {
 		return shared.AbortError(err, false)
 	}
 
+	// An incremental backup needs all the previous backups down to the full one
+	chain, err := shared.GetBackupChain(inputDirectory)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
 	// Gather the list of volumes and images from the backup location
 	// Both parses provided flags and the produced list has volumes or images
 	// already skipped over if needed.
-	volumes, err := gatherVolumesToRestore(inputDirectory, flags)
+	volumes, err := gatherVolumesToRestore(inputDirectory, flags, podman.IsVolumePresent)
 	if err != nil {
 		return shared.AbortError(err, false)
 	}
-	images, err := gatherImagesToRestore(inputDirectory, flags)
+	images, err := gatherImagesToRestore(getImagesSource(chain), flags)
 	if err != nil {
 		return shared.AbortError(err, false)
 	}
@@ -57,7 +81,7 @@ func Restore(
 	// An error with volume restore is considered serious so we abort
 	// --continue can be used to skip over already imported images once error
 	// is resolved
-	if err := restoreVolumes(volumes, flags, dryRun); err != nil {
+	if err := restoreVolumes(volumes, chain, flags); err != nil {
 		return shared.AbortError(err, true)
 	}
 
@@ -76,6 +100,8 @@ func Restore(
 	if err := restoreSystemdConfig(inputDirectory, flags); err != nil {
 		hasError = utils.JoinErrors(hasError, err)
 	}
+	// The configuration has to be restored before changing the FQDN
+	hasError = utils.JoinErrors(hasError, changeFQDN(flags))
 
 	if flags.Restart {
 		hasError = podman_mgradm.StartServices()
@@ -95,6 +121,10 @@ func printIntro(dir string, flags *shared.Flagpole) {
 	log.Debug().Msgf("skip volumes: %s", flags.SkipVolumes)
 	log.Debug().Msgf("extra volumes: %s", flags.ExtraVolumes)
 	log.Debug().Msgf("skip existing: %t", flags.SkipExisting)
+	log.Debug().Msgf("volume: %s", flags.Volume)
+	log.Debug().Msgf("paths: %s", flags.Paths)
+	log.Debug().Msgf("target: %s", flags.Target)
+	log.Debug().Msgf("new FQDN: %s", flags.New.FQDN)
 }
 
 func sanityChecks(inputDirectory string, flags *shared.Flagpole) error {
@@ -102,10 +132,18 @@ func sanityChecks(inputDirectory string, flags *shared.Flagpole) error {
 		return err
 	}
 
-	if !utils.FileExists(inputDirectory) {
+	// The S3 backups existence is checked when listing them
+	if !shared.IsS3URL(inputDirectory) && !utils.FileExists(inputDirectory) {
 		return fmt.Errorf(L("input directory %s does not exists"), inputDirectory)
 	}
 
+	// The host needs to resolve its new name
+	if flags.New.FQDN != "" {
+		if err := utils.IsValidFQDN(flags.New.FQDN); err != nil {
+			return err
+		}
+	}
+
 	hostData, err := podman.InspectHost()
 	if err != nil {
 		return err
@@ -125,7 +163,12 @@ func sanityChecks(inputDirectory string, flags *shared.Flagpole) error {
 // It takes a list from the backup source, checks if volume already exists and if it is
 // to be skipped.
 // Special `--skipvolume all` handing will cause to return empty list.
-func gatherVolumesToRestore(source string, flags *shared.Flagpole) ([]string, error) {
+// volumeExists tells whether a volume is already existing on the target system.
+func gatherVolumesToRestore(
+	source string,
+	flags *shared.Flagpole,
+	volumeExists func(string) bool,
+) ([]string, error) {
 	skipVolumes := flags.SkipVolumes
 	if len(skipVolumes) == 1 && skipVolumes[0] == "all" {
 		log.Debug().Msg("Skipping restoring of volumes")
@@ -144,40 +187,55 @@ func gatherVolumesToRestore(source string, flags *shared.Flagpole) ([]string, er
 
 	output := []string{}
 	for _, v := range volumes {
-		if strings.HasSuffix(v.Name(), "sha256sum") {
-			// This is checksum file, ignore
+		if strings.HasSuffix(v.Name(), "sha256sum") || strings.HasSuffix(v.Name(), shared.ManifestSuffix) {
+			// This is checksum or manifest file, ignore
 			continue
 		}
 		volName := strings.TrimSuffix(v.Name(), ".tar")
-
-		// Skip volumes set as skipvolume option
-		if utils.Contains(skipVolumes, volName) {
-			log.Info().Msgf(L("Skipping volume %s"), volName)
+		if restore, err := shouldRestoreVolume(volName, flags, volumeExists); err != nil {
+			return nil, err
+		} else if !restore {
 			continue
 		}
+		output = append(output, path.Join(volumeDir, v.Name()))
+	}
+	return output, nil
+}
+
+// shouldRestoreVolume checks the flags to tell whether a volume needs to be restored.
+// An error is returned if the volume exists and is not to be overwritten or skipped.
+func shouldRestoreVolume(volName string, flags *shared.Flagpole, volumeExists func(string) bool) (bool, error) {
+	// Skip volumes set as skipvolume option
+	if utils.Contains(flags.SkipVolumes, volName) {
+		log.Info().Msgf(L("Skipping volume %s"), volName)
+		return false, nil
+	}
 
-		// Skip database volumes if skipdatabase option is used
-		if flags.SkipDatabase {
-			for _, v := range utils.PgsqlRequiredVolumeMounts {
-				if volName == v.Name {
-					log.Info().Msgf(L("Skipping database volume %s"), volName)
-					continue
-				}
-			}
+	// Skip database volumes if skipdatabase option is used
+	if flags.SkipDatabase && isDatabaseVolume(volName) {
+		log.Info().Msgf(L("Skipping database volume %s"), volName)
+		return false, nil
+	}
+	if volumeExists(volName) {
+		if flags.SkipExisting {
+			log.Info().Msgf(L("Not restoring existing volume %s"), volName)
+			return false, nil
 		}
-		if podman.IsVolumePresent(volName) {
-			if flags.SkipExisting {
-				log.Info().Msgf(L("Not restoring existing volume %s"), volName)
-				continue
-			}
-			if !flags.ForceRestore {
-				return nil, fmt.Errorf(L("Not restoring existing volume %s unless forced"), volName)
-			}
-			log.Info().Msgf(L("Volume %s will be overwriten"), volName)
+		if !flags.ForceRestore {
+			return false, fmt.Errorf(L("Not restoring existing volume %s unless forced"), volName)
 		}
-		output = append(output, path.Join(volumeDir, v.Name()))
+		log.Info().Msgf(L("Volume %s will be overwriten"), volName)
 	}
-	return output, nil
+	return true, nil
+}
+
+func isDatabaseVolume(name string) bool {
+	for _, v := range utils.PgsqlRequiredVolumeMounts {
+		if name == v.Name {
+			return true
+		}
+	}
+	return false
 }
 
 // gatherImagesTorRestore produces a list of images to be imported.
@@ -207,14 +265,13 @@ func gatherImagesToRestore(source string, flags *shared.Flagpole) ([]string, err
 	return output, nil
 }
 
-func restoreVolumes(volumes []string, flags *shared.Flagpole, dryRun bool) error {
+// restoreVolumes imports the volumes from each backup of the chain.
+func restoreVolumes(volumes []string, chain []string, flags *shared.Flagpole) error {
 	var hasError error
 	for _, volume := range volumes {
 		volName := strings.TrimSuffix(volume, ".tar")
 		_, volName = path.Split(volName)
-		if err := podman.ImportVolume(volName, volume, flags.SkipVerify, dryRun); err != nil {
-			hasError = utils.JoinErrors(hasError, handleVolumeHacks(volName, err))
-		}
+		hasError = utils.JoinErrors(hasError, restoreVolumeChain(volName, chain, flags))
 	}
 	return hasError
 }
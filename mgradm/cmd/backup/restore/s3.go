@@ -0,0 +1,327 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	podman_mgradm "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// s3Backup gives access to the objects of a backup stored in an S3 bucket and their checksums.
+type s3Backup struct {
+	client   *shared.S3Client
+	objects  []string
+	manifest *shared.BackupManifest
+	// stagingDir is the folder to download the objects to verify, the temporary folder if empty.
+	stagingDir string
+}
+
+// restoreS3Backup restores a backup from an S3-compatible object storage.
+//
+// The volumes and images are downloaded one at a time to the staging folder to verify them before importing.
+func restoreS3Backup(flags *shared.Flagpole, source string) error {
+	if err := sanityChecks(source, flags); err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	backup, err := openS3Backup(source, flags)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	// An error with volume restore is considered serious so we abort
+	if err := backup.restoreVolumes(flags); err != nil {
+		return shared.AbortError(err, true)
+	}
+
+	// Everything below is not considered a serious error as it can be recreated from
+	// defaults, but there may be a data loss
+	hasError := backup.restoreImages(flags)
+
+	if content, err := backup.readSmallObject(shared.PodmanConfBackupFile, flags.SkipVerify); err != nil {
+		hasError = utils.JoinErrors(hasError, err)
+	} else if content == nil {
+		log.Warn().Msg(L("podman config backup not found in the backup location, trying defaults"))
+		hasError = utils.JoinErrors(hasError, defaultPodmanNetwork(flags))
+	} else {
+		hasError = utils.JoinErrors(hasError, restorePodmanConfigurationFrom(bytes.NewReader(content), flags))
+	}
+
+	log.Info().Msgf(L("Restoring systemd configuration"))
+	if content, err := backup.readSmallObject(shared.SystemdConfBackupFile, flags.SkipVerify); err != nil {
+		hasError = utils.JoinErrors(hasError, err)
+	} else if content == nil {
+		log.Warn().Msg(L("systemd backup not found in the backup location, generating defaults"))
+		hasError = utils.JoinErrors(hasError, generateDefaltSystemdServices(flags))
+	} else if err := restoreSystemdConfigurationFrom(bytes.NewReader(content), flags); err != nil {
+		hasError = utils.JoinErrors(hasError, err)
+	} else {
+		hasError = utils.JoinErrors(hasError, systemd.ReloadDaemon(flags.DryRun))
+	}
+	hasError = utils.JoinErrors(hasError, changeFQDN(flags))
+
+	if flags.Restart {
+		hasError = utils.JoinErrors(hasError, podman_mgradm.StartServices())
+	}
+	return shared.ReportError(hasError)
+}
+
+func openS3Backup(source string, flags *shared.Flagpole) (*s3Backup, error) {
+	client, err := shared.NewS3Client(source, &flags.S3)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := client.List()
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the content of %s"), source)
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf(L("no backup found in %s"), source)
+	}
+	if utils.Contains(objects, shared.IncrementalBackupFile) {
+		return nil, errors.New(L("incremental backups cannot be restored from S3"))
+	}
+
+	backup := s3Backup{client: client, objects: objects, stagingDir: flags.S3.StagingDir}
+	if utils.Contains(objects, shared.ManifestFile) {
+		content, err := backup.download(shared.ManifestFile)
+		if err != nil {
+			return nil, err
+		}
+		var manifest shared.BackupManifest
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return nil, utils.Errorf(err, L("failed to parse %s"), shared.ManifestFile)
+		}
+		backup.manifest = &manifest
+	}
+	return &backup, nil
+}
+
+func (b *s3Backup) restoreVolumes(flags *shared.Flagpole) error {
+	if len(flags.SkipVolumes) == 1 && flags.SkipVolumes[0] == "all" {
+		log.Debug().Msg("Skipping restoring of volumes")
+		return nil
+	}
+
+	volumes := b.list(shared.VolumesSubdir)
+	if len(volumes) == 0 {
+		return errors.New(L("No volumes found in the backup"))
+	}
+	for _, name := range volumes {
+		volName := strings.TrimSuffix(path.Base(name), ".tar")
+		if restore, err := shouldRestoreVolume(volName, flags, podman.IsVolumePresent); err != nil {
+			return err
+		} else if !restore {
+			continue
+		}
+		if flags.DryRun {
+			log.Info().Msgf(L("Would restore volume %s"), volName)
+			continue
+		}
+		if err := runCmd("podman", "volume", "create", "--ignore", volName); err != nil {
+			return utils.Errorf(err, L("Failed to precreate empty volume %s"), volName)
+		}
+		log.Info().Msgf(L("Restoring volume %s"), volName)
+		err := b.importObject(name, flags.SkipVerify, "podman", "volume", "import", volName, "-")
+		if err != nil {
+			return handleVolumeHacks(volName, utils.Errorf(err, L("Failed to import volume %s"), volName))
+		}
+	}
+	return nil
+}
+
+func (b *s3Backup) restoreImages(flags *shared.Flagpole) error {
+	if flags.SkipImages {
+		log.Debug().Msg("Skipping restoring of images")
+		return nil
+	}
+
+	var hasError error
+	for _, name := range b.list(shared.ImagesSubdir) {
+		if flags.DryRun {
+			log.Info().Msgf(L("Would restore image %s"), name)
+			continue
+		}
+		log.Info().Msgf(L("Restoring image %s"), name)
+		if err := b.importObject(name, flags.SkipVerify, "podman", "image", "load", "--quiet"); err != nil {
+			hasError = utils.JoinErrors(hasError, utils.Errorf(err, L("Failed to restore image %s"), name))
+		}
+	}
+	return hasError
+}
+
+// list returns the tarballs of a folder of the backup.
+func (b *s3Backup) list(folder string) []string {
+	result := []string{}
+	for _, name := range b.objects {
+		if strings.HasPrefix(name, folder+"/") && strings.HasSuffix(name, ".tar") {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// importObject feeds an object to the standard input of a command.
+//
+// Unless the verification is skipped, the object is first downloaded to the staging folder and its checksum
+// verified: nothing corrupted or tampered can be imported.
+func (b *s3Backup) importObject(name string, skipVerify bool, command string, args ...string) error {
+	if skipVerify {
+		body, _, err := b.client.Download(name)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		_, err = utils.NewRunner(command, args...).Stdin(body).Exec()
+		return err
+	}
+
+	expected, err := b.checksum(name)
+	if err != nil {
+		return err
+	}
+	tempDir, cleaner, err := b.makeStagingDir()
+	if err != nil {
+		return err
+	}
+	defer cleaner()
+
+	localPath := path.Join(tempDir, path.Base(name))
+	if err := b.downloadTo(name, localPath, expected); err != nil {
+		return err
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = utils.NewRunner(command, args...).Stdin(file).Exec()
+	return err
+}
+
+// makeStagingDir creates a folder to download the objects to verify and returns a function to remove it.
+func (b *s3Backup) makeStagingDir() (string, func(), error) {
+	if b.stagingDir == "" {
+		return utils.TempDir()
+	}
+	dir, err := os.MkdirTemp(b.stagingDir, "mgradm-*")
+	if err != nil {
+		return "", nil, utils.Errorf(err, L("failed to create a folder in %s"), b.stagingDir)
+	}
+	cleaner := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Error().Err(err).Msgf(L("failed to remove %s"), dir)
+		}
+	}
+	return dir, cleaner, nil
+}
+
+// downloadTo writes an object to a local file and verifies its checksum.
+func (b *s3Backup) downloadTo(name string, localPath string, expected string) error {
+	body, size, err := b.client.Download(name)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if size > 0 {
+		if err := shared.CheckFreeSpace(path.Dir(localPath), size); err != nil {
+			return utils.Errorf(err,
+				L("cannot download %[1]s to verify it, free some space or set --s3-stagingdir to another folder"), name,
+			)
+		}
+	}
+
+	file, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	log.Debug().Msgf("Downloading %s to %s", name, localPath)
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hasher), body); err != nil {
+		return utils.Errorf(err, L("failed to download %s"), name)
+	}
+	return verifyS3Checksum(name, hasher, expected)
+}
+
+// readSmallObject downloads and verifies an object, returns nil content if the object doesn't exist.
+func (b *s3Backup) readSmallObject(name string, skipVerify bool) ([]byte, error) {
+	if !utils.Contains(b.objects, name) {
+		return nil, nil
+	}
+	content, err := b.download(name)
+	if err != nil || skipVerify {
+		return content, err
+	}
+	expected, err := b.checksum(name)
+	if err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	hasher.Write(content)
+	if err := verifyS3Checksum(name, hasher, expected); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (b *s3Backup) download(name string) ([]byte, error) {
+	body, _, err := b.client.Download(name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// checksum returns the expected checksum of an object from the manifest or its checksum file.
+func (b *s3Backup) checksum(name string) (string, error) {
+	if b.manifest != nil {
+		if checksum, ok := b.manifest.Files[name]; ok {
+			return checksum, nil
+		}
+	}
+	if !utils.Contains(b.objects, name+".sha256sum") {
+		return "", fmt.Errorf(L("no checksum found for %s"), name)
+	}
+	content, err := b.download(name + ".sha256sum")
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf(L("no checksum found for %s"), name)
+	}
+	return fields[0], nil
+}
+
+func verifyS3Checksum(name string, hasher hash.Hash, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != expected {
+		return fmt.Errorf(L("Checksum of %s does not match"), name)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	podman_mgradm "github.com/uyuni-project/uyuni-tools/mgradm/shared/podman"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// restoreSelection extracts a volume, or some paths inside it, into the live volume or a target directory.
+//
+// The server is only stopped when restoring into the live database volume.
+func restoreSelection(flags *shared.Flagpole, inputDirectory string) error {
+	volName := flags.Volume
+	paths := []string{}
+	for _, selected := range flags.Paths {
+		paths = append(paths, shared.NormalizeVolumePath(selected))
+	}
+
+	chain, err := selectionSanityChecks(inputDirectory, flags)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	destination, err := getSelectionDestination(volName, flags)
+	if err != nil {
+		return shared.AbortError(err, false)
+	}
+
+	if flags.DryRun {
+		what := volName
+		if len(paths) > 0 {
+			what = fmt.Sprintf("%s:%s", volName, strings.Join(paths, ","))
+		}
+		log.Info().Msgf(L("Would extract %[1]s from %[2]s into %[3]s"), what, inputDirectory, destination)
+		return nil
+	}
+
+	// Only the live database needs the server to be stopped
+	serverStopped := false
+	if flags.Target == "" && isDatabaseVolume(volName) && systemd.IsServiceRunning(podman.ServerService) {
+		log.Info().Msg(L("Stopping server service"))
+		if err := podman_mgradm.StopServices(); err != nil {
+			return shared.AbortError(err, false)
+		}
+		serverStopped = true
+	}
+
+	err = extractVolumeChain(volName, paths, chain, destination, flags.SkipVerify)
+
+	var hasError error
+	if serverStopped {
+		log.Info().Msg(L("Restarting server service"))
+		hasError = podman_mgradm.StartServices()
+	}
+	if err != nil {
+		return shared.AbortError(utils.JoinErrors(err, hasError), true)
+	}
+
+	log.Info().Msgf(L("Volume %[1]s restored into %[2]s"), volName, destination)
+	return shared.ReportError(hasError)
+}
+
+func selectionSanityChecks(inputDirectory string, flags *shared.Flagpole) ([]string, error) {
+	if shared.IsS3URL(inputDirectory) || shared.IsArchive(inputDirectory) {
+		return nil, errors.New(L("a single volume can only be restored from a backup directory"))
+	}
+	if flags.New.FQDN != "" {
+		return nil, errors.New(L("the FQDN cannot be changed when restoring a single volume"))
+	}
+	if !utils.FileExists(inputDirectory) {
+		return nil, fmt.Errorf(L("input directory %s does not exists"), inputDirectory)
+	}
+	if flags.Target == "" {
+		if err := shared.SanityChecks(); err != nil {
+			return nil, err
+		}
+	}
+
+	chain, err := shared.GetBackupChain(inputDirectory)
+	if err != nil {
+		return nil, err
+	}
+	for _, backupDir := range chain {
+		if utils.FileExists(path.Join(backupDir, shared.VolumesSubdir, flags.Volume+".tar")) {
+			return chain, nil
+		}
+	}
+	return nil, fmt.Errorf(L("volume %[1]s is not in backup %[2]s"), flags.Volume, inputDirectory)
+}
+
+// getSelectionDestination returns the target directory or the mount point of the live volume.
+func getSelectionDestination(volName string, flags *shared.Flagpole) (string, error) {
+	if flags.Target != "" {
+		target, err := filepath.Abs(flags.Target)
+		if err != nil {
+			return "", err
+		}
+		if !flags.DryRun {
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return "", utils.Errorf(err, L("failed to create %s folder"), target)
+			}
+		}
+		return target, nil
+	}
+
+	if !podman.IsVolumePresent(volName) {
+		return "", fmt.Errorf(L("volume %s does not exist: restore the whole backup or use a target directory"), volName)
+	}
+	return podman.GetVolumeMountPoint(volName)
+}
+
+// extractVolumeChain extracts the selected paths from each backup of the chain, from the oldest to the newest.
+func extractVolumeChain(volName string, paths []string, chain []string, destination string, skipVerify bool) error {
+	found := map[string]bool{}
+	start := getVolumeChainStart(volName, chain)
+	for i := start; i < len(chain); i++ {
+		volumesDir := path.Join(chain[i], shared.VolumesSubdir)
+		tarballPath := path.Join(volumesDir, volName+".tar")
+		if !utils.FileExists(tarballPath) {
+			continue
+		}
+		if !skipVerify {
+			if err := utils.ValidateChecksum(tarballPath); err != nil {
+				return utils.Errorf(err, L("Checksum does not match for volume %s"), tarballPath)
+			}
+		}
+
+		log.Info().Msgf(L("Extracting from %s"), tarballPath)
+		matched, err := shared.ExtractVolumeEntries(tarballPath, destination, paths)
+		if err != nil {
+			return err
+		}
+		for selected := range matched {
+			found[selected] = true
+		}
+
+		// The complete export has no deleted file
+		if i == start {
+			continue
+		}
+		if err := removeDeletedSelection(shared.ManifestPath(volumesDir, volName), paths, destination); err != nil {
+			return err
+		}
+	}
+
+	missing := []string{}
+	for _, selected := range paths {
+		if !found[selected] {
+			missing = append(missing, selected)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(L("paths not found in volume %[1]s: %[2]s"), volName, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// removeDeletedSelection removes the selected files deleted in an incremental backup.
+func removeDeletedSelection(manifestPath string, paths []string, destination string) error {
+	if !utils.FileExists(manifestPath) {
+		return nil
+	}
+	manifest, err := shared.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	deleted := []string{}
+	for _, name := range manifest.Deleted {
+		if shared.MatchesPaths(name, paths) {
+			deleted = append(deleted, name)
+		}
+	}
+	return shared.ApplyDeletions(destination, deleted)
+}
@@ -25,9 +25,14 @@ func restoreSystemdConfiguration(backupSource string, flags *shared.Flagpole) er
 	}
 	defer backupFile.Close()
 
+	return restoreSystemdConfigurationFrom(backupFile, flags)
+}
+
+// restoreSystemdConfigurationFrom restores the systemd files from a tarball.
+func restoreSystemdConfigurationFrom(reader io.Reader, flags *shared.Flagpole) error {
 	var hasError error
 
-	tr := tar.NewReader(backupFile)
+	tr := tar.NewReader(reader)
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+var systemd podman.Systemd = podman.SystemdImpl{}
+
+// Schedule generates and enables the systemd service and timer running the backups.
+func Schedule(
+	_ *types.GlobalFlags,
+	flags *shared.Flagpole,
+	_ *cobra.Command,
+	args []string,
+) error {
+	if flags.Disable {
+		return disableSchedule(flags.DryRun)
+	}
+
+	if len(args) != 1 {
+		return errors.New(L("the directory to store the backups into is required"))
+	}
+	baseDirectory, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := scheduleSanityChecks(flags); err != nil {
+		return err
+	}
+
+	if flags.DryRun {
+		log.Info().Msgf(L("Would schedule backups into %[1]s on %[2]s"), baseDirectory, flags.Schedule)
+		log.Info().Msgf(L("Would run %s"), "systemctl enable --now "+shared.BackupService+".timer")
+		return nil
+	}
+
+	if err := os.MkdirAll(baseDirectory, 0700); err != nil {
+		return utils.Errorf(err, L("failed to create %s folder"), baseDirectory)
+	}
+
+	serviceData := templates.BackupServiceTemplateData{Name: shared.BackupService}
+	if err := utils.WriteTemplateToFile(serviceData,
+		podman.GetServicePath(shared.BackupService), 0555, true); err != nil {
+		return utils.Error(err, L("failed to generate systemd service unit file"))
+	}
+
+	timerData := templates.BackupTimerTemplateData{Name: shared.BackupService, Schedule: flags.Schedule}
+	if err := utils.WriteTemplateToFile(timerData,
+		podman.GetTimerPath(shared.BackupService), 0555, true); err != nil {
+		return utils.Error(err, L("failed to generate systemd timer unit file"))
+	}
+
+	if err := podman.GenerateSystemdConfFile(
+		shared.BackupService, "generated.conf", getServiceEnvironment(baseDirectory, flags), true,
+	); err != nil {
+		return utils.Error(err, L("cannot generate systemd conf file"))
+	}
+
+	if err := systemd.ReloadDaemon(false); err != nil {
+		return err
+	}
+
+	if err := systemd.EnableService(shared.BackupService + ".timer"); err != nil {
+		return err
+	}
+
+	log.Info().Msgf(L("Backups scheduled into %[1]s on %[2]s"), baseDirectory, flags.Schedule)
+	return nil
+}
+
+func scheduleSanityChecks(flags *shared.Flagpole) error {
+	if err := shared.SanityChecks(); err != nil {
+		return err
+	}
+
+	if flags.KeepDaily < 0 || flags.KeepWeekly < 0 {
+		return errors.New(L("the number of backups to keep cannot be negative"))
+	}
+	if flags.KeepDaily == 0 && flags.KeepWeekly == 0 {
+		log.Warn().Msg(L("Only the latest backup will be kept"))
+	}
+
+	if flags.Incremental != "" || flags.Archive {
+		return errors.New(L("only full backup directories can be scheduled"))
+	}
+
+	// Check the schedule now rather than getting a timer that never triggers
+	if utils.IsInstalled("systemd-analyze") {
+		if err := utils.RunCmd("systemd-analyze", "calendar", flags.Schedule); err != nil {
+			return fmt.Errorf(L("invalid schedule: %s"), flags.Schedule)
+		}
+	}
+	return nil
+}
+
+// getServiceEnvironment computes the backup service environment from the backup flags.
+func getServiceEnvironment(baseDirectory string, flags *shared.Flagpole) string {
+	createArgs := []string{}
+	if len(flags.SkipVolumes) > 0 {
+		createArgs = append(createArgs, "--skipvolumes", strings.Join(flags.SkipVolumes, ","))
+	}
+	if len(flags.ExtraVolumes) > 0 {
+		createArgs = append(createArgs, "--extravolumes", strings.Join(flags.ExtraVolumes, ","))
+	}
+	if flags.Workers > 0 {
+		createArgs = append(createArgs, "--workers", strconv.Itoa(flags.Workers))
+	}
+	boolFlags := []struct {
+		name  string
+		value bool
+	}{
+		{"--skipdatabase", flags.SkipDatabase},
+		{"--onlinedatabase", flags.OnlineDatabase},
+		{"--skipimages", flags.SkipImages},
+		{"--skipconfig", flags.SkipConfig},
+	}
+	for _, flag := range boolFlags {
+		if flag.value {
+			createArgs = append(createArgs, flag.name)
+		}
+	}
+
+	return fmt.Sprintf(`Environment="UYUNI_BACKUP_DIR=%s"
+Environment="UYUNI_BACKUP_ARGS=%s"
+Environment=UYUNI_BACKUP_KEEP_DAILY=%d
+Environment=UYUNI_BACKUP_KEEP_WEEKLY=%d`,
+		baseDirectory, strings.Join(createArgs, " "), flags.KeepDaily, flags.KeepWeekly,
+	)
+}
+
+// disableSchedule stops the timer and removes the scheduled backups units.
+//
+// The existing backups are left untouched.
+func disableSchedule(dryRun bool) error {
+	timer := shared.BackupService + ".timer"
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), "systemctl disable --now "+timer)
+		log.Info().Msgf(L("Would remove %s"), podman.GetTimerPath(shared.BackupService))
+		log.Info().Msgf(L("Would remove %s"), podman.GetServicePath(shared.BackupService))
+		log.Info().Msgf(L("Would remove %s"), podman.GetServiceConfFolder(shared.BackupService))
+		return nil
+	}
+
+	timerPath := podman.GetTimerPath(shared.BackupService)
+	if !utils.FileExists(timerPath) {
+		log.Info().Msg(L("No backup is scheduled"))
+		return nil
+	}
+
+	if err := systemd.DisableService(timer); err != nil {
+		return err
+	}
+	for _, unitPath := range []string{
+		timerPath,
+		podman.GetServicePath(shared.BackupService),
+		podman.GetServiceConfFolder(shared.BackupService),
+	} {
+		if err := os.RemoveAll(unitPath); err != nil {
+			return utils.Errorf(err, L("failed to remove %s"), unitPath)
+		}
+	}
+	if err := systemd.ReloadDaemon(false); err != nil {
+		return err
+	}
+	log.Info().Msg(L("Scheduled backups disabled"))
+	return nil
+}
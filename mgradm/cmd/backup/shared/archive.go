@@ -0,0 +1,374 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Supported compressions of the backup archive.
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+var (
+	gzipMagic   = []byte{0x1f, 0x8b}
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	ageMagics   = [][]byte{[]byte("age-encryption.org/"), []byte("-----BEGIN AGE ENCRYPTED FILE-----")}
+	tarMagic    = []byte("ustar")
+	tarMagicPos = 257
+)
+
+// commandsPipeline is a chain of commands, each reading the output of the previous one.
+type commandsPipeline struct {
+	cmds    []*exec.Cmd
+	stderrs []*bytes.Buffer
+}
+
+// add starts a new command reading from stdin and returns its output.
+// If stdout is not nil, the command writes to it and the returned reader is nil.
+func (p *commandsPipeline) add(args []string, stdin io.Reader, stdout io.Writer) (io.ReadCloser, error) {
+	log.Debug().Msgf("Running: %s", strings.Join(args, " "))
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	var out io.ReadCloser
+	if stdout != nil {
+		cmd.Stdout = stdout
+	} else {
+		var err error
+		if out, err = cmd.StdoutPipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, utils.Errorf(err, L("failed to run %s"), args[0])
+	}
+	p.cmds = append(p.cmds, cmd)
+	p.stderrs = append(p.stderrs, &stderr)
+	return out, nil
+}
+
+// wait waits for all the commands of the pipeline to finish.
+func (p *commandsPipeline) wait() error {
+	var hasError error
+	for i, cmd := range p.cmds {
+		if err := cmd.Wait(); err != nil {
+			message := strings.TrimSpace(p.stderrs[i].String())
+			hasError = utils.JoinErrors(hasError, fmt.Errorf(L("%[1]s failed: %[2]s"), cmd.Args[0], message))
+		}
+	}
+	return hasError
+}
+
+// ArchiveWriter writes a backup as a single compressed and encrypted tar stream.
+type ArchiveWriter struct {
+	*tar.Writer
+	file     *os.File
+	stdin    io.WriteCloser
+	pipeline commandsPipeline
+}
+
+// NewArchiveWriter creates the archive file and starts the compression and encryption commands.
+//
+// Exactly one of ageRecipient or gpgRecipient needs to be provided.
+func NewArchiveWriter(
+	archivePath string,
+	compression string,
+	ageRecipient string,
+	gpgRecipient string,
+) (*ArchiveWriter, error) {
+	commands, err := getArchiveWriteCommands(compression, ageRecipient, gpgRecipient)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to create %s"), archivePath)
+	}
+	writer := ArchiveWriter{file: file}
+
+	pipeReader, pipeWriter, err := os.Pipe()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// The first command gets its own copy of the pipe reader
+	defer pipeReader.Close()
+	writer.stdin = pipeWriter
+
+	// Chain the commands, the last one writing into the archive file
+	var input io.Reader = pipeReader
+	for i, command := range commands {
+		var output io.Writer
+		if i == len(commands)-1 {
+			output = file
+		}
+		out, err := writer.pipeline.add(command, input, output)
+		if err != nil {
+			writer.Abort()
+			return nil, err
+		}
+		input = out
+	}
+	writer.Writer = tar.NewWriter(writer.stdin)
+	return &writer, nil
+}
+
+func getArchiveWriteCommands(compression string, ageRecipient string, gpgRecipient string) ([][]string, error) {
+	commands := [][]string{}
+	switch compression {
+	case CompressionGzip:
+		commands = append(commands, []string{"gzip", "-c"})
+	case CompressionZstd:
+		commands = append(commands, []string{"zstd", "-q", "-c"})
+	case CompressionNone, "":
+	default:
+		return nil, fmt.Errorf(L("unsupported compression: %s"), compression)
+	}
+
+	switch {
+	case ageRecipient != "" && gpgRecipient != "":
+		return nil, errors.New(L("age and GPG encryption cannot be used together"))
+	case ageRecipient != "":
+		commands = append(commands, []string{"age", "-r", ageRecipient})
+	case gpgRecipient != "":
+		commands = append(commands, []string{"gpg", "--batch", "--quiet", "--encrypt", "--recipient", gpgRecipient})
+	default:
+		return nil, errors.New(L("an age or GPG recipient is required to encrypt the archive"))
+	}
+
+	for _, command := range commands {
+		if !utils.IsInstalled(command[0]) {
+			return nil, fmt.Errorf(L("install %s before running this command"), command[0])
+		}
+	}
+	return commands, nil
+}
+
+// AddBytes stores content in the archive.
+func (w *ArchiveWriter) AddBytes(name string, content []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0600,
+		Size: int64(len(content)),
+	}
+	if err := w.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := w.Write(content)
+	return err
+}
+
+// AddFile copies a file from the disk into the archive.
+func (w *ArchiveWriter) AddFile(name string, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := w.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// AddVolume stores the files of a volume mounted on root in the archive under the volumes folder.
+//
+// The first entry is the root folder of the volume to keep its owner and permissions.
+// The volume manifest with the hashes of the files is added after them.
+func (w *ArchiveWriter) AddVolume(volume string, root string) error {
+	manifest, err := ScanVolume(root)
+	if err != nil {
+		return utils.Errorf(err, L("failed to list the files of volume %s"), volume)
+	}
+
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	prefix := VolumesSubdir + "/" + volume + "/"
+	// Restore needs the owner and permissions of the root folder, like the 0700 mode of the database volume
+	rootInfo, err := os.Lstat(root)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read %s"), root)
+	}
+	rootHeader, err := tar.FileInfoHeader(rootInfo, "")
+	if err != nil {
+		return err
+	}
+	rootHeader.Name = prefix
+	if err := w.WriteHeader(rootHeader); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := writeVolumeEntry(w.Writer, root, name, prefix, manifest); err != nil {
+			return err
+		}
+	}
+
+	manifest.Full = true
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return utils.Errorf(err, L("failed to serialize the manifest"))
+	}
+	return w.AddBytes(VolumesSubdir+"/"+volume+ManifestSuffix, content)
+}
+
+// Close finishes the archive and waits for the compression and encryption to finish.
+func (w *ArchiveWriter) Close() error {
+	hasError := w.Writer.Close()
+	hasError = utils.JoinErrors(hasError, w.stdin.Close())
+	hasError = utils.JoinErrors(hasError, w.pipeline.wait())
+	return utils.JoinErrors(hasError, w.file.Close())
+}
+
+// Abort stops the commands and removes the incomplete archive.
+func (w *ArchiveWriter) Abort() {
+	if w.stdin != nil {
+		w.stdin.Close()
+	}
+	for _, cmd := range w.pipeline.cmds {
+		_ = cmd.Process.Kill()
+	}
+	_ = w.pipeline.wait()
+	w.file.Close()
+	if err := os.Remove(w.file.Name()); err != nil {
+		log.Debug().Err(err).Msgf("Failed to remove %s", w.file.Name())
+	}
+}
+
+// ArchiveReader reads a backup archive, decrypting and decompressing it on the fly.
+type ArchiveReader struct {
+	*tar.Reader
+	file     *os.File
+	stream   io.Reader
+	pipeline commandsPipeline
+}
+
+// NewArchiveReader opens a backup archive and starts the decryption and decompression commands.
+//
+// The encryption and compression are detected from the content. An age identity file
+// is needed to decrypt age archives, GPG uses the keys from the user keyring.
+func NewArchiveReader(archivePath string, ageIdentity string) (*ArchiveReader, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to open %s"), archivePath)
+	}
+	reader := ArchiveReader{file: file}
+
+	input := bufio.NewReader(file)
+	decrypt, err := getDecryptCommand(input, ageIdentity)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	var stream io.Reader = input
+	if decrypt != nil {
+		out, err := reader.pipeline.add(decrypt, input, nil)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		stream = out
+	}
+
+	decrypted := bufio.NewReader(stream)
+	stream = decrypted
+	if decompress := getDecompressCommand(decrypted); decompress != nil {
+		out, err := reader.pipeline.add(decompress, decrypted, nil)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		stream = out
+	}
+	reader.stream = stream
+	reader.Reader = tar.NewReader(stream)
+	return &reader, nil
+}
+
+func hasMagic(reader *bufio.Reader, magic []byte, offset int) bool {
+	data, _ := reader.Peek(offset + len(magic))
+	return len(data) == offset+len(magic) && bytes.Equal(data[offset:], magic)
+}
+
+func getDecryptCommand(reader *bufio.Reader, ageIdentity string) ([]string, error) {
+	for _, magic := range ageMagics {
+		if hasMagic(reader, magic, 0) {
+			if ageIdentity == "" {
+				return nil, errors.New(L("the archive is encrypted with age, an identity file is required"))
+			}
+			return []string{"age", "--decrypt", "-i", ageIdentity}, nil
+		}
+	}
+
+	// Not encrypted archives
+	if hasMagic(reader, gzipMagic, 0) || hasMagic(reader, zstdMagic, 0) || hasMagic(reader, tarMagic, tarMagicPos) {
+		log.Warn().Msg(L("The backup archive is not encrypted"))
+		return nil, nil
+	}
+	return []string{"gpg", "--batch", "--quiet", "--decrypt"}, nil
+}
+
+func getDecompressCommand(reader *bufio.Reader) []string {
+	if hasMagic(reader, gzipMagic, 0) {
+		return []string{"gzip", "-d", "-c"}
+	}
+	if hasMagic(reader, zstdMagic, 0) {
+		return []string{"zstd", "-q", "-d", "-c"}
+	}
+	return nil
+}
+
+// Close reads the remaining data and waits for the decryption and decompression commands to finish.
+func (r *ArchiveReader) Close() error {
+	var hasError error
+	if r.stream != nil {
+		// The tar reader doesn't read the trailing blocks
+		_, hasError = io.Copy(io.Discard, r.stream)
+	}
+	hasError = utils.JoinErrors(hasError, r.pipeline.wait())
+	return utils.JoinErrors(hasError, r.file.Close())
+}
+
+// IsArchive returns whether the backup path is an archive file rather than a directory.
+func IsArchive(backupPath string) bool {
+	info, err := os.Stat(backupPath)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestGetArchiveWriteCommands(t *testing.T) {
+	_, err := getArchiveWriteCommands(CompressionZstd, "", "")
+	testutils.AssertTrue(t, "missing recipient not detected", err != nil)
+
+	_, err = getArchiveWriteCommands(CompressionZstd, "age1xyz", "admin@example.com")
+	testutils.AssertTrue(t, "both recipients not detected", err != nil)
+
+	_, err = getArchiveWriteCommands("lzma", "age1xyz", "")
+	testutils.AssertTrue(t, "invalid compression not detected", err != nil)
+}
+
+func writeTestArchive(t *testing.T, out io.Writer) {
+	tw := tar.NewWriter(out)
+	content := []byte("secret content")
+	if err := tw.WriteHeader(&tar.Header{Name: SecretBackupFile, Mode: 0600, Size: int64(len(content))}); err != nil {
+		t.Fatalf("failed to write header: %s", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatalf("failed to write content: %s", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tarball: %s", err)
+	}
+}
+
+func TestArchiveReader(t *testing.T) {
+	type testCase struct {
+		name     string
+		compress bool
+	}
+
+	for _, test := range []testCase{{"plain", false}, {"gzip", true}} {
+		if test.compress && !utils.IsInstalled("gzip") {
+			continue
+		}
+		archivePath := path.Join(t.TempDir(), "backup.tar")
+		file, err := os.Create(archivePath)
+		if err != nil {
+			t.Fatalf("failed to create archive: %s", err)
+		}
+		if test.compress {
+			gzipWriter := gzip.NewWriter(file)
+			writeTestArchive(t, gzipWriter)
+			gzipWriter.Close()
+		} else {
+			writeTestArchive(t, file)
+		}
+		file.Close()
+
+		testutils.AssertTrue(t, test.name+": archive not detected", IsArchive(archivePath))
+
+		reader, err := NewArchiveReader(archivePath, "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.name, err)
+		}
+		header, err := reader.Next()
+		if err != nil {
+			t.Fatalf("%s: failed to read the archive: %s", test.name, err)
+		}
+		testutils.AssertEquals(t, test.name+": wrong entry name", SecretBackupFile, header.Name)
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%s: failed to read the entry: %s", test.name, err)
+		}
+		testutils.AssertEquals(t, test.name+": wrong entry content", "secret content", string(content))
+		_, err = reader.Next()
+		testutils.AssertEquals(t, test.name+": unexpected entry", io.EOF, err)
+		if err := reader.Close(); err != nil {
+			t.Errorf("%s: failed to close the archive: %s", test.name, err)
+		}
+	}
+}
+
+func TestAddVolume(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, path.Join(root, "etc/rhn.conf"), "java.hostname = server.example.com\n")
+	if err := os.Chmod(root, 0700); err != nil {
+		t.Fatalf("failed to change the volume root mode: %s", err)
+	}
+
+	var buf bytes.Buffer
+	writer := ArchiveWriter{Writer: tar.NewWriter(&buf)}
+	if err := writer.AddVolume("etc-rhn", root); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := writer.Writer.Close(); err != nil {
+		t.Fatalf("failed to close tarball: %s", err)
+	}
+
+	reader := tar.NewReader(&buf)
+	names := []string{}
+	var manifest VolumeManifest
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read tarball: %s", err)
+		}
+		names = append(names, header.Name)
+		if header.Name == "volumes/etc-rhn/" {
+			testutils.AssertEquals(t, "wrong root type", byte(tar.TypeDir), header.Typeflag)
+			testutils.AssertEquals(t, "wrong root mode", int64(0700), header.Mode&0777)
+			testutils.AssertEquals(t, "wrong root owner", os.Getuid(), header.Uid)
+			testutils.AssertEquals(t, "wrong root group", os.Getgid(), header.Gid)
+		}
+		if header.Name == "volumes/etc-rhn"+ManifestSuffix {
+			content, _ := io.ReadAll(reader)
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				t.Fatalf("failed to parse the manifest: %s", err)
+			}
+		}
+	}
+	expected := []string{
+		"volumes/etc-rhn/", "volumes/etc-rhn/etc/", "volumes/etc-rhn/etc/rhn.conf", "volumes/etc-rhn" + ManifestSuffix,
+	}
+	testutils.AssertEquals(t, "wrong archive content", expected, names)
+	testutils.AssertTrue(t, "manifest should be full", manifest.Full)
+	testutils.AssertTrue(t, "missing file hash", manifest.Files["etc/rhn.conf"].Hash != "")
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// DatabaseBackupFile is the file describing an online database backup.
+const DatabaseBackupFile = "database.json"
+
+// DatabaseBackupData describes an online backup of the database and its write-ahead log position.
+type DatabaseBackupData struct {
+	Method             string
+	StartWALLocation   string
+	StartWALFile       string
+	CheckpointLocation string
+	Timeline           string
+	StartTime          string
+}
+
+var walLocationRegex = regexp.MustCompile(`^([0-9A-F]+/[0-9A-F]+) \(file ([0-9A-F]+)\)$`)
+
+// ParseBackupLabel extracts the write-ahead log position from a PostgreSQL backup_label file.
+func ParseBackupLabel(label []byte) *DatabaseBackupData {
+	data := DatabaseBackupData{}
+	scanner := bufio.NewScanner(bytes.NewReader(label))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		switch parts[0] {
+		case "START WAL LOCATION":
+			if matches := walLocationRegex.FindStringSubmatch(value); matches != nil {
+				data.StartWALLocation = matches[1]
+				data.StartWALFile = matches[2]
+			} else {
+				data.StartWALLocation = value
+			}
+		case "CHECKPOINT LOCATION":
+			data.CheckpointLocation = value
+		case "START TIMELINE":
+			data.Timeline = value
+		case "START TIME":
+			data.StartTime = value
+		}
+	}
+	return &data
+}
+
+// ReadBackupLabel finds and parses the backup_label file in a pg_basebackup tarball.
+func ReadBackupLabel(tarballPath string) (*DatabaseBackupData, error) {
+	file, err := os.Open(tarballPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimPrefix(header.Name, "./") != "backup_label" {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		return ParseBackupLabel(content), nil
+	}
+	return nil, errors.New(L("no backup_label file in the database backup"))
+}
+
+// ReadDatabaseBackupData loads the online database backup description of a backup directory.
+//
+// If the database was not backed up online, nil is returned without error.
+func ReadDatabaseBackupData(backupDir string) (*DatabaseBackupData, error) {
+	dataPath := path.Join(backupDir, DatabaseBackupFile)
+	content, err := os.ReadFile(dataPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %s"), dataPath)
+	}
+	var data DatabaseBackupData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %s"), dataPath)
+	}
+	return &data, nil
+}
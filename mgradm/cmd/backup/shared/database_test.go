@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+const testBackupLabel = `START WAL LOCATION: 0/2000028 (file 000000010000000000000002)
+CHECKPOINT LOCATION: 0/2000060
+BACKUP METHOD: streamed
+BACKUP FROM: primary
+START TIME: 2025-03-12 10:51:06 UTC
+LABEL: uyuni backup
+START TIMELINE: 1
+`
+
+func TestReadBackupLabel(t *testing.T) {
+	tarballPath := path.Join(t.TempDir(), "var-pgsql.tar")
+	file, err := os.Create(tarballPath)
+	if err != nil {
+		t.Fatalf("failed to create tarball: %s", err)
+	}
+	tw := tar.NewWriter(file)
+	for _, entry := range []struct{ name, content string }{
+		{"PG_VERSION", "16\n"},
+		{"backup_label", testBackupLabel},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0600, Size: int64(len(entry.content))}); err != nil {
+			t.Fatalf("failed to write header: %s", err)
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("failed to write content: %s", err)
+		}
+	}
+	tw.Close()
+	file.Close()
+
+	data, err := ReadBackupLabel(tarballPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong WAL location", "0/2000028", data.StartWALLocation)
+	testutils.AssertEquals(t, "wrong WAL file", "000000010000000000000002", data.StartWALFile)
+	testutils.AssertEquals(t, "wrong checkpoint", "0/2000060", data.CheckpointLocation)
+	testutils.AssertEquals(t, "wrong timeline", "1", data.Timeline)
+	testutils.AssertEquals(t, "wrong start time", "2025-03-12 10:51:06 UTC", data.StartTime)
+}
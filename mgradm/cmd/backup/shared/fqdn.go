@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// FQDNFile is a file pattern relative to the root of a volume for files containing the server FQDN.
+type FQDNFile struct {
+	Volume  string
+	Pattern string
+}
+
+// FQDNFiles lists the files of the server volumes holding the FQDN.
+var FQDNFiles = []FQDNFile{
+	{Volume: utils.EtcRhnVolumeMount.Name, Pattern: "rhn.conf"},
+	{Volume: "etc-salt", Pattern: "master.d/*.conf"},
+	{Volume: "etc-cobbler", Pattern: "settings.yaml"},
+	{Volume: "etc-cobbler", Pattern: "settings.d/*.settings"},
+	{Volume: "srv-www", Pattern: "htdocs/pub/bootstrap/*.sh"},
+	{Volume: "srv-susemanager", Pattern: "salt/bootstrap/*.sh"},
+	{Volume: "srv-susemanager", Pattern: "pillar_data/*"},
+	{Volume: "srv-susemanager", Pattern: "pillar_data/*/*"},
+	{Volume: "srv-susemanager", Pattern: "formula_data/pillar/*"},
+	{Volume: "srv-pillar", Pattern: "*.sls"},
+	{Volume: "srv-pillar", Pattern: "*/*.sls"},
+}
+
+// FQDNRenameSQL updates the database rows holding the server FQDN, like spacewalk-hostname-rename does.
+//
+// The old and new FQDNs are passed as the old_fqdn and new_fqdn psql variables.
+const FQDNRenameSQL = `
+BEGIN;
+UPDATE rhnTemplateString SET value = :'new_fqdn' WHERE label = 'hostname' AND value = :'old_fqdn';
+UPDATE suseSaltPillar
+    SET pillar = replace(pillar::text, to_json(:'old_fqdn'::text)::text, to_json(:'new_fqdn'::text)::text)::jsonb
+    WHERE pillar::text LIKE '%' || to_json(:'old_fqdn'::text)::text || '%';
+COMMIT;
+`
+
+// ReadServerFQDN returns the value of java.hostname in an rhn.conf file.
+func ReadServerFQDN(rhnConfPath string) (string, error) {
+	return ReadRhnConfValue(rhnConfPath, "java.hostname")
+}
+
+// ReadRhnConfValue returns the value of a key in an rhn.conf file.
+func ReadRhnConfValue(rhnConfPath string, name string) (string, error) {
+	file, err := os.Open(rhnConfPath)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to open %s"), rhnConfPath)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if found && strings.TrimSpace(key) == name {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", utils.Errorf(err, L("failed to read %s"), rhnConfPath)
+	}
+	return "", fmt.Errorf(L("no %s defined in rhn.conf"), name)
+}
+
+// ReplaceFQDN replaces the old FQDN by the new one, except when it is part of a longer host name.
+func ReplaceFQDN(content []byte, oldFQDN string, newFQDN string) ([]byte, bool) {
+	if oldFQDN == "" {
+		return content, false
+	}
+	var result bytes.Buffer
+	changed := false
+	old := []byte(oldFQDN)
+	start := 0
+	offset := 0
+	for {
+		index := bytes.Index(content[offset:], old)
+		if index < 0 {
+			break
+		}
+		index += offset
+		end := index + len(old)
+		if (index == 0 || !isHostnameChar(content[index-1])) && isHostnameEnd(content, end) {
+			result.Write(content[start:index])
+			result.WriteString(newFQDN)
+			start = end
+			changed = true
+		}
+		offset = end
+	}
+	result.Write(content[start:])
+	return result.Bytes(), changed
+}
+
+func isHostnameChar(c byte) bool {
+	return c == '.' || isLabelChar(c)
+}
+
+func isLabelChar(c byte) bool {
+	return c == '-' || c == '_' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// isHostnameEnd returns whether the host name ends at the end index of the content.
+//
+// A dot only continues the host name when it is followed by another label:
+// a trailing dot of a DNS name or a dot ending a sentence is not part of it.
+func isHostnameEnd(content []byte, end int) bool {
+	if end == len(content) {
+		return true
+	}
+	if content[end] == '.' {
+		return end+1 == len(content) || !isLabelChar(content[end+1])
+	}
+	return !isLabelChar(content[end])
+}
+
+// RewriteFQDN replaces the FQDN in the files matching the pattern relative to the root folder.
+//
+// Returns the changed files.
+func RewriteFQDN(root string, pattern string, oldFQDN string, newFQDN string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(root, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	changedFiles := []string{}
+	for _, file := range files {
+		info, err := os.Lstat(file)
+		if err != nil {
+			return changedFiles, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return changedFiles, utils.Errorf(err, L("failed to read %s"), file)
+		}
+		newContent, changed := ReplaceFQDN(content, oldFQDN, newFQDN)
+		if !changed {
+			continue
+		}
+		log.Debug().Msgf("Changing FQDN in %s", file)
+		if err := os.WriteFile(file, newContent, info.Mode().Perm()); err != nil {
+			return changedFiles, utils.Errorf(err, L("failed to write %s"), file)
+		}
+		changedFiles = append(changedFiles, file)
+	}
+	return changedFiles, nil
+}
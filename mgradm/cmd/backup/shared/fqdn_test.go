@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestReplaceFQDN(t *testing.T) {
+	data := [][]string{
+		{"java.hostname = old.example.com\n", "java.hostname = new.example.com\n"},
+		{"url: https://old.example.com/pub", "url: https://new.example.com/pub"},
+		{"old.example.com:443 old.example.com", "new.example.com:443 new.example.com"},
+		{"other.old.example.com", "other.old.example.com"},
+		{"old.example.com.au", "old.example.com.au"},
+		{"my-old.example.com", "my-old.example.com"},
+		{"The server is old.example.com.", "The server is new.example.com."},
+		{"server IN A old.example.com.\n", "server IN A new.example.com.\n"},
+		{"old.example.com. old.example.com.au.", "new.example.com. old.example.com.au."},
+	}
+	for _, testCase := range data {
+		actual, changed := ReplaceFQDN([]byte(testCase[0]), "old.example.com", "new.example.com")
+		testutils.AssertEquals(t, "testcase "+testCase[0]+": wrong content", testCase[1], string(actual))
+		testutils.AssertEquals(t, "testcase "+testCase[0]+": wrong changed flag", testCase[0] != testCase[1], changed)
+	}
+}
+
+func TestRewriteFQDN(t *testing.T) {
+	root := t.TempDir()
+	rhnConf := path.Join(root, "rhn.conf")
+	writeTestFile(t, rhnConf, "db_name = uyuni\njava.hostname = old.example.com\n")
+	writeTestFile(t, path.Join(root, "other.conf"), "host = old.example.com\n")
+
+	fqdn, err := ReadServerFQDN(rhnConf)
+	if err != nil {
+		t.Fatalf("unexpected error reading the FQDN: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong FQDN", "old.example.com", fqdn)
+	dbName, err := ReadRhnConfValue(rhnConf, "db_name")
+	testutils.AssertEquals(t, "unexpected error reading the database name", nil, err)
+	testutils.AssertEquals(t, "wrong database name", "uyuni", dbName)
+	_, err = ReadRhnConfValue(rhnConf, "db_host")
+	testutils.AssertTrue(t, "missing key not detected", err != nil)
+
+	changed, err := RewriteFQDN(root, "rhn.conf", fqdn, "new.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong changed files", []string{rhnConf}, changed)
+
+	content, _ := os.ReadFile(rhnConf)
+	testutils.AssertEquals(t, "wrong rhn.conf", "db_name = uyuni\njava.hostname = new.example.com\n", string(content))
+	content, _ = os.ReadFile(path.Join(root, "other.conf"))
+	testutils.AssertEquals(t, "unmatched file should not change", "host = old.example.com\n", string(content))
+}
@@ -0,0 +1,443 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// IncrementalBackupFile is the file describing the base of an incremental backup.
+const IncrementalBackupFile = "incremental.json"
+
+// ManifestSuffix is the suffix of the volume manifest files, appended to the volume name.
+const ManifestSuffix = ".manifest.json"
+
+// IncrementalData describes which backup an incremental backup is based on.
+type IncrementalData struct {
+	// Base is the path to the previous backup in the chain, relative to the incremental backup folder.
+	//
+	// Older backups may have an absolute path.
+	Base string
+}
+
+// FileEntry describes the state of a file of a volume at backup time.
+type FileEntry struct {
+	Size    int64
+	ModTime int64
+	Mode    fs.FileMode
+	UID     int
+	GID     int
+	Link    string `json:",omitempty"`
+	// Hash is the sha256 of the content of the regular files.
+	Hash string `json:",omitempty"`
+}
+
+// sameContent returns whether the entries only differ by their modification time and the content hash
+// is the one of the other entry.
+func (e FileEntry) sameContent(other FileEntry, hash string) bool {
+	e.ModTime = other.ModTime
+	e.Hash = other.Hash
+	return e == other && hash == other.Hash
+}
+
+// VolumeManifest lists the files of a volume at backup time.
+type VolumeManifest struct {
+	Files map[string]FileEntry
+	// Deleted lists the files removed since the base backup.
+	Deleted []string `json:",omitempty"`
+	// Full is true if the volume tarball contains all the files, not only the changed ones.
+	Full bool `json:",omitempty"`
+}
+
+// VolumeDelta is the list of changes of a volume since a base manifest.
+type VolumeDelta struct {
+	Root     string
+	Changed  []string
+	Deleted  []string
+	Manifest *VolumeManifest
+}
+
+// Size returns the amount of bytes of the changed files.
+func (d *VolumeDelta) Size() int64 {
+	var size int64
+	for _, file := range d.Changed {
+		size += d.Manifest.Files[file].Size
+	}
+	return size
+}
+
+// ScanVolume walks a volume root folder and creates a manifest of its files without computing the hashes.
+//
+// Use HashVolume or HashTarball to compute them.
+func ScanVolume(root string) (*VolumeManifest, error) {
+	manifest := VolumeManifest{Files: map[string]FileEntry{}}
+	err := filepath.WalkDir(root, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, filePath)
+		if err != nil || relPath == "." {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fileEntry := FileEntry{
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+			Mode:    info.Mode(),
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			fileEntry.UID = int(stat.Uid)
+			fileEntry.GID = int(stat.Gid)
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if fileEntry.Link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+		if info.IsDir() {
+			// Directory size and modification time change with their content, don't compare them
+			fileEntry.Size = 0
+			fileEntry.ModTime = 0
+		}
+		manifest.Files[filepath.ToSlash(relPath)] = fileEntry
+		return nil
+	})
+	return &manifest, err
+}
+
+// HashVolume computes the hashes of the regular files of a volume manifest.
+func HashVolume(manifest *VolumeManifest, root string) error {
+	for name, entry := range manifest.Files {
+		if !entry.Mode.IsRegular() {
+			continue
+		}
+		hash, err := hashFile(path.Join(root, name))
+		if err != nil {
+			return err
+		}
+		entry.Hash = hash
+		manifest.Files[name] = entry
+	}
+	return nil
+}
+
+// HashTarball computes the hashes of the regular files of a volume manifest from the volume tarball stream.
+//
+// The files with a different size than in the manifest have changed since the scan and are not hashed.
+func HashTarball(manifest *VolumeManifest, reader io.Reader) error {
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		entry, found := manifest.Files[name]
+		if !found || !entry.Mode.IsRegular() || entry.Size != header.Size {
+			continue
+		}
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, tr); err != nil {
+			return err
+		}
+		entry.Hash = hex.EncodeToString(hasher.Sum(nil))
+		manifest.Files[name] = entry
+	}
+}
+
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to read %s"), filePath)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", utils.Errorf(err, L("failed to read %s"), filePath)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// VerifyHashes checks the hashes of the regular files read from a backup against the manifest ones.
+//
+// hashes maps the files relative to the volume root to their sha256.
+func (m *VolumeManifest) VerifyHashes(hashes map[string]string) error {
+	names := make([]string, 0, len(m.Files))
+	for name, entry := range m.Files {
+		if entry.Hash != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var hasError error
+	for _, name := range names {
+		hash, found := hashes[name]
+		if !found {
+			hasError = utils.JoinErrors(hasError, fmt.Errorf(L("%s is missing from the backup"), name))
+		} else if hash != m.Files[name].Hash {
+			hasError = utils.JoinErrors(hasError, fmt.Errorf(L("checksum does not match for %s"), name))
+		}
+	}
+	return hasError
+}
+
+// DiffVolume compares the current content of a volume with the manifest of the base backup.
+//
+// The hashes of the unchanged files are copied from the base manifest.
+// A regular file with only a different modification time is hashed and
+// considered unchanged if its content is still the one of the base backup.
+func DiffVolume(base *VolumeManifest, root string) (*VolumeDelta, error) {
+	return diffVolume(base, root, true)
+}
+
+// EstimateDelta compares the current content of a volume with the manifest of the base backup without reading
+// the files.
+//
+// The files with a different modification time are considered as changed: the delta can be bigger than the real one.
+func EstimateDelta(base *VolumeManifest, root string) (*VolumeDelta, error) {
+	return diffVolume(base, root, false)
+}
+
+func diffVolume(base *VolumeManifest, root string, hashTouched bool) (*VolumeDelta, error) {
+	current, err := ScanVolume(root)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := VolumeDelta{Root: root, Manifest: current}
+	for name, entry := range current.Files {
+		baseEntry, exists := base.Files[name]
+		if exists && baseEntry.Hash != "" && entry.Mode.IsRegular() && entry.Size == baseEntry.Size {
+			hash := baseEntry.Hash
+			if entry.ModTime != baseEntry.ModTime {
+				if !hashTouched {
+					delta.Changed = append(delta.Changed, name)
+					continue
+				}
+				if hash, err = hashFile(path.Join(root, name)); err != nil {
+					return nil, err
+				}
+			}
+			if entry.sameContent(baseEntry, hash) {
+				entry.Hash = hash
+				current.Files[name] = entry
+				continue
+			}
+		} else if exists && baseEntry == entry {
+			continue
+		}
+		delta.Changed = append(delta.Changed, name)
+	}
+	for name := range base.Files {
+		if _, exists := current.Files[name]; !exists {
+			delta.Deleted = append(delta.Deleted, name)
+		}
+	}
+	sort.Strings(delta.Changed)
+	sort.Strings(delta.Deleted)
+	current.Deleted = delta.Deleted
+	return &delta, nil
+}
+
+// WriteDelta writes the changed files of the delta into a tarball and computes their hashes.
+func WriteDelta(delta *VolumeDelta, tarballPath string) error {
+	out, err := os.Create(tarballPath)
+	if err != nil {
+		return utils.Errorf(err, L("failed to create %s"), tarballPath)
+	}
+	defer out.Close()
+
+	tw := tar.NewWriter(out)
+	for _, name := range delta.Changed {
+		if err := writeVolumeEntry(tw, delta.Root, name, "", delta.Manifest); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// writeVolumeEntry adds a file of a volume to a tarball, prefixing its name with prefix.
+// The hash of the regular files is computed and stored in the manifest.
+func writeVolumeEntry(tw *tar.Writer, root string, name string, prefix string, manifest *VolumeManifest) error {
+	filePath := path.Join(root, name)
+	info, err := os.Lstat(filePath)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read %s"), filePath)
+	}
+	entry := manifest.Files[name]
+
+	header, err := tar.FileInfoHeader(info, entry.Link)
+	if err != nil {
+		return err
+	}
+	header.Name = prefix + name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read %s"), filePath)
+	}
+	defer file.Close()
+
+	// The file may have changed since the scan: only write what is in the header
+	hasher := sha256.New()
+	if _, err := io.CopyN(io.MultiWriter(tw, hasher), file, header.Size); err != nil {
+		return utils.Errorf(err, L("failed to write %s to the backup"), filePath)
+	}
+	entry.Hash = hex.EncodeToString(hasher.Sum(nil))
+	manifest.Files[name] = entry
+	return nil
+}
+
+// ApplyDeletions removes the files deleted in an incremental backup from a restored volume.
+func ApplyDeletions(root string, deleted []string) error {
+	var hasError error
+	// Remove the deepest files first so that the folders are empty when removed
+	sorted := append([]string{}, deleted...)
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	for _, name := range sorted {
+		target := filepath.Join(root, filepath.FromSlash(name))
+		rel, err := filepath.Rel(root, target)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			hasError = utils.JoinErrors(hasError, fmt.Errorf(L("invalid path in the backup: %s"), name))
+			continue
+		}
+		if err := os.RemoveAll(target); err != nil {
+			hasError = utils.JoinErrors(hasError, err)
+		}
+	}
+	return hasError
+}
+
+// ManifestPath returns the path to the manifest of a volume in a volumes backup folder.
+func ManifestPath(volumesDir string, volume string) string {
+	return path.Join(volumesDir, volume+ManifestSuffix)
+}
+
+// ReadManifest loads a volume manifest file.
+func ReadManifest(manifestPath string) (*VolumeManifest, error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %s"), manifestPath)
+	}
+	var manifest VolumeManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %s"), manifestPath)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]FileEntry{}
+	}
+	return &manifest, nil
+}
+
+// ReadBaseManifest loads the manifest of a volume in a base backup directory.
+//
+// An empty manifest is returned if the volume was not part of the base backup.
+func ReadBaseManifest(baseDirectory string, volume string) (*VolumeManifest, error) {
+	manifestPath := ManifestPath(path.Join(baseDirectory, VolumesSubdir), volume)
+	if !utils.FileExists(manifestPath) {
+		log.Warn().Msgf(L("No manifest for volume %s in the base backup, backing it up entirely"), volume)
+		return &VolumeManifest{Files: map[string]FileEntry{}}, nil
+	}
+	return ReadManifest(manifestPath)
+}
+
+// WriteManifest stores a volume manifest file with its checksum.
+func WriteManifest(manifest *VolumeManifest, manifestPath string) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return utils.Errorf(err, L("failed to serialize the manifest"))
+	}
+	if err := os.WriteFile(manifestPath, content, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write %s"), manifestPath)
+	}
+	return utils.CreateChecksum(manifestPath)
+}
+
+// ReadIncrementalData loads the incremental backup description of a backup directory.
+//
+// If the backup is not incremental, nil is returned without error.
+func ReadIncrementalData(backupDir string) (*IncrementalData, error) {
+	dataPath := path.Join(backupDir, IncrementalBackupFile)
+	content, err := os.ReadFile(dataPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %s"), dataPath)
+	}
+	var data IncrementalData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %s"), dataPath)
+	}
+	return &data, nil
+}
+
+// GetBackupChain returns the list of backup directories to restore, starting with the full backup.
+func GetBackupChain(backupDir string) ([]string, error) {
+	chain := []string{}
+	current := backupDir
+	for {
+		absPath, err := filepath.Abs(current)
+		if err != nil {
+			return nil, err
+		}
+		if utils.Contains(chain, absPath) {
+			return nil, fmt.Errorf(L("loop detected in the incremental backups chain at %s"), absPath)
+		}
+		if !utils.FileExists(absPath) {
+			return nil, fmt.Errorf(L("base backup %s is missing"), absPath)
+		}
+		chain = append([]string{absPath}, chain...)
+
+		data, err := ReadIncrementalData(absPath)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			break
+		}
+		log.Debug().Msgf("Backup %s is based on %s", absPath, data.Base)
+		current = data.Base
+		if !filepath.IsAbs(current) {
+			current = filepath.Join(absPath, current)
+		}
+	}
+	return chain, nil
+}
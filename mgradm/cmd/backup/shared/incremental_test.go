@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func writeTestFile(t *testing.T, filePath string, content string) {
+	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+		t.Fatalf("failed to create folder: %s", err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+}
+
+func TestDiffVolume(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, path.Join(root, "unchanged"), "same")
+	writeTestFile(t, path.Join(root, "changed"), "before")
+	writeTestFile(t, path.Join(root, "sub/removed"), "gone")
+	if err := os.Symlink("unchanged", path.Join(root, "link")); err != nil {
+		t.Fatalf("failed to create link: %s", err)
+	}
+
+	base, err := ScanVolume(root)
+	if err != nil {
+		t.Fatalf("unexpected scan error: %s", err)
+	}
+	testutils.AssertEquals(t, "unexpected files count", 5, len(base.Files))
+	testutils.AssertEquals(t, "wrong link target", "unchanged", base.Files["link"].Link)
+
+	writeTestFile(t, path.Join(root, "changed"), "after the change")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path.Join(root, "changed"), later, later); err != nil {
+		t.Fatalf("failed to change time: %s", err)
+	}
+	writeTestFile(t, path.Join(root, "sub/added"), "new")
+	if err := os.Remove(path.Join(root, "sub/removed")); err != nil {
+		t.Fatalf("failed to remove file: %s", err)
+	}
+
+	delta, err := DiffVolume(base, root)
+	if err != nil {
+		t.Fatalf("unexpected diff error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong changed files", []string{"changed", "sub/added"}, delta.Changed)
+	testutils.AssertEquals(t, "wrong deleted files", []string{"sub/removed"}, delta.Deleted)
+	testutils.AssertEquals(t, "wrong deleted files in manifest", []string{"sub/removed"}, delta.Manifest.Deleted)
+	testutils.AssertEquals(t, "wrong delta size", int64(len("after the change")+len("new")), delta.Size())
+
+	tarballPath := path.Join(t.TempDir(), "volume.tar")
+	if err := WriteDelta(delta, tarballPath); err != nil {
+		t.Fatalf("unexpected write error: %s", err)
+	}
+	testutils.AssertTrue(t, "missing hash of a stored file", delta.Manifest.Files["changed"].Hash != "")
+	testutils.AssertEquals(t, "unchanged file should not be hashed", "", delta.Manifest.Files["unchanged"].Hash)
+
+	tarball, err := os.Open(tarballPath)
+	if err != nil {
+		t.Fatalf("failed to open tarball: %s", err)
+	}
+	defer tarball.Close()
+	reader := tar.NewReader(tarball)
+	names := []string{}
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read tarball: %s", err)
+		}
+		names = append(names, header.Name)
+	}
+	testutils.AssertEquals(t, "wrong tarball content", []string{"changed", "sub/added"}, names)
+}
+
+func TestDiffVolumeHashes(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, path.Join(root, "touched"), "same")
+	writeTestFile(t, path.Join(root, "rewritten"), "before")
+
+	base, err := ScanVolume(root)
+	if err != nil {
+		t.Fatalf("unexpected scan error: %s", err)
+	}
+	if err := HashVolume(base, root); err != nil {
+		t.Fatalf("unexpected hash error: %s", err)
+	}
+	testutils.AssertTrue(t, "missing hash in the full manifest", base.Files["touched"].Hash != "")
+
+	// Same size, but only one of the files has a different content
+	writeTestFile(t, path.Join(root, "rewritten"), "after!")
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{"touched", "rewritten"} {
+		if err := os.Chtimes(path.Join(root, name), later, later); err != nil {
+			t.Fatalf("failed to change time: %s", err)
+		}
+	}
+
+	delta, err := DiffVolume(base, root)
+	if err != nil {
+		t.Fatalf("unexpected diff error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong changed files", []string{"rewritten"}, delta.Changed)
+	testutils.AssertEquals(t, "hash not kept", base.Files["touched"].Hash, delta.Manifest.Files["touched"].Hash)
+	testutils.AssertEquals(t, "new modification time not stored",
+		later.UnixNano(), delta.Manifest.Files["touched"].ModTime,
+	)
+}
+
+func TestEstimateDelta(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, path.Join(root, "touched"), "same")
+	writeTestFile(t, path.Join(root, "unchanged"), "same")
+
+	base, err := ScanVolume(root)
+	if err != nil {
+		t.Fatalf("unexpected scan error: %s", err)
+	}
+	if err := HashVolume(base, root); err != nil {
+		t.Fatalf("unexpected hash error: %s", err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path.Join(root, "touched"), later, later); err != nil {
+		t.Fatalf("failed to change time: %s", err)
+	}
+
+	// The files are not read: the touched file is counted as changed
+	delta, err := EstimateDelta(base, root)
+	if err != nil {
+		t.Fatalf("unexpected estimate error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong changed files", []string{"touched"}, delta.Changed)
+	testutils.AssertEquals(t, "wrong estimated size", int64(len("same")), delta.Size())
+}
+
+func TestHashTarball(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, path.Join(root, "file"), "content")
+	writeTestFile(t, path.Join(root, "sub/other"), "other content")
+	writeTestFile(t, path.Join(root, "sub/changed"), "changed later")
+
+	manifest, err := ScanVolume(root)
+	if err != nil {
+		t.Fatalf("unexpected scan error: %s", err)
+	}
+	expected, err := ScanVolume(root)
+	if err != nil {
+		t.Fatalf("unexpected scan error: %s", err)
+	}
+	if err := HashVolume(expected, root); err != nil {
+		t.Fatalf("unexpected hash error: %s", err)
+	}
+
+	// Write the tarball like podman volume export does
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"./", ""},
+		{"./file", "content"},
+		{"./sub/", ""},
+		{"./sub/other", "other content"},
+		{"./sub/changed", "changed"},
+		{"./sub/added", "added after the scan"},
+	}
+	for _, file := range files {
+		header := tar.Header{Name: file.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(file.content))}
+		if strings.HasSuffix(file.name, "/") {
+			header = tar.Header{Name: file.name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatalf("failed to write header: %s", err)
+		}
+		if _, err := tw.Write([]byte(file.content)); err != nil {
+			t.Fatalf("failed to write content: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tarball: %s", err)
+	}
+
+	if err := HashTarball(manifest, &buf); err != nil {
+		t.Fatalf("unexpected hash error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong file hash", expected.Files["file"].Hash, manifest.Files["file"].Hash)
+	testutils.AssertEquals(t, "wrong sub file hash", expected.Files["sub/other"].Hash, manifest.Files["sub/other"].Hash)
+	testutils.AssertEquals(t, "changed file should not be hashed", "", manifest.Files["sub/changed"].Hash)
+	testutils.AssertEquals(t, "folder should not be hashed", "", manifest.Files["sub"].Hash)
+	_, found := manifest.Files["sub/added"]
+	testutils.AssertTrue(t, "file added after the scan should not be in the manifest", !found)
+}
+
+func TestVerifyHashes(t *testing.T) {
+	manifest := VolumeManifest{Files: map[string]FileEntry{
+		"etc":          {Mode: os.ModeDir | 0755},
+		"etc/rhn.conf": {Size: 4, Mode: 0644, Hash: "abcd"},
+	}}
+
+	err := manifest.VerifyHashes(map[string]string{"etc/rhn.conf": "abcd"})
+	testutils.AssertEquals(t, "unexpected error", nil, err)
+
+	err = manifest.VerifyHashes(map[string]string{"etc/rhn.conf": "1234"})
+	testutils.AssertTrue(t, "mismatching hash not detected", err != nil)
+
+	err = manifest.VerifyHashes(map[string]string{})
+	testutils.AssertTrue(t, "missing file not detected", err != nil)
+}
+
+func TestApplyDeletions(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, path.Join(root, "keep"), "keep")
+	writeTestFile(t, path.Join(root, "dir/removed"), "removed")
+
+	err := ApplyDeletions(root, []string{"dir", "dir/removed", "../outside"})
+	testutils.AssertTrue(t, "path outside of the volume should be rejected", err != nil)
+	testutils.AssertTrue(t, "file should be kept", utils.FileExists(path.Join(root, "keep")))
+	testutils.AssertTrue(t, "folder should be removed", !utils.FileExists(path.Join(root, "dir")))
+}
+
+func TestGetBackupChain(t *testing.T) {
+	full := t.TempDir()
+	incr1 := t.TempDir()
+	incr2 := t.TempDir()
+	writeTestFile(t, path.Join(incr1, IncrementalBackupFile), `{"Base": "`+full+`"}`)
+	writeTestFile(t, path.Join(incr2, IncrementalBackupFile), `{"Base": "`+incr1+`"}`)
+
+	chain, err := GetBackupChain(incr2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong chain", []string{full, incr1, incr2}, chain)
+
+	writeTestFile(t, path.Join(full, IncrementalBackupFile), `{"Base": "`+incr2+`"}`)
+	_, err = GetBackupChain(incr2)
+	testutils.AssertTrue(t, "loop not detected", err != nil)
+}
+
+func TestGetBackupChainRelative(t *testing.T) {
+	root := t.TempDir()
+	full := path.Join(root, "full")
+	incr1 := path.Join(root, "incr1")
+	incr2 := path.Join(root, "incr2")
+	writeTestFile(t, path.Join(full, "data.json"), "{}")
+	writeTestFile(t, path.Join(incr1, IncrementalBackupFile), `{"Base": "../full"}`)
+	writeTestFile(t, path.Join(incr2, IncrementalBackupFile), `{"Base": "../incr1"}`)
+
+	// The backups can be moved together
+	moved := path.Join(t.TempDir(), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatalf("failed to move the backups: %s", err)
+	}
+
+	chain, err := GetBackupChain(path.Join(moved, "incr2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{path.Join(moved, "full"), path.Join(moved, "incr1"), path.Join(moved, "incr2")}
+	testutils.AssertEquals(t, "wrong chain", expected, chain)
+}
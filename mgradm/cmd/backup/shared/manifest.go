@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ManifestFile is the file describing the content of a backup directory.
+const ManifestFile = "manifest.json"
+
+// ManifestVersion is the version of the manifest format.
+const ManifestVersion = 1
+
+// BackupManifest describes a backup: where it comes from and what it contains.
+type BackupManifest struct {
+	Version        int
+	CreationTime   time.Time
+	Hostname       string
+	Backend        string
+	Incremental    string `json:",omitempty"`
+	OnlineDatabase bool   `json:",omitempty"`
+	Server         ServerManifestData
+	Images         []ImageManifestData
+	Volumes        []VolumeManifestData
+	// Files maps the path of the backup files relative to the backup directory to their sha256 checksum.
+	Files map[string]string
+}
+
+// ServerManifestData are the server details extracted from the server inspect data.
+//
+// The database credentials are not part of it on purpose.
+type ServerManifestData struct {
+	UyuniRelease       string `json:",omitempty"`
+	SuseManagerRelease string `json:",omitempty"`
+	Fqdn               string `json:",omitempty"`
+	PgVersion          string `json:",omitempty"`
+	DBName             string `json:",omitempty"`
+	DBHost             string `json:",omitempty"`
+	DBPort             int    `json:",omitempty"`
+	ReportDBHost       string `json:",omitempty"`
+}
+
+// ImageManifestData describes the image used by a container at backup time.
+type ImageManifestData struct {
+	Container string
+	Image     string
+	Digest    string `json:",omitempty"`
+}
+
+// VolumeManifestData describes a backed up volume.
+type VolumeManifestData struct {
+	Name string
+	// Size is the size of the volume export in bytes.
+	Size int64
+}
+
+// NewBackupManifest creates a manifest with the common values.
+func NewBackupManifest(backend string) *BackupManifest {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to get the hostname")
+	}
+	return &BackupManifest{
+		Version:      ManifestVersion,
+		CreationTime: time.Now().UTC(),
+		Hostname:     hostname,
+		Backend:      backend,
+	}
+}
+
+// SetServerData copies the relevant values of the server inspect data to the manifest.
+func (m *BackupManifest) SetServerData(data *utils.ServerInspectData) {
+	m.Server = ServerManifestData{
+		UyuniRelease:       data.UyuniRelease,
+		SuseManagerRelease: data.SuseManagerRelease,
+		Fqdn:               data.Fqdn,
+		PgVersion:          data.CurrentPgVersion,
+		DBName:             data.DBName,
+		DBHost:             data.DBHost,
+		DBPort:             data.DBPort,
+		ReportDBHost:       data.ReportDBHost,
+	}
+}
+
+// InspectRunningServer runs the server inspector in the running server container.
+func InspectRunningServer(cnx *shared.Connection) (*utils.ServerInspectData, error) {
+	inspector := utils.NewServerInspector("")
+	// We need the inspector to write to the standard output instead of a file
+	inspector.DataPath = "/dev/stdout"
+	script, err := inspector.GenerateScriptString()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := cnx.Exec("sh", "-c", script)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to inspect the running server"))
+	}
+	inspectedData, err := utils.ReadInspectDataString[utils.ServerInspectData](out)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to parse the inspected data"))
+	}
+	return inspectedData, nil
+}
+
+// WriteBackupManifest computes the volumes sizes and files checksums and writes the manifest in the backup directory.
+func WriteBackupManifest(manifest *BackupManifest, outputDirectory string) error {
+	volumesDir := path.Join(outputDirectory, VolumesSubdir)
+	if entries, err := os.ReadDir(volumesDir); err == nil {
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".tar") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			manifest.Volumes = append(manifest.Volumes, VolumeManifestData{
+				Name: strings.TrimSuffix(entry.Name(), ".tar"),
+				Size: info.Size(),
+			})
+		}
+	}
+
+	files, err := listBackupFiles(outputDirectory)
+	if err != nil {
+		return err
+	}
+	manifest.Files = map[string]string{}
+	for _, file := range files {
+		// Reuse the checksums computed during the backup to avoid reading big files twice
+		checksum, err := readChecksumFile(path.Join(outputDirectory, file))
+		if err != nil {
+			checksum, err = computeChecksum(path.Join(outputDirectory, file))
+		}
+		if err != nil {
+			return err
+		}
+		manifest.Files[file] = checksum
+	}
+
+	content, err := manifest.Marshal()
+	if err != nil {
+		return err
+	}
+	manifestPath := path.Join(outputDirectory, ManifestFile)
+	if err := os.WriteFile(manifestPath, content, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write %s"), manifestPath)
+	}
+	return nil
+}
+
+// Marshal serializes the manifest to JSON.
+func (m *BackupManifest) Marshal() ([]byte, error) {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to serialize the manifest"))
+	}
+	return content, nil
+}
+
+// ReadBackupManifest loads the manifest of a backup directory.
+func ReadBackupManifest(backupDir string) (*BackupManifest, error) {
+	manifestPath := path.Join(backupDir, ManifestFile)
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %s"), manifestPath)
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse %s"), manifestPath)
+	}
+	return &manifest, nil
+}
+
+// VerifyBackup checks that all the files of the manifest are in the backup directory and have the expected checksum.
+//
+// The returned slice contains one message per problem.
+func VerifyBackup(backupDir string, manifest *BackupManifest) ([]string, error) {
+	problems := []string{}
+
+	if manifest.Version > ManifestVersion {
+		log.Warn().Msgf(L("The backup manifest version %d is newer than the supported one"), manifest.Version)
+	}
+
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filePath := path.Join(backupDir, name)
+		if !utils.FileExists(filePath) {
+			problems = append(problems, fmt.Sprintf(L("%s is missing"), name))
+			continue
+		}
+		log.Info().Msgf(L("Verifying %s"), name)
+		checksum, err := computeChecksum(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != manifest.Files[name] {
+			problems = append(problems, fmt.Sprintf(L("checksum of %s does not match"), name))
+		}
+	}
+
+	files, err := listBackupFiles(backupDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if _, ok := manifest.Files[file]; !ok {
+			log.Warn().Msgf(L("%s is not part of the backup manifest"), file)
+		}
+	}
+
+	if manifest.Incremental != "" {
+		if _, err := GetBackupChain(backupDir); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems, nil
+}
+
+// listBackupFiles lists the files of a backup directory except the checksums and the manifest.
+func listBackupFiles(backupDir string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(backupDir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(backupDir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == ManifestFile || strings.HasSuffix(relPath, ".sha256sum") {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+func readChecksumFile(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath + ".sha256sum")
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", errors.New(L("empty checksum file"))
+	}
+	return fields[0], nil
+}
+
+// computeChecksum uses the sha256sum tool like utils.CreateChecksum.
+func computeChecksum(filePath string) (string, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "sha256sum", filePath)
+	if err != nil {
+		return "", utils.Errorf(err, L("Failed to calculate checksum of the file %s"), filePath)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", fmt.Errorf(L("Failed to calculate checksum of the file %s"), filePath)
+	}
+	return fields[0], nil
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestVerifyBackup(t *testing.T) {
+	backupDir := t.TempDir()
+	writeTestFile(t, path.Join(backupDir, VolumesSubdir, "var-spacewalk.tar"), "volume content")
+	writeTestFile(t, path.Join(backupDir, VolumesSubdir, "etc-rhn.tar"), "config")
+	writeTestFile(t, path.Join(backupDir, PodmanConfBackupFile), "podman")
+
+	manifest := NewBackupManifest("podman")
+	if err := WriteBackupManifest(manifest, backupDir); err != nil {
+		t.Fatalf("unexpected error writing the manifest: %s", err)
+	}
+
+	read, err := ReadBackupManifest(backupDir)
+	if err != nil {
+		t.Fatalf("unexpected error reading the manifest: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong volumes count", 2, len(read.Volumes))
+	testutils.AssertEquals(t, "wrong volume size", int64(len("config")), read.Volumes[0].Size)
+	testutils.AssertEquals(t, "wrong files count", 3, len(read.Files))
+
+	problems, err := VerifyBackup(backupDir, read)
+	if err != nil {
+		t.Fatalf("unexpected verify error: %s", err)
+	}
+	testutils.AssertEquals(t, "unexpected problems", []string{}, problems)
+
+	writeTestFile(t, path.Join(backupDir, VolumesSubdir, "var-spacewalk.tar"), "corrupted")
+	if err := os.Remove(path.Join(backupDir, PodmanConfBackupFile)); err != nil {
+		t.Fatalf("failed to remove file: %s", err)
+	}
+	problems, err = VerifyBackup(backupDir, read)
+	if err != nil {
+		t.Fatalf("unexpected verify error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong problems", []string{
+		PodmanConfBackupFile + " is missing",
+		"checksum of " + VolumesSubdir + "/var-spacewalk.tar does not match",
+	}, problems)
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// BackupService is the name of the systemd service and timer running the scheduled backups.
+const BackupService = "uyuni-backup"
+
+// ScheduledBackupTimeFormat is the format of the scheduled backups directory names.
+//
+// It has to match the date command in the backup service template.
+const ScheduledBackupTimeFormat = "20060102-150405"
+
+// ScheduledBackup is a timestamped backup in the scheduled backups directory.
+type ScheduledBackup struct {
+	Name string
+	Time time.Time
+	// Complete is true if the backup has a manifest: it is written last.
+	Complete bool
+}
+
+// ListScheduledBackups returns the timestamped backups of a directory, newest first.
+//
+// The entries without a timestamp name are ignored: they are not managed by the schedule.
+func ListScheduledBackups(baseDirectory string) ([]ScheduledBackup, error) {
+	entries, err := os.ReadDir(baseDirectory)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read %s"), baseDirectory)
+	}
+
+	backups := []ScheduledBackup{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		backupTime, err := time.ParseInLocation(ScheduledBackupTimeFormat, entry.Name(), time.Local)
+		if err != nil {
+			log.Debug().Msgf("Ignoring %s: not a scheduled backup", entry.Name())
+			continue
+		}
+		backups = append(backups, ScheduledBackup{
+			Name:     entry.Name(),
+			Time:     backupTime,
+			Complete: utils.FileExists(path.Join(baseDirectory, entry.Name(), ManifestFile)),
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// ExpiredBackups returns the backups to remove to keep the newest backup of the last keepDaily days
+// and of the last keepWeekly weeks.
+//
+// The backups need to be sorted newest first. The newest complete backup is always kept and
+// incomplete backups are only kept if there is no newer complete one.
+func ExpiredBackups(backups []ScheduledBackup, keepDaily int, keepWeekly int) []ScheduledBackup {
+	days := map[string]bool{}
+	weeks := map[string]bool{}
+	hasComplete := false
+
+	expired := []ScheduledBackup{}
+	for _, backup := range backups {
+		if !backup.Complete {
+			// A failed backup can still be investigated until a newer one succeeds
+			if hasComplete {
+				expired = append(expired, backup)
+			}
+			continue
+		}
+
+		keep := !hasComplete
+		hasComplete = true
+
+		day := backup.Time.Format("2006-01-02")
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			keep = true
+		}
+
+		year, weekNumber := backup.Time.ISOWeek()
+		week := fmt.Sprintf("%d-%d", year, weekNumber)
+		if !weeks[week] && len(weeks) < keepWeekly {
+			weeks[week] = true
+			keep = true
+		}
+
+		if !keep {
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
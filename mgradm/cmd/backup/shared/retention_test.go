@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestListScheduledBackups(t *testing.T) {
+	baseDir := t.TempDir()
+	for _, name := range []string{"20250310-020000", "20250312-020000", "manual", "20250311-020000"} {
+		if err := os.Mkdir(path.Join(baseDir, name), 0700); err != nil {
+			t.Fatalf("failed to create folder: %s", err)
+		}
+	}
+	writeTestFile(t, path.Join(baseDir, "20250311-020000", ManifestFile), "{}")
+
+	backups, err := ListScheduledBackups(baseDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	names := []string{}
+	for _, backup := range backups {
+		names = append(names, backup.Name)
+	}
+	testutils.AssertEquals(t, "wrong backups", []string{"20250312-020000", "20250311-020000", "20250310-020000"}, names)
+	testutils.AssertTrue(t, "backup with manifest should be complete", backups[1].Complete)
+	testutils.AssertTrue(t, "backup without manifest should be incomplete", !backups[0].Complete)
+}
+
+func TestExpiredBackups(t *testing.T) {
+	// Two backups a day on 2025-03-01 (Saturday) to 2025-03-20 (Thursday)
+	backups := []ScheduledBackup{}
+	for day := 20; day >= 1; day-- {
+		for _, hour := range []int{14, 2} {
+			backupTime := time.Date(2025, time.March, day, hour, 0, 0, 0, time.Local)
+			backups = append(backups, ScheduledBackup{
+				Name:     backupTime.Format(ScheduledBackupTimeFormat),
+				Time:     backupTime,
+				Complete: true,
+			})
+		}
+	}
+	// The latest backup failed
+	backups[0].Complete = false
+
+	expired := ExpiredBackups(backups, 3, 2)
+	expiredNames := map[string]bool{}
+	for _, backup := range expired {
+		expiredNames[backup.Name] = true
+	}
+
+	kept := []string{}
+	for _, backup := range backups {
+		if !expiredNames[backup.Name] {
+			kept = append(kept, backup.Name)
+		}
+	}
+	testutils.AssertEquals(t, "wrong kept backups", []string{
+		"20250320-140000", "20250320-020000", "20250319-140000", "20250318-140000", "20250316-140000",
+	}, kept)
+}
+
+func TestExpiredBackupsKeepsLatest(t *testing.T) {
+	backupTime := time.Date(2025, time.March, 20, 2, 0, 0, 0, time.Local)
+	backups := []ScheduledBackup{
+		{Name: "latest", Time: backupTime, Complete: true},
+		{Name: "old", Time: backupTime.Add(-time.Hour), Complete: true},
+	}
+	expired := ExpiredBackups(backups, 0, 0)
+	testutils.AssertEquals(t, "wrong expired backups", []ScheduledBackup{backups[1]}, expired)
+}
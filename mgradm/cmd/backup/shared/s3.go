@@ -0,0 +1,479 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// S3Scheme is the prefix of the S3 backup targets.
+const S3Scheme = "s3://"
+
+const (
+	s3DefaultRegion = "us-east-1"
+	// s3MinPartSize is the smallest size of the multipart upload parts, S3 requires at least 5MiB.
+	s3MinPartSize = 16 * 1024 * 1024
+	// s3MaxParts is the maximum number of parts of a multipart upload, with some margin.
+	s3MaxParts   = 9000
+	s3EmptyHash  = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3TimeFormat = "20060102T150405Z"
+	s3DateFormat = "20060102"
+)
+
+// S3SecretKeyEnv is the environment variable holding the S3 secret key if not in the configuration file.
+//
+// The secret key has no flag to avoid exposing it in the processes list and shell history.
+const S3SecretKeyEnv = "AWS_SECRET_ACCESS_KEY"
+
+// S3Flags are the connection parameters to the S3-compatible object storage.
+type S3Flags struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	AccessKey string `mapstructure:"accesskey"`
+	SecretKey string `mapstructure:"secretkey"`
+	// StagingDir is the folder where the objects are downloaded to be verified before restoring them.
+	StagingDir string `mapstructure:"stagingdir"`
+}
+
+// IsS3URL returns whether the backup target is an S3 bucket.
+func IsS3URL(target string) bool {
+	return strings.HasPrefix(target, S3Scheme)
+}
+
+// S3Client uploads and downloads the objects of a backup stored in an S3 bucket.
+//
+// All the object names are relative to the prefix of the backup URL.
+type S3Client struct {
+	endpoint  *url.URL
+	region    string
+	accessKey string
+	secretKey string
+	bucket    string
+	prefix    string
+	client    *http.Client
+}
+
+// NewS3Client parses an s3://bucket/prefix URL and prepares a client for it.
+func NewS3Client(target string, flags *S3Flags) (*S3Client, error) {
+	if !IsS3URL(target) {
+		return nil, fmt.Errorf(L("%s is not an S3 URL"), target)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(target, S3Scheme), "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf(L("no bucket in S3 URL %s"), target)
+	}
+
+	if flags.AccessKey == "" {
+		return nil, errors.New(L("S3 access key is required"))
+	}
+	secretKey := flags.SecretKey
+	if secretKey == "" {
+		secretKey = os.Getenv(S3SecretKeyEnv)
+	}
+	if secretKey == "" {
+		return nil, fmt.Errorf(L("S3 secret key is required, set it in the %s environment variable"), S3SecretKeyEnv)
+	}
+
+	region := flags.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	endpoint := flags.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || endpointURL.Host == "" {
+		return nil, fmt.Errorf(L("invalid S3 endpoint %s"), endpoint)
+	}
+
+	prefix := ""
+	if len(parts) == 2 {
+		prefix = strings.Trim(parts[1], "/")
+	}
+
+	return &S3Client{
+		endpoint:  endpointURL,
+		region:    region,
+		accessKey: flags.AccessKey,
+		secretKey: secretKey,
+		bucket:    parts[0],
+		prefix:    prefix,
+		client:    &http.Client{},
+	}, nil
+}
+
+// String returns the URL of the backup.
+func (c *S3Client) String() string {
+	return S3Scheme + joinS3Key(c.bucket, c.prefix)
+}
+
+// key computes the full object key from a name relative to the backup prefix.
+func (c *S3Client) key(name string) string {
+	return joinS3Key(c.prefix, name)
+}
+
+func joinS3Key(parts ...string) string {
+	nonEmpty := []string{}
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "/")
+}
+
+// List returns the names of the objects of the backup, relative to its prefix.
+func (c *S3Client) List() ([]string, error) {
+	prefix := ""
+	if c.prefix != "" {
+		prefix = c.prefix + "/"
+	}
+
+	names := []string{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		body, err := c.request(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, utils.Errorf(err, L("failed to parse the list of objects"))
+		}
+		for _, object := range result.Contents {
+			names = append(names, strings.TrimPrefix(object.Key, prefix))
+		}
+		if !result.IsTruncated {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// PutBytes uploads a small object.
+func (c *S3Client) PutBytes(name string, content []byte) error {
+	_, err := c.request(http.MethodPut, c.key(name), nil, content)
+	if err != nil {
+		return utils.Errorf(err, L("failed to upload %s"), name)
+	}
+	return nil
+}
+
+// Upload streams the reader content to an object using a multipart upload.
+//
+// Only one part is kept in memory at a time. sizeHint is the expected size of the data,
+// used to compute a part size keeping the number of parts under the S3 limit.
+// The size and sha256 checksum of the uploaded data are returned.
+func (c *S3Client) Upload(name string, reader io.Reader, sizeHint int64) (int64, string, error) {
+	partSize := int64(s3MinPartSize)
+	if sizeHint/s3MaxParts > partSize {
+		partSize = sizeHint / s3MaxParts
+	}
+
+	hasher := sha256.New()
+	reader = io.TeeReader(reader, hasher)
+	buffer := make([]byte, partSize)
+
+	var size int64
+	uploadID := ""
+	parts := []s3CompletedPart{}
+	for {
+		read, readErr := io.ReadFull(reader, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			c.abortUpload(name, uploadID)
+			return size, "", utils.Errorf(readErr, L("failed to read the data to upload to %s"), name)
+		}
+		size += int64(read)
+		last := readErr != nil
+
+		// Small objects do not need a multipart upload
+		if last && uploadID == "" {
+			if err := c.PutBytes(name, buffer[:read]); err != nil {
+				return size, "", err
+			}
+			break
+		}
+
+		if uploadID == "" {
+			var err error
+			if uploadID, err = c.createUpload(name); err != nil {
+				return size, "", err
+			}
+		}
+
+		if read > 0 {
+			partNumber := len(parts) + 1
+			etag, err := c.uploadPart(name, uploadID, partNumber, buffer[:read])
+			if err != nil {
+				c.abortUpload(name, uploadID)
+				return size, "", err
+			}
+			parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: etag})
+		}
+
+		if last {
+			if err := c.completeUpload(name, uploadID, parts); err != nil {
+				c.abortUpload(name, uploadID)
+				return size, "", err
+			}
+			break
+		}
+	}
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Download returns a reader on the content of an object and its size, -1 if unknown.
+// The caller has to close the reader.
+func (c *S3Client) Download(name string) (io.ReadCloser, int64, error) {
+	req, err := c.newRequest(http.MethodGet, c.key(name), nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, utils.Errorf(err, L("failed to download %s"), name)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, 0, utils.Errorf(readS3Error(resp), L("failed to download %s"), name)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+type s3CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+func (c *S3Client) createUpload(name string) (string, error) {
+	body, err := c.request(http.MethodPost, c.key(name), url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to start the upload of %s"), name)
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf(L("failed to start the upload of %s"), name)
+	}
+	return result.UploadID, nil
+}
+
+func (c *S3Client) uploadPart(name string, uploadID string, partNumber int, content []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	req, err := c.newRequest(http.MethodPut, c.key(name), query, content)
+	if err != nil {
+		return "", err
+	}
+	log.Debug().Msgf("Uploading part %d of %s", partNumber, name)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to upload part %[1]d of %[2]s"), partNumber, name)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", utils.Errorf(readS3Error(resp), L("failed to upload part %[1]d of %[2]s"), partNumber, name)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *S3Client) completeUpload(name string, uploadID string, parts []s3CompletedPart) error {
+	content, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	body, err := c.request(http.MethodPost, c.key(name), url.Values{"uploadId": {uploadID}}, content)
+	if err == nil {
+		// The upload can still fail with a successful status code
+		err = parseS3Error(body)
+	}
+	if err != nil {
+		return utils.Errorf(err, L("failed to complete the upload of %s"), name)
+	}
+	return nil
+}
+
+// abortUpload cleans the uploaded parts of a failed upload.
+func (c *S3Client) abortUpload(name string, uploadID string) {
+	if uploadID == "" {
+		return
+	}
+	if _, err := c.request(http.MethodDelete, c.key(name), url.Values{"uploadId": {uploadID}}, nil); err != nil {
+		log.Warn().Err(err).Msgf(L("Failed to abort the upload of %s"), name)
+	}
+}
+
+// request runs a request with a small body and returns the response body.
+func (c *S3Client) request(method string, key string, query url.Values, content []byte) ([]byte, error) {
+	req, err := c.newRequest(method, key, query, content)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, readS3Error(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// newRequest prepares a request signed with AWS signature version 4.
+func (c *S3Client) newRequest(method string, key string, query url.Values, content []byte) (*http.Request, error) {
+	requestURL := *c.endpoint
+	// Path style addressing works with all the S3-compatible storages
+	requestURL.Path = "/" + joinS3Key(c.bucket, key)
+	requestURL.RawPath = "/" + s3Escape(joinS3Key(c.bucket, key), true)
+	requestURL.RawQuery = canonicalS3Query(query)
+
+	var body io.Reader
+	payloadHash := s3EmptyHash
+	if content != nil {
+		body = bytes.NewReader(content)
+		hash := sha256.Sum256(content)
+		payloadHash = hex.EncodeToString(hash[:])
+	}
+	req, err := http.NewRequest(method, requestURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if content != nil {
+		req.ContentLength = int64(len(content))
+	}
+	c.sign(req, payloadHash, time.Now().UTC())
+	return req, nil
+}
+
+// sign adds the AWS signature version 4 authorization headers to the request.
+func (c *S3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format(s3TimeFormat)
+	shortDate := now.Format(s3DateFormat)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.URL.Host, payloadHash, amzDate,
+	)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", shortDate, c.region)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + c.secretKey)
+	for _, value := range []string{shortDate, c.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, value)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// canonicalS3Query encodes the query parameters sorted by name as required by the signature.
+func canonicalS3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, s3Escape(key, false)+"="+s3Escape(value, false))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// s3Escape percent-encodes all the characters except the unreserved ones.
+func s3Escape(value string, keepSlash bool) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' ||
+			b == '-' || b == '_' || b == '.' || b == '~' || b == '/' && keepSlash {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func readS3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	if err := parseS3Error(body); err != nil {
+		return err
+	}
+	return fmt.Errorf(L("S3 request failed with status %s"), resp.Status)
+}
+
+// parseS3Error returns the error described in an S3 response body, if any.
+func parseS3Error(body []byte) error {
+	var s3Error struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if err := xml.Unmarshal(body, &s3Error); err != nil || s3Error.XMLName.Local != "Error" {
+		return nil
+	}
+	return fmt.Errorf(L("S3 request failed: %[1]s %[2]s"), s3Error.Code, s3Error.Message)
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// NormalizeVolumePath returns a path relative to the volume root without leading slash or dot.
+func NormalizeVolumePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// MatchesPaths returns whether the volume path is one of the paths or inside one of them.
+//
+// All the paths match if none is given.
+func MatchesPaths(name string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	name = NormalizeVolumePath(name)
+	for _, selected := range paths {
+		if selected == "" || name == selected || strings.HasPrefix(name, selected+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractVolumeEntries extracts the files of a volume export matching the paths into the destination folder.
+//
+// The existing files are overwritten. The returned map tells which of the paths matched at least one entry.
+func ExtractVolumeEntries(tarballPath string, destination string, paths []string) (map[string]bool, error) {
+	matched := map[string]bool{}
+
+	file, err := os.Open(tarballPath)
+	if err != nil {
+		return matched, utils.Errorf(err, L("failed to open %s"), tarballPath)
+	}
+	defer file.Close()
+
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return matched, utils.Errorf(err, L("failed to read %s"), tarballPath)
+		}
+
+		name := NormalizeVolumePath(header.Name)
+		if name == "" || !MatchesPaths(name, paths) {
+			continue
+		}
+		for _, selected := range paths {
+			if MatchesPaths(name, []string{selected}) {
+				matched[selected] = true
+			}
+		}
+
+		if err := extractEntry(header, name, reader, destination); err != nil {
+			return matched, utils.Errorf(err, L("failed to extract %s"), name)
+		}
+	}
+	return matched, nil
+}
+
+func extractEntry(header *tar.Header, name string, reader io.Reader, destination string) error {
+	target, err := utils.JoinInRoot(destination, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	log.Debug().Msgf("Extracting %s", target)
+
+	mode := header.FileInfo().Mode().Perm()
+	switch header.Typeflag {
+	case tar.TypeDir:
+		// A link replaced by a directory in the archive must not be followed
+		if err := utils.RemoveSymlink(target); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, mode); err != nil {
+			return err
+		}
+		if err := os.Chmod(target, mode); err != nil {
+			return err
+		}
+	case tar.TypeReg:
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, reader)
+		out.Close()
+		if err != nil {
+			return err
+		}
+	case tar.TypeSymlink:
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		if err := os.Symlink(header.Linkname, target); err != nil {
+			return err
+		}
+	case tar.TypeLink:
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		linkTarget, err := utils.JoinInRoot(destination, NormalizeVolumePath(header.Linkname))
+		if err != nil {
+			return err
+		}
+		if err := os.Link(linkTarget, target); err != nil {
+			return err
+		}
+	default:
+		log.Debug().Msgf("Skipping special file %s", name)
+		return nil
+	}
+
+	// Restore the owner when running as root, the files may belong to a user of the container
+	if err := os.Lchown(target, header.Uid, header.Gid); err != nil && !errors.Is(err, os.ErrPermission) {
+		return err
+	}
+	if header.Typeflag != tar.TypeSymlink {
+		return os.Chtimes(target, header.ModTime, header.ModTime)
+	}
+	return nil
+}
+
+// removeExisting removes a file replaced by an extracted entry.
+func removeExisting(target string) error {
+	info, err := os.Lstat(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf(L("%s is a directory"), target)
+	}
+	return os.Remove(target)
+}
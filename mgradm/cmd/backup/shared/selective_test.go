@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func TestMatchesPaths(t *testing.T) {
+	paths := []string{"salt/top.sls", "pillar"}
+	testutils.AssertTrue(t, "exact path should match", MatchesPaths("./salt/top.sls", paths))
+	testutils.AssertTrue(t, "file in folder should match", MatchesPaths("pillar/data/file.sls", paths))
+	testutils.AssertTrue(t, "similar prefix should not match", !MatchesPaths("pillar2/file.sls", paths))
+	testutils.AssertTrue(t, "all paths should match without selection", MatchesPaths("anything", []string{}))
+	testutils.AssertEquals(t, "wrong normalized path", "etc/rhn", NormalizeVolumePath("/etc/../etc/rhn/"))
+}
+
+func TestExtractVolumeEntries(t *testing.T) {
+	tarballPath := path.Join(t.TempDir(), "srv-pillar.tar")
+	file, err := os.Create(tarballPath)
+	if err != nil {
+		t.Fatalf("failed to create tarball: %s", err)
+	}
+	tw := tar.NewWriter(file)
+	for _, entry := range []struct {
+		name     string
+		content  string
+		typeflag byte
+	}{
+		{"./", "", tar.TypeDir},
+		{"./data/", "", tar.TypeDir},
+		{"./data/minion.sls", "minion: data", tar.TypeReg},
+		{"./data/other.sls", "other: data", tar.TypeReg},
+		{"./top.sls", "base: {}", tar.TypeReg},
+		{"./../escape", "evil", tar.TypeReg},
+	} {
+		header := tar.Header{Name: entry.name, Mode: 0640, Size: int64(len(entry.content)), Typeflag: entry.typeflag}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0750
+		}
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatalf("failed to write header: %s", err)
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("failed to write content: %s", err)
+		}
+	}
+	tw.Close()
+	file.Close()
+
+	destination := t.TempDir()
+	writeTestFile(t, path.Join(destination, "data/minion.sls"), "minion: broken")
+
+	matched, err := ExtractVolumeEntries(tarballPath, destination, []string{"data/minion.sls", "missing"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong matched paths", map[string]bool{"data/minion.sls": true}, matched)
+	testutils.AssertEquals(t, "file not restored", "minion: data",
+		string(utils.ReadFile(path.Join(destination, "data/minion.sls"))),
+	)
+	testutils.AssertTrue(t, "unselected file extracted", !utils.FileExists(path.Join(destination, "data/other.sls")))
+	testutils.AssertTrue(t, "unselected file extracted", !utils.FileExists(path.Join(destination, "top.sls")))
+
+	// Without selection the whole volume is extracted and paths cannot escape the destination
+	destination = path.Join(t.TempDir(), "volume")
+	if _, err := ExtractVolumeEntries(tarballPath, destination, []string{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertTrue(t, "file not extracted", utils.FileExists(path.Join(destination, "top.sls")))
+	testutils.AssertTrue(t, "file extracted outside", !utils.FileExists(path.Join(path.Dir(destination), "escape")))
+	testutils.AssertTrue(t, "escaping file not contained", utils.FileExists(path.Join(destination, "escape")))
+}
+
+func TestExtractVolumeEntriesSymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	writeTestFile(t, path.Join(outside, "passwd"), "root:x:0:0")
+
+	link := tar.Header{Name: "a", Linkname: outside, Typeflag: tar.TypeSymlink, Mode: 0777}
+	data := map[string][]tar.Header{
+		"symlink then child": {link, {Name: "a/passwd", Size: 4, Typeflag: tar.TypeReg, Mode: 0644}},
+		"symlink then dir":   {link, {Name: "a/sub/", Typeflag: tar.TypeDir, Mode: 0755}},
+		"hard link":          {link, {Name: "passwd", Linkname: "a/passwd", Typeflag: tar.TypeLink, Mode: 0644}},
+	}
+
+	for testCase, headers := range data {
+		tarballPath := path.Join(t.TempDir(), "volume.tar")
+		file, err := os.Create(tarballPath)
+		if err != nil {
+			t.Fatalf("failed to create tarball: %s", err)
+		}
+		tw := tar.NewWriter(file)
+		for _, header := range headers {
+			if err := tw.WriteHeader(&header); err != nil {
+				t.Fatalf("failed to write header: %s", err)
+			}
+			if header.Size > 0 {
+				if _, err := tw.Write([]byte("evil")); err != nil {
+					t.Fatalf("failed to write content: %s", err)
+				}
+			}
+		}
+		tw.Close()
+		file.Close()
+
+		destination := t.TempDir()
+		if _, err := ExtractVolumeEntries(tarballPath, destination, []string{}); err == nil {
+			t.Errorf("%s: writing through a symbolic link should fail", testCase)
+		}
+		testutils.AssertEquals(t, testCase+": file outside the volume changed", "root:x:0:0",
+			string(utils.ReadFile(path.Join(outside, "passwd"))),
+		)
+		testutils.AssertTrue(t, testCase+": directory created outside the volume",
+			!utils.FileExists(path.Join(outside, "sub")),
+		)
+	}
+}
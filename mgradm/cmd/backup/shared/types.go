@@ -4,18 +4,48 @@
 
 package shared
 
+import (
+	"encoding/json"
+
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
 type Flagpole struct {
-	SkipVolumes  []string `mapstructure:"skipvolumes"`
-	ExtraVolumes []string `mapstructure:"extravolumes"`
-	SkipDatabase bool     `mapstructure:"skipdatabase"`
-	SkipImages   bool     `mapstructure:"skipimages"`
-	SkipConfig   bool     `mapstructure:"skipconfig"`
-	NoRestart    bool     `mapstructure:"norestart"`
-	Restart      bool     `mapstructure:"restart"`
-	DryRun       bool     `mapstructure:"dryrun"`
-	ForceRestore bool     `mapstructure:"force"`
-	SkipExisting bool     `mapstructure:"continue"`
-	SkipVerify   bool     `mapstructure:"skipverify"`
+	Backend        string   `mapstructure:"backend"`
+	SkipVolumes    []string `mapstructure:"skipvolumes"`
+	ExtraVolumes   []string `mapstructure:"extravolumes"`
+	SkipDatabase   bool     `mapstructure:"skipdatabase"`
+	SkipImages     bool     `mapstructure:"skipimages"`
+	SkipConfig     bool     `mapstructure:"skipconfig"`
+	NoRestart      bool     `mapstructure:"norestart"`
+	Restart        bool     `mapstructure:"restart"`
+	DryRun         bool     `mapstructure:"dryrun"`
+	ForceRestore   bool     `mapstructure:"force"`
+	SkipExisting   bool     `mapstructure:"continue"`
+	SkipVerify     bool     `mapstructure:"skipverify"`
+	Incremental    string   `mapstructure:"incremental"`
+	OnlineDatabase bool     `mapstructure:"onlinedatabase"`
+	Archive        bool     `mapstructure:"archive"`
+	Compression    string   `mapstructure:"compression"`
+	AgeRecipient   string   `mapstructure:"agerecipient"`
+	GPGRecipient   string   `mapstructure:"gpgrecipient"`
+	AgeIdentity    string   `mapstructure:"ageidentity"`
+	Schedule       string   `mapstructure:"schedule"`
+	KeepDaily      int      `mapstructure:"keepdaily"`
+	KeepWeekly     int      `mapstructure:"keepweekly"`
+	Disable        bool     `mapstructure:"disable"`
+	S3             S3Flags  `mapstructure:"s3"`
+	Volume         string   `mapstructure:"volume"`
+	Paths          []string `mapstructure:"path"`
+	Target         string   `mapstructure:"target"`
+	Workers        int      `mapstructure:"workers"`
+	New            NewFlags `mapstructure:"new"`
+	SSL            types.SSLCertGenerationFlags
+}
+
+// NewFlags holds the values to change on the restored server.
+type NewFlags struct {
+	FQDN string `mapstructure:"fqdn"`
 }
 
 // Backup error indicating if something was already backed up (resp. restored) or not.
@@ -73,3 +103,19 @@ type PodmanNetworkConfigData struct {
 	NetworkInsterface string          `mapstructure:"network_interface"`
 	NetworkDNSServers []string        `mapstructure:"network_dns_servers"`
 }
+
+// KubernetesBackupData contains what is needed to recreate the server objects on a kubernetes cluster.
+type KubernetesBackupData struct {
+	Namespace    string
+	ServerImage  string
+	Objects      json.RawMessage
+	HelmReleases []HelmReleaseData
+}
+
+// HelmReleaseData describes a helm release installed in the server namespace at backup time.
+type HelmReleaseData struct {
+	Name       string
+	Chart      string
+	AppVersion string
+	Values     json.RawMessage
+}
@@ -20,29 +20,36 @@ const PodmanConfBackupFile = "podmanBackup.tar"
 const SystemdConfBackupFile = "systemdBackup.tar"
 const NetworkOutputFile = "uyuniNetwork.json"
 const SecretBackupFile = "secrets.json"
+const KubernetesBackupFile = "kubernetesBackup.json"
 
 const VolumesSubdir = "volumes"
 const ImagesSubdir = "images"
 
-func StorageCheck(volumes []string, images []string, outputDirectory string) error {
+// StorageCheck verifies there is enough space on the output device to backup the volumes and images.
+//
+// If baseDirectory is set, only the changes of the volumes since that backup are counted.
+// Returns the expected export size of each volume and image.
+func StorageCheck(
+	volumes []string,
+	images []string,
+	outputDirectory string,
+	baseDirectory string,
+) (map[string]int64, error) {
 	// check disk space availability based on volume work list and container image list
-	var outStat unix.Statfs_t
-	if err := unix.Statfs(outputDirectory, &outStat); err != nil {
-		log.Warn().Err(err).Msgf(L("unable to determine target %s storage size"), outputDirectory)
-	}
-	freeSpace := outStat.Bavail * uint64(outStat.Bsize)
 	var spaceRequired int64
+	sizes := map[string]int64{}
 
 	// calculate required space
 	for _, volume := range volumes {
 		mountPoint, err := podman.GetVolumeMountPoint(volume)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		volumeSize, err := dirSize(mountPoint)
+		volumeSize, err := volumeBackupSize(volume, mountPoint, baseDirectory)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		sizes[volume] = volumeSize
 		spaceRequired += volumeSize
 	}
 
@@ -52,11 +59,32 @@ func StorageCheck(volumes []string, images []string, outputDirectory string) err
 		// but that can't be bad to have more disk than actually needed.
 		size, err := podman.GetImageVirtualSize(image)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		sizes[image] = size
 		spaceRequired += size
 	}
 
+	return sizes, CheckFreeSpace(outputDirectory, spaceRequired)
+}
+
+// VolumeSize returns the size of the files of a volume.
+func VolumeSize(volume string) (int64, error) {
+	mountPoint, err := podman.GetVolumeMountPoint(volume)
+	if err != nil {
+		return 0, err
+	}
+	return dirSize(mountPoint)
+}
+
+// CheckFreeSpace returns an error if the outputDirectory device has less than spaceRequired bytes available.
+func CheckFreeSpace(outputDirectory string, spaceRequired int64) error {
+	var outStat unix.Statfs_t
+	if err := unix.Statfs(outputDirectory, &outStat); err != nil {
+		log.Warn().Err(err).Msgf(L("unable to determine target %s storage size"), outputDirectory)
+	}
+	freeSpace := outStat.Bavail * uint64(outStat.Bsize)
+
 	if freeSpace < uint64(spaceRequired) {
 		return errors.New(L("insufficient space on target device"))
 	}
@@ -71,6 +99,31 @@ func SanityChecks() error {
 	return nil
 }
 
+// KubernetesSanityChecks checks the tools needed to backup or restore on kubernetes are available.
+func KubernetesSanityChecks() error {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return errors.New(L("install kubectl before running this command"))
+	}
+
+	return nil
+}
+
+func volumeBackupSize(volume string, mountPoint string, baseDirectory string) (int64, error) {
+	if baseDirectory == "" {
+		return dirSize(mountPoint)
+	}
+	base, err := ReadBaseManifest(baseDirectory, volume)
+	if err != nil {
+		return 0, err
+	}
+	// Don't hash the files here: they are hashed when exporting the delta
+	delta, err := EstimateDelta(base, mountPoint)
+	if err != nil {
+		return 0, err
+	}
+	return delta.Size(), nil
+}
+
 func dirSize(path string) (int64, error) {
 	var size int64
 	err := filepath.WalkDir(path, func(_ string, entry os.DirEntry, err error) error {
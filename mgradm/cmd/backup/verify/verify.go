@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/mgradm/cmd/backup/shared"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// Verify checks the completeness and the checksums of a backup directory.
+func Verify(
+	_ *types.GlobalFlags,
+	_ *shared.Flagpole,
+	_ *cobra.Command,
+	args []string,
+) error {
+	backupDir := args[0]
+	if shared.IsArchive(backupDir) {
+		return errors.New(L("verifying a backup archive is not supported"))
+	}
+	if !utils.FileExists(backupDir) {
+		return fmt.Errorf(L("backup directory %s does not exist"), backupDir)
+	}
+
+	var problems []string
+	if utils.FileExists(path.Join(backupDir, shared.ManifestFile)) {
+		manifest, err := shared.ReadBackupManifest(backupDir)
+		if err != nil {
+			return err
+		}
+		problems, err = shared.VerifyBackup(backupDir, manifest)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Warn().Msg(L("No backup manifest found, only the files with a checksum will be verified"))
+		var err error
+		problems, err = verifyChecksums(backupDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf(L("backup %[1]s is not valid:\n  %[2]s"), backupDir, strings.Join(problems, "\n  "))
+	}
+	log.Info().Msgf(L("Backup %s is valid"), backupDir)
+	return nil
+}
+
+// verifyChecksums validates all the files having a checksum file in the backup directory.
+func verifyChecksums(backupDir string) ([]string, error) {
+	problems := []string{}
+	err := filepath.WalkDir(backupDir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(filePath, ".sha256sum") {
+			return err
+		}
+		file := strings.TrimSuffix(filePath, ".sha256sum")
+		if !utils.FileExists(file) {
+			problems = append(problems, fmt.Sprintf(L("%s is missing"), file))
+			return nil
+		}
+		log.Info().Msgf(L("Verifying %s"), file)
+		if err := utils.ValidateChecksum(file); err != nil {
+			problems = append(problems, err.Error())
+		}
+		return nil
+	})
+	return problems, err
+}
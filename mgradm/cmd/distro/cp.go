@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
 	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/kickstart"
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/podman"
@@ -47,15 +48,7 @@ func registerDistro(connection *api.ConnectionDetails, distro *types.Distributio
 		return utils.Errorf(err, L("unable to login and register the distribution. Manual distro registration is required"))
 	}
 
-	data := map[string]interface{}{
-		"treeLabel":    distro.TreeLabel,
-		"basePath":     distro.BasePath,
-		"channelLabel": distro.ChannelLabel,
-		"installType":  distro.InstallType,
-	}
-
-	_, err = client.Post("kickstart/tree/create", data)
-	if err != nil {
+	if err := kickstart.CreateTree(client, distro); err != nil {
 		return utils.Errorf(err, L("unable to register the distribution. Manual distro registration is required"))
 	}
 	log.Info().Msgf(L("Distribution %s successfully registered"), distro.TreeLabel)
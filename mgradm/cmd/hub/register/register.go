@@ -6,12 +6,14 @@ package register
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
 	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
 	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/podman"
@@ -89,8 +91,24 @@ func getRhnConfig(cnx *shared.Connection) (map[string]string, error) {
 	return config, nil
 }
 
+// defaultReportDBPort is the port of the report database when not set in the configuration.
+const defaultReportDBPort = 5432
+
+// reportDBPort returns the report database port from the configuration or the default one if not set.
+func reportDBPort(config map[string]string) (int, error) {
+	value := config["report_db_port"]
+	if value == "" {
+		return defaultReportDBPort, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, utils.Errorf(err, L("invalid report_db_port value: %s"), value)
+	}
+	return port, nil
+}
+
 func registerToHub(config map[string]string, cnxDetails *api.ConnectionDetails) error {
-	keys := []string{"java.hostname", "report_db_name", "report_db_port", "report_db_user", "report_db_password"}
+	keys := []string{"java.hostname", "report_db_name", "report_db_user", "report_db_password"}
 	for _, key := range keys {
 		if _, ok := config[key]; !ok {
 			return fmt.Errorf(L("mandatory %s entry missing in config"), key)
@@ -105,34 +123,26 @@ func registerToHub(config map[string]string, cnxDetails *api.ConnectionDetails)
 		return utils.Errorf(err, L("failed to connect to the Hub server"))
 	}
 
-	data := map[string]interface{}{
-		"fqdn": config["java.hostname"],
-	}
-
-	ret, err := api.Post[int](client, "system/registerPeripheralServer", data)
+	port, err := reportDBPort(config)
 	if err != nil {
-		return utils.Errorf(err, L("failed to register this peripheral server"))
-	}
-	if !ret.Success {
-		return fmt.Errorf(L("failed to register this peripheral server: %s"), ret.Message)
-	}
-	id := ret.Result
-
-	data = map[string]interface{}{
-		"sid":              id,
-		"reportDbName":     config["report_db_name"],
-		"reportDbHost":     config["java.hostname"],
-		"reportDbPort":     config["report_db_port"],
-		"reportDbUser":     config["report_db_user"],
-		"reportDbPassword": config["report_db_password"],
+		return err
 	}
-	ret, err = api.Post[int](client, "system/updatePeripheralServerInfo", data)
+
+	id, err := system.RegisterPeripheralServer(client, config["java.hostname"])
 	if err != nil {
-		return utils.Errorf(err, L("failed to update peripheral server info"))
+		return err
 	}
 
-	if !ret.Success {
-		return fmt.Errorf(L("failed to update peripheral server info: %s"), ret.Message)
+	info := system.PeripheralServerInfo{
+		ID:               id,
+		ReportDBName:     config["report_db_name"],
+		ReportDBHost:     config["java.hostname"],
+		ReportDBPort:     port,
+		ReportDBUser:     config["report_db_user"],
+		ReportDBPassword: config["report_db_password"],
+	}
+	if err := system.UpdatePeripheralServerInfo(client, info); err != nil {
+		return err
 	}
 	log.Info().Msgf(L("Registered peripheral server: %[1]s, ID: %[2]d"), config["java.hostname"], id)
 	return nil
@@ -41,3 +41,16 @@ func TestParamsParsing(t *testing.T) {
 		t.Errorf("command failed with error: %s", err)
 	}
 }
+
+func TestReportDBPort(t *testing.T) {
+	port, err := reportDBPort(map[string]string{})
+	testutils.AssertEquals(t, "Unexpected error for missing port", nil, err)
+	testutils.AssertEquals(t, "Wrong default port", 5432, port)
+
+	port, err = reportDBPort(map[string]string{"report_db_port": "5433"})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong port", 5433, port)
+
+	_, err = reportDBPort(map[string]string{"report_db_port": "foo"})
+	testutils.AssertTrue(t, "Missing error for invalid port", err != nil)
+}
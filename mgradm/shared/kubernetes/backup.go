@@ -0,0 +1,372 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/mgradm/shared/templates"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// BackupJobName is the name of the job giving access to the volumes during backup and restore.
+const BackupJobName = "uyuni-backup"
+
+// backupMountRoot is the folder of the backup job where the volumes are mounted.
+const backupMountRoot = "/backup"
+
+// backupObjectKinds are the kinds of the server objects to store in a backup.
+const backupObjectKinds = "deployment,service,configmap,secret,ingress,persistentvolumeclaim"
+
+// StartVolumesAccessJob starts a job mounting the volumes claims and waits for its pod to be running.
+//
+// The volumes are mounted in the pod in a folder named after them and the job runs until deleted.
+// If node is not empty, the pod will be scheduled on it: this is needed to reach ReadWriteOnce volumes
+// already mounted by a running pod.
+// The job and pod names are returned.
+func StartVolumesAccessJob(
+	namespace string,
+	image string,
+	pullPolicy string,
+	pullSecret string,
+	node string,
+	volumes []string,
+) (string, string, error) {
+	mounts := []types.VolumeMount{}
+	for _, volume := range volumes {
+		mounts = append(mounts, types.VolumeMount{Name: volume, MountPath: path.Join(backupMountRoot, volume)})
+	}
+
+	scriptData := templates.VolumesAccessScriptTemplateData{MountRoot: backupMountRoot}
+	job, err := kubernetes.GetScriptJob(namespace, BackupJobName, image, pullPolicy, pullSecret, mounts, scriptData)
+	if err != nil {
+		return "", "", err
+	}
+	if node != "" {
+		job.Spec.Template.Spec.NodeName = node
+	}
+
+	jobName := job.ObjectMeta.Name
+	if err := kubernetes.Apply([]runtime.Object{job}, L("failed to run the volumes access job")); err != nil {
+		return jobName, "", err
+	}
+
+	jobFilter := "-ljob-name=" + jobName
+	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "kubectl", "wait", "-n", namespace,
+		"--for=condition=Ready", "pod", jobFilter, "--timeout=300s",
+	); err != nil {
+		return jobName, "", utils.Errorf(err, L("%s job pod failed to start"), jobName)
+	}
+
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "pod", "-n", namespace, jobFilter,
+		"-o", "jsonpath={.items[0].metadata.name}",
+	)
+	if err != nil {
+		return jobName, "", utils.Errorf(err, L("failed to find the pod of job %s"), jobName)
+	}
+	return jobName, strings.TrimSpace(string(out)), nil
+}
+
+// DeleteJob removes a job and its pods.
+func DeleteJob(namespace string, name string) error {
+	if _, err := utils.RunCmdOutput(zerolog.DebugLevel,
+		"kubectl", "delete", "job", "-n", namespace, name, "--ignore-not-found",
+	); err != nil {
+		return utils.Errorf(err, L("failed to delete job %s"), name)
+	}
+	return nil
+}
+
+// GetVolumeSize returns the size in bytes of the content of a volume mounted in the volumes access pod.
+func GetVolumeSize(namespace string, pod string, volume string) (int64, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "exec", "-n", namespace, pod, "--",
+		"du", "-sb", path.Join(backupMountRoot, volume),
+	)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to compute the size of volume %s"), volume)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf(L("invalid size for volume %s"), volume)
+	}
+	return strconv.ParseInt(fields[0], 10, 64)
+}
+
+// ExportVolume streams the content of a volume mounted in the volumes access pod into a tarball.
+//
+// The tarball is named after the volume in the existing outputDir folder and has a checksum file.
+// If dryRun is set to true, only messages will be logged to explain what would happen.
+func ExportVolume(namespace string, pod string, volume string, outputDir string, dryRun bool) error {
+	outputFile := path.Join(outputDir, volume+".tar")
+	exportCommand := []string{"kubectl", "exec", "-n", namespace, pod, "--",
+		"tar", "--numeric-owner", "-C", path.Join(backupMountRoot, volume), "-cf", "-", ".",
+	}
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), strings.Join(exportCommand, " "))
+		return nil
+	}
+
+	out, err := os.Create(outputFile)
+	if err != nil {
+		return utils.Errorf(err, L("failed to create %s"), outputFile)
+	}
+	defer out.Close()
+
+	log.Info().Msgf(L("Run %s"), strings.Join(exportCommand, " "))
+	if _, err := utils.NewRunner(exportCommand[0], exportCommand[1:]...).
+		Log(zerolog.DebugLevel).Stdout(out).Exec(); err != nil {
+		return utils.Errorf(err, L("Failed to export volume %s"), volume)
+	}
+	if err := utils.CreateChecksum(outputFile); err != nil {
+		return utils.Errorf(err, L("Failed to write checksum of volume %[1]s to the %[2]s"),
+			volume, outputFile+".sha256sum")
+	}
+	return nil
+}
+
+// ImportVolume streams a volume tarball into the volume mounted in the volumes access pod.
+// If dryRun is set to true, only messages will be logged to explain what would happen.
+func ImportVolume(
+	namespace string,
+	pod string,
+	volume string,
+	volumePath string,
+	skipVerify bool,
+	dryRun bool,
+) error {
+	importCommand := []string{"kubectl", "exec", "-i", "-n", namespace, pod, "--",
+		"tar", "--numeric-owner", "-C", path.Join(backupMountRoot, volume), "-xpf", "-",
+	}
+	if dryRun {
+		log.Info().Msgf(L("Would run %s"), strings.Join(importCommand, " "))
+		return nil
+	}
+	if !skipVerify {
+		if err := utils.ValidateChecksum(volumePath); err != nil {
+			return utils.Errorf(err, L("Checksum does not match for volume %s"), volumePath)
+		}
+	}
+
+	in, err := os.Open(volumePath)
+	if err != nil {
+		return utils.Errorf(err, L("failed to open %s"), volumePath)
+	}
+	defer in.Close()
+
+	log.Info().Msgf(L("Run %s"), strings.Join(importCommand, " "))
+	if _, err := utils.NewRunner(importCommand[0], importCommand[1:]...).
+		Log(zerolog.DebugLevel).Stdin(in).Exec(); err != nil {
+		return utils.Errorf(err, L("Failed to import volume %s"), volume)
+	}
+	return nil
+}
+
+// GetDeploymentsReplicas returns the number of replicas of all the server deployments, indexed by name.
+func GetDeploymentsReplicas(namespace string) (map[string]int, error) {
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "deploy", "-n", namespace,
+		kubernetes.ServerFilter, "-o", `jsonpath={range .items[*]}{.metadata.name},{.spec.replicas}{"\n"}{end}`,
+	)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the server deployments"))
+	}
+
+	replicas := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, ",", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, utils.Errorf(err, L("invalid replicas count for deployment %s"), parts[0])
+		}
+		replicas[parts[0]] = count
+	}
+	return replicas, nil
+}
+
+// ScaleDeployments sets the replicas of the deployments to the values of the map.
+func ScaleDeployments(namespace string, replicas map[string]int) error {
+	var hasError error
+	for name, count := range replicas {
+		hasError = utils.JoinErrors(hasError, kubernetes.ReplicasTo(namespace, name, uint(count)))
+	}
+	return hasError
+}
+
+// GetBackupObjects returns the server objects to store in a backup as a JSON kubernetes List.
+//
+// This contains the server deployments, services, configuration, secrets, claims and the helm releases records.
+// The objects are cleaned up of the runtime data to be applied again on restore.
+func GetBackupObjects(namespace string) ([]byte, error) {
+	items := []map[string]interface{}{}
+
+	// Helm releases records are not labeled as part of the server, get them separately
+	for _, args := range [][]string{
+		{backupObjectKinds, kubernetes.ServerFilter},
+		{"secret", "-lowner=helm"},
+	} {
+		out, err := utils.RunCmdOutput(zerolog.DebugLevel, "kubectl", "get", "-n", namespace,
+			args[0], args[1], "-o", "json",
+		)
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to get the %s kubernetes objects"), args[0])
+		}
+		var list struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		if err := json.Unmarshal(out, &list); err != nil {
+			return nil, utils.Errorf(err, L("failed to parse the kubernetes objects"))
+		}
+		items = append(items, list.Items...)
+	}
+
+	return SanitizeBackupObjects(items)
+}
+
+// SanitizeBackupObjects removes the data set by the cluster from the objects and returns a JSON List of them.
+//
+// Without this, the objects couldn't be created again on a cluster, for instance because of
+// the resource version or the bound volume name.
+func SanitizeBackupObjects(items []map[string]interface{}) ([]byte, error) {
+	for _, item := range items {
+		delete(item, "status")
+		if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+			for _, field := range []string{
+				"uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "selfLink", "ownerReferences",
+			} {
+				delete(metadata, field)
+			}
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				for key := range annotations {
+					if key == "kubectl.kubernetes.io/last-applied-configuration" ||
+						strings.HasPrefix(key, "pv.kubernetes.io/") ||
+						strings.HasPrefix(key, "volume.kubernetes.io/") ||
+						strings.HasPrefix(key, "deployment.kubernetes.io/") {
+						delete(annotations, key)
+					}
+				}
+			}
+		}
+
+		spec, ok := item["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch item["kind"] {
+		case "PersistentVolumeClaim":
+			// The volume will be provisioned again
+			delete(spec, "volumeName")
+		case "Service":
+			// The cluster will allocate new IPs
+			delete(spec, "clusterIP")
+			delete(spec, "clusterIPs")
+		}
+	}
+
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	}
+	return json.MarshalIndent(list, "", "  ")
+}
+
+// ApplyBackupObjects applies the objects of a JSON List matching the kinds filter.
+//
+// If kinds is empty, all objects are applied.
+// The deployments replicas are set to 0: the server will be started later if needed.
+func ApplyBackupObjects(objects []byte, kinds []string) error {
+	var list map[string]interface{}
+	if err := json.Unmarshal(objects, &list); err != nil {
+		return utils.Errorf(err, L("failed to parse the kubernetes objects"))
+	}
+	items, _ := list["items"].([]interface{})
+
+	filtered := []interface{}{}
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := object["kind"].(string)
+		if len(kinds) > 0 && !utils.Contains(kinds, kind) {
+			continue
+		}
+		if spec, ok := object["spec"].(map[string]interface{}); ok && kind == "Deployment" {
+			spec["replicas"] = 0
+		}
+		filtered = append(filtered, object)
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	list["items"] = filtered
+
+	tempDir, cleaner, err := utils.TempDir()
+	if err != nil {
+		return err
+	}
+	defer cleaner()
+
+	definitionPath := path.Join(tempDir, "objects.json")
+	data, err := json.Marshal(list)
+	if err != nil {
+		return utils.Errorf(err, L("failed to serialize the kubernetes objects"))
+	}
+	if err := os.WriteFile(definitionPath, data, 0600); err != nil {
+		return utils.Errorf(err, L("failed to write %s"), definitionPath)
+	}
+
+	if err := utils.RunCmdStdMapping(zerolog.DebugLevel, "kubectl", "apply", "-f", definitionPath); err != nil {
+		return utils.Errorf(err, L("failed to restore the kubernetes objects"))
+	}
+	return nil
+}
+
+// GetBackupObjectsReplicas returns the number of replicas of the deployments in a JSON List, indexed by name.
+func GetBackupObjectsReplicas(objects []byte) (map[string]int, error) {
+	var list struct {
+		Items []struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Replicas *int `json:"replicas"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(objects, &list); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse the kubernetes objects"))
+	}
+
+	replicas := map[string]int{}
+	for _, item := range list.Items {
+		if item.Kind != "Deployment" {
+			continue
+		}
+		// Kubernetes defaults to one replica when not set
+		count := 1
+		if item.Spec.Replicas != nil {
+			count = *item.Spec.Replicas
+		}
+		replicas[item.Metadata.Name] = count
+	}
+	return replicas, nil
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nok8s
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestSanitizeBackupObjects(t *testing.T) {
+	input := `[
+{"kind": "PersistentVolumeClaim", "metadata": {"name": "var-pgsql", "namespace": "uyuni", "uid": "123",
+	"resourceVersion": "42", "annotations": {"pv.kubernetes.io/bind-completed": "yes", "keep": "me"}},
+	"spec": {"volumeName": "pvc-123", "storageClassName": "local"}, "status": {"phase": "Bound"}},
+{"kind": "Service", "metadata": {"name": "db", "namespace": "uyuni"},
+	"spec": {"clusterIP": "10.0.0.1", "clusterIPs": ["10.0.0.1"], "ports": []}}
+]`
+	var items []map[string]interface{}
+	if err := json.Unmarshal([]byte(input), &items); err != nil {
+		t.Fatalf("failed to parse test data: %s", err)
+	}
+
+	out, err := SanitizeBackupObjects(items)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var list struct {
+		Kind  string                   `json:"kind"`
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		t.Fatalf("failed to parse result: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong list kind", "List", list.Kind)
+	testutils.AssertEquals(t, "wrong items count", 2, len(list.Items))
+
+	pvc := list.Items[0]
+	_, hasStatus := pvc["status"]
+	testutils.AssertTrue(t, "status not removed", !hasStatus)
+	metadata := pvc["metadata"].(map[string]interface{})
+	_, hasUID := metadata["uid"]
+	testutils.AssertTrue(t, "uid not removed", !hasUID)
+	_, hasVersion := metadata["resourceVersion"]
+	testutils.AssertTrue(t, "resourceVersion not removed", !hasVersion)
+	annotations := metadata["annotations"].(map[string]interface{})
+	testutils.AssertEquals(t, "wrong annotations", map[string]interface{}{"keep": "me"}, annotations)
+	spec := pvc["spec"].(map[string]interface{})
+	_, hasVolumeName := spec["volumeName"]
+	testutils.AssertTrue(t, "volumeName not removed", !hasVolumeName)
+	testutils.AssertEquals(t, "storage class should be kept", "local", spec["storageClassName"].(string))
+
+	serviceSpec := list.Items[1]["spec"].(map[string]interface{})
+	_, hasClusterIP := serviceSpec["clusterIP"]
+	testutils.AssertTrue(t, "clusterIP not removed", !hasClusterIP)
+	_, hasClusterIPs := serviceSpec["clusterIPs"]
+	testutils.AssertTrue(t, "clusterIPs not removed", !hasClusterIPs)
+}
+
+func TestGetBackupObjectsReplicas(t *testing.T) {
+	objects := `{"kind": "List", "items": [
+{"kind": "Deployment", "metadata": {"name": "uyuni"}, "spec": {"replicas": 1}},
+{"kind": "Deployment", "metadata": {"name": "hub-api"}, "spec": {"replicas": 0}},
+{"kind": "Deployment", "metadata": {"name": "db"}, "spec": {}},
+{"kind": "Service", "metadata": {"name": "web"}, "spec": {}}
+]}`
+	replicas, err := GetBackupObjectsReplicas([]byte(objects))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "wrong replicas", map[string]int{"uyuni": 1, "hub-api": 0, "db": 1}, replicas)
+}
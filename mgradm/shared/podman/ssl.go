@@ -299,6 +299,71 @@ func generateServerCertificate(image string, sslFlags *adm_utils.InstallSSLFlags
 	)
 }
 
+// RegenerateServerCertificate generates a new server certificate for the FQDN using the existing CA.
+//
+// The CA key is read from the root volume and the server certificate and key secrets are replaced.
+func RegenerateServerCertificate(image string, sslFlags *types.SSLCertGenerationFlags, tz string, fqdn string) error {
+	tempDir, cleaner, err := utils.TempDir()
+	defer cleaner()
+	if err != nil {
+		return err
+	}
+
+	env := map[string]string{
+		"CERT_EMAIL":  sslFlags.Email,
+		"CERT_CNAMES": strings.Join(append([]string{fqdn}, sslFlags.Cnames...), " "),
+		"CERT_PASS":   sslFlags.Password,
+		"HOSTNAME":    fqdn,
+	}
+	if err := runSSLContainer(sslRegenerateServerScript, tempDir, image, tz, env); err != nil {
+		return utils.Error(err, L("Server SSL certificates generation failed"))
+	}
+
+	log.Info().Msg(L("Server SSL certificates generated"))
+
+	// The existing secrets would not be overwritten
+	shared_podman.DeleteSecret(shared_podman.SSLCertSecret, false)
+	shared_podman.DeleteSecret(shared_podman.SSLKeySecret, false)
+	return shared_podman.CreateTLSSecrets(
+		shared_podman.CASecret, path.Join(tempDir, "ca.crt"),
+		shared_podman.SSLCertSecret, path.Join(tempDir, "server.crt"),
+		shared_podman.SSLKeySecret, path.Join(tempDir, "server.key"),
+	)
+}
+
+// RegenerateDatabaseCertificate generates a new report database certificate for the FQDN using the existing CA.
+//
+// The CA key is read from the root volume and the database certificate and key secrets are replaced.
+func RegenerateDatabaseCertificate(
+	image string, sslFlags *types.SSLCertGenerationFlags, tz string, fqdn string,
+) error {
+	tempDir, cleaner, err := utils.TempDir()
+	defer cleaner()
+	if err != nil {
+		return err
+	}
+
+	env := map[string]string{
+		"CERT_EMAIL":  sslFlags.Email,
+		"CERT_CNAMES": strings.Join(append([]string{fqdn}, sslFlags.Cnames...), " "),
+		"CERT_PASS":   sslFlags.Password,
+	}
+	if err := runSSLContainer(sslRegenerateDatabaseScript, tempDir, image, tz, env); err != nil {
+		return utils.Error(err, L("Database SSL certificates generation failed"))
+	}
+
+	log.Info().Msg(L("Database SSL certificates generated"))
+
+	// The existing secrets would not be overwritten
+	shared_podman.DeleteSecret(shared_podman.DBSSLCertSecret, false)
+	shared_podman.DeleteSecret(shared_podman.DBSSLKeySecret, false)
+	return shared_podman.CreateTLSSecrets(
+		shared_podman.DBCASecret, path.Join(tempDir, "ca.crt"),
+		shared_podman.DBSSLCertSecret, path.Join(tempDir, "reportdb.crt"),
+		shared_podman.DBSSLKeySecret, path.Join(tempDir, "reportdb.key"),
+	)
+}
+
 func generateDatabaseCertificate(image string, sslFlags *adm_utils.InstallSSLFlags, tz string, fqdn string) error {
 	// Write the ordered cert and Root CA to temp files
 	tempDir, cleaner, err := utils.TempDir()
@@ -336,7 +401,7 @@ func generateDatabaseCertificate(image string, sslFlags *adm_utils.InstallSSLFla
 	return nil
 }
 
-const sslSetupServerScript = `
+const sslMachineNameFunction = `
 	getMachineName() {
 	  hostname="$1"
 
@@ -355,7 +420,9 @@ const sslSetupServerScript = `
 
 	  echo "$result"
 	}
+`
 
+const sslSetupServerScript = sslMachineNameFunction + `
 	echo "Generating the self-signed SSL CA..."
 	mkdir -p /root/ssl-build
 	rhn-ssl-tool --gen-ca --no-rpm --force --dir /root/ssl-build \
@@ -383,6 +450,32 @@ const sslSetupServerScript = `
 	cp "/root/ssl-build/$MACHINE_NAME/server.key" /ssl/server.key
 `
 
+// This is assuming the CA and its key are in the ssl-build folder of the root volume.
+const sslRegenerateServerScript = sslMachineNameFunction + `
+	if [ ! -f /root/ssl-build/RHN-ORG-PRIVATE-SSL-KEY ]; then
+		echo "No CA key in /root/ssl-build: the server certificate needs to be provided."
+		exit 1
+	fi
+
+	echo "Generate apache certificate..."
+	cert_args=""
+	for CERT_CNAME in $CERT_CNAMES; do
+		cert_args="$cert_args --set-cname $CERT_CNAME"
+	done
+	if [ -n "$CERT_EMAIL" ]; then
+		cert_args="$cert_args --set-email $CERT_EMAIL"
+	fi
+
+	rhn-ssl-tool --gen-server --no-rpm --cert-expiration 3650 \
+		--dir /root/ssl-build --password "$CERT_PASS" \
+		--set-hostname "$HOSTNAME" $cert_args
+
+	MACHINE_NAME=$(getMachineName "$HOSTNAME")
+	cp /root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT /ssl/ca.crt
+	cp "/root/ssl-build/$MACHINE_NAME/server.crt" /ssl/server.crt
+	cp "/root/ssl-build/$MACHINE_NAME/server.key" /ssl/server.key
+`
+
 // This is assuming CA cert is generated by server script.
 // If we in any point in the future allow mix of 3rd party server and self signed ca for database
 // this will need to be updated to include check for ca cert and build if needed.
@@ -399,3 +492,28 @@ const sslSetupDatabaseScript = `
 	cp /root/ssl-build/reportdb/server.crt /ssl/reportdb.crt
 	cp /root/ssl-build/reportdb/server.key /ssl/reportdb.key
 `
+
+// This is assuming the CA and its key are in the ssl-build folder of the root volume.
+const sslRegenerateDatabaseScript = `
+	if [ ! -f /root/ssl-build/RHN-ORG-PRIVATE-SSL-KEY ]; then
+		echo "No CA key in /root/ssl-build: the database certificate needs to be provided."
+		exit 1
+	fi
+
+	echo "Generating DB certificate..."
+	cert_args=""
+	for CERT_CNAME in $CERT_CNAMES; do
+		cert_args="$cert_args --set-cname $CERT_CNAME"
+	done
+	if [ -n "$CERT_EMAIL" ]; then
+		cert_args="$cert_args --set-email $CERT_EMAIL"
+	fi
+
+	rhn-ssl-tool --gen-server --no-rpm --cert-expiration 3650 \
+		--dir /root/ssl-build --password "$CERT_PASS" \
+		--set-hostname reportdb.mgr.internal --set-cname reportdb --set-cname db $cert_args
+
+	cp /root/ssl-build/RHN-ORG-TRUSTED-SSL-CERT /ssl/ca.crt
+	cp /root/ssl-build/reportdb/server.crt /ssl/reportdb.crt
+	cp /root/ssl-build/reportdb/server.key /ssl/reportdb.key
+`
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+// The backup directory name is computed at run time, % has to be escaped for systemd.
+// The old backups are only pruned if the backup succeeded.
+const backupServiceTemplate = `
+# {{ .Name }}.service, generated by mgradm
+# Use an {{ .Name }}.service.d/custom.conf file to override
+[Unit]
+Description=Uyuni server scheduled backup
+Wants=network-online.target
+After=network-online.target uyuni-server.service
+
+[Service]
+Type=oneshot
+ExecStart=/bin/sh -c '/usr/bin/mgradm backup create ${UYUNI_BACKUP_ARGS} \
+	"${UYUNI_BACKUP_DIR}/$(date +%%Y%%m%%d-%%H%%M%%S)"'
+ExecStartPost=/bin/sh -c '/usr/bin/mgradm backup prune \
+	--keepdaily ${UYUNI_BACKUP_KEEP_DAILY} \
+	--keepweekly ${UYUNI_BACKUP_KEEP_WEEKLY} \
+	"${UYUNI_BACKUP_DIR}"'
+`
+
+const backupTimerTemplate = `
+# {{ .Name }}.timer, generated by mgradm
+[Unit]
+Description=Uyuni server scheduled backup timer
+
+[Timer]
+OnCalendar={{ .Schedule }}
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`
+
+// BackupServiceTemplateData holds information to create the systemd service running the scheduled backups.
+type BackupServiceTemplateData struct {
+	Name string
+}
+
+// Render will create the systemd service file.
+func (data BackupServiceTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("service").Parse(backupServiceTemplate))
+	return t.Execute(wr, data)
+}
+
+// BackupTimerTemplateData holds information to create the systemd timer triggering the scheduled backups.
+type BackupTimerTemplateData struct {
+	Name     string
+	Schedule string
+}
+
+// Render will create the systemd timer file.
+func (data BackupTimerTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("timer").Parse(backupTimerTemplate))
+	return t.Execute(wr, data)
+}
@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+import (
+	"io"
+	"text/template"
+)
+
+const volumesAccessScriptTemplate = `#!/bin/sh
+trap 'exit 0' TERM INT
+echo "Volumes ready in {{ .MountRoot }}"
+while true; do
+	sleep 5
+done`
+
+// VolumesAccessScriptTemplateData represents the data for the script keeping the volumes available for copying.
+type VolumesAccessScriptTemplateData struct {
+	MountRoot string
+}
+
+// Render will create the script keeping the volumes mounted until the job is deleted.
+func (data VolumesAccessScriptTemplateData) Render(wr io.Writer) error {
+	t := template.Must(template.New("script").Parse(volumesAccessScriptTemplate))
+	return t.Execute(wr, data)
+}
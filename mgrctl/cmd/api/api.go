@@ -5,6 +5,9 @@
 package api
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared/api"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
@@ -14,7 +17,11 @@ import (
 
 type apiFlags struct {
 	api.ConnectionDetails `mapstructure:"api"`
-	ForceLogin            bool `mapstructure:"force"`
+	ForceLogin            bool   `mapstructure:"force"`
+	Output                string `mapstructure:"output"`
+	File                  string `mapstructure:"file"`
+	Continue              bool   `mapstructure:"continue"`
+	Refresh               bool   `mapstructure:"refresh"`
 }
 
 // NewCommand generates a JSON over HTTP API helper tool command.
@@ -32,10 +39,15 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 		Long: L(`Takes an API path and optional parameters and then issues GET request with them.
 
 Example:
-# mgrctl api get user/getDetails login=test`),
+# mgrctl api get user/getDetails login=test
+
+Output format example:
+# mgrctl api get system/listSystems --output table
+# mgrctl api get system/listSystems -o 'jsonpath={[*].name}'`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runGet)
 		},
+		ValidArgsFunction: completeAPICall(globalFlags, &flags),
 	}
 
 	apiPost := &cobra.Command{
@@ -55,6 +67,7 @@ JSON example:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runPost)
 		},
+		ValidArgsFunction: completeAPICall(globalFlags, &flags),
 	}
 
 	apiLogin := &cobra.Command{
@@ -63,7 +76,19 @@ JSON example:
 		Long: L(`Login stores login information for next API calls.
 
 User name, password and remote host can be provided using flags or will be asked interactively.
-Environment variables are also supported.`),
+Environment variables are also supported.
+
+The credentials are stored in the context named by the --context flag.
+Without it, the context already used for the server or the current one is used,
+unless the current context is for another server: a context named after the server is then created.
+The stored context becomes the current one.
+
+The session and password are stored in the Secret Service or kernel keyring if available,
+or in a file encrypted with a passphrase read from UYUNI_API_PASSPHRASE environment variable or asked.
+Use --api-credstore plain to store only the session in a plain JSON file.
+
+Example:
+# mgrctl api login --context hub --api-server hub.example.com`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runLogin)
 		},
@@ -79,11 +104,129 @@ Environment variables are also supported.`),
 		},
 	}
 
+	apiUseContext := &cobra.Command{
+		Use:   "use-context name",
+		Short: L("Change the current API context"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runUseContext)
+		},
+	}
+
+	apiGetContexts := &cobra.Command{
+		Use:   "get-contexts",
+		Short: L("List the stored API contexts"),
+		Long:  L("List the contexts stored by the login command."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runGetContexts)
+		},
+	}
+
+	apiRun := &cobra.Command{
+		Use:   "run",
+		Short: L("Run a sequence of API calls"),
+		Long: L(`Run the API calls described in a YAML or JSON file over a single login session.
+
+Each step has a method (get or post, get by default), an API path and parameters.
+The parameter values can use Go templates to refer to the results of the previous named steps.
+A value made of a single template expression rendering a number or boolean is passed with that type.
+
+The execution stops at the first failing call, unless the --continue flag is set.
+The results of all the calls are printed and a failed call results in a non-zero exit code.
+
+Example of steps file:
+steps:
+  - name: org
+    method: post
+    path: org/create
+    params:
+      orgName: example
+      adminLogin: admin
+      adminPassword: secret
+      firstName: Admin
+      lastName: Example
+      email: admin@example.com
+      usePamAuth: false
+  - method: get
+    path: org/getDetails
+    params:
+      orgId: "{{ .org.id }}"
+
+Example:
+# mgrctl api run -f steps.yaml --continue`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runRun)
+		},
+	}
+	apiRun.Flags().StringP("file", "f", "", L("YAML or JSON file describing the API calls to run"))
+	apiRun.Flags().Bool("continue", false, L("Run the next API calls even if one fails"))
+
+	apiList := &cobra.Command{
+		Use:   "list [namespace]",
+		Short: L("List the API namespaces or methods"),
+		Long: L(`List the API namespaces or the methods of a namespace with their parameters.
+
+The API description is queried from the server and cached for a day.
+Parameters names are only shown if the server reports them, their types are shown otherwise.
+
+Example:
+# mgrctl api list system/config`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runList)
+		},
+		ValidArgsFunction: completeAPINamespace(globalFlags, &flags),
+	}
+
+	apiDescribe := &cobra.Command{
+		Use:   "describe path",
+		Short: L("Describe an API method"),
+		Long: L(`Show the parameters, return value and exceptions of all the variants of an API method.
+
+The API description is queried from the server and cached for a day.
+The result is shown as YAML unless the --output flag is set.
+
+Example:
+# mgrctl api describe user/listAssignableRoles`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runDescribe)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeAPICall(globalFlags, &flags)(cmd, args, toComplete)
+		},
+	}
+
+	for _, cmd := range []*cobra.Command{apiList, apiDescribe} {
+		cmd.Flags().Bool("refresh", false, L("Query the API description from the server even if cached"))
+	}
+
 	apiCmd.AddCommand(apiGet)
 	apiCmd.AddCommand(apiPost)
 	apiCmd.AddCommand(apiLogin)
 	apiCmd.AddCommand(apiLogout)
+	apiCmd.AddCommand(apiUseContext)
+	apiCmd.AddCommand(apiGetContexts)
+	apiCmd.AddCommand(apiRun)
+	apiCmd.AddCommand(apiList)
+	apiCmd.AddCommand(apiDescribe)
 	api.AddAPIFlags(apiCmd)
+	utils.AddOutputFlag(apiCmd)
 
 	return apiCmd
 }
+
+// printResponse prints the result of a successful API call in the requested format.
+//
+// A failed call results in an error to get a non-zero exit code.
+func printResponse(format string, path string, res *api.APIResponse[interface{}]) error {
+	if !res.Success {
+		return fmt.Errorf(L("API call %[1]s failed: %[2]s"), path, res.Message)
+	}
+	return utils.PrintOutput(os.Stdout, format, res.Result)
+}
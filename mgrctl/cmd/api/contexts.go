@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func runUseContext(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, args []string) error {
+	log.Debug().Msgf("Running use-context command %s", args[0])
+
+	if err := api.UseContext(&flags.ConnectionDetails, args[0]); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Switched to %s context"), args[0])
+	return nil
+}
+
+func runGetContexts(_ *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, _ []string) error {
+	log.Debug().Msg("Running get-contexts command")
+
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return err
+	}
+
+	contexts, err := api.ListContexts(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+	if len(contexts) == 0 {
+		log.Info().Msg(L("No stored API context"))
+		return nil
+	}
+	return utils.PrintOutput(os.Stdout, format, contexts)
+}
@@ -0,0 +1,325 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/introspection"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// apiDocCacheValidity is the duration after which the API description is fetched again from the server.
+const apiDocCacheValidity = 24 * time.Hour
+
+// apiDoc is the description of the API of a server, cached locally.
+type apiDoc struct {
+	Server     string
+	Timestamp  time.Time
+	Namespaces map[string][]introspection.Method
+}
+
+// completionFunc is the signature of the cobra ValidArgsFunction.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// methodDescription is the output of the describe command.
+type methodDescription struct {
+	Path       string                    `json:"path"`
+	Parameters []introspection.Parameter `json:"parameters"`
+	Return     string                    `json:"return,omitempty"`
+	Exceptions []string                  `json:"exceptions,omitempty"`
+}
+
+func runList(_ *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, args []string) error {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return err
+	}
+
+	doc, err := loadAPIDoc(&flags.ConnectionDetails, flags.Refresh, true)
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]string{}
+	if len(args) == 0 {
+		for _, namespace := range doc.namespaces() {
+			rows = append(rows, map[string]string{"namespace": namespace})
+		}
+		return utils.PrintOutput(os.Stdout, format, rows)
+	}
+
+	namespace := strings.Trim(args[0], "/")
+	methods, ok := doc.Namespaces[namespace]
+	if !ok {
+		return fmt.Errorf(L("unknown API namespace: %s"), namespace)
+	}
+	sortMethods(methods)
+	for _, method := range methods {
+		rows = append(rows, map[string]string{
+			"method": namespace + "/" + method.Name,
+			"params": formatParameters(method.GetParameters()),
+		})
+	}
+	return utils.PrintOutput(os.Stdout, format, rows)
+}
+
+func runDescribe(_ *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, args []string) error {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "yaml")
+	if err != nil {
+		return err
+	}
+
+	doc, err := loadAPIDoc(&flags.ConnectionDetails, flags.Refresh, true)
+	if err != nil {
+		return err
+	}
+
+	methods := doc.findMethods(args[0])
+	if len(methods) == 0 {
+		return fmt.Errorf(L("unknown API method: %s"), args[0])
+	}
+	descriptions := []methodDescription{}
+	for _, method := range methods {
+		descriptions = append(descriptions, methodDescription{
+			Path:       strings.Trim(args[0], "/"),
+			Parameters: method.GetParameters(),
+			Return:     method.Return,
+			Exceptions: method.Exceptions,
+		})
+	}
+	return utils.PrintOutput(os.Stdout, format, descriptions)
+}
+
+// completeAPICall returns the completion function for the API path and parameters arguments.
+func completeAPICall(globalFlags *types.GlobalFlags, flags *apiFlags) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var doc *apiDoc
+		err := utils.CommandHelper(globalFlags, cmd, args, flags, nil,
+			func(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, _ []string) error {
+				var err error
+				doc, err = loadAPIDoc(&flags.ConnectionDetails, false, false)
+				return err
+			},
+		)
+		if err != nil {
+			log.Debug().Err(err).Msg("Cannot get the API description for completion")
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) == 0 {
+			return doc.completePath(toComplete)
+		}
+		return doc.completeParameters(args[0], args[1:], toComplete)
+	}
+}
+
+// completeAPINamespace returns the completion function for the namespace argument of the list command.
+func completeAPINamespace(globalFlags *types.GlobalFlags, flags *apiFlags) completionFunc {
+	completeCall := completeAPICall(globalFlags, flags)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		candidates, directive := completeCall(cmd, args, toComplete)
+		namespaces := []string{}
+		for _, candidate := range candidates {
+			if strings.HasSuffix(candidate, "/") {
+				namespaces = append(namespaces, candidate)
+			}
+		}
+		return namespaces, directive
+	}
+}
+
+// loadAPIDoc returns the description of the server API, from the cache if recent enough.
+//
+// If interactive is false, the user is never prompted: the stored credentials are only read
+// if the passphrase of the encrypted file is in the environment and the API is only queried
+// if a session is stored.
+func loadAPIDoc(conn *api.ConnectionDetails, refresh bool, interactive bool) (*apiDoc, error) {
+	conn.NonInteractive = !interactive
+	client, err := api.Init(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !refresh {
+		if doc, err := readAPIDocCache(client.Details.Server); err == nil {
+			return doc, nil
+		}
+	}
+
+	if !interactive && !client.Details.InSession && client.Details.Password == "" {
+		return nil, errors.New(L("not logged in"))
+	}
+	if err := client.Login(); err != nil {
+		return nil, utils.Errorf(err, L("unable to login to the server"))
+	}
+	namespaces, err := introspection.ListCalls(client)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &apiDoc{Server: client.Details.Server, Timestamp: time.Now(), Namespaces: namespaces}
+	if err := writeAPIDocCache(doc); err != nil {
+		log.Warn().Err(err).Msg(L("failed to cache the API description"))
+	}
+	return doc, nil
+}
+
+func getAPIDocCacheFile(server string) string {
+	return path.Join(utils.GetUserCacheDir(), "uyuni-tools", fmt.Sprintf("api-%s.json", server))
+}
+
+// readAPIDocCache reads the cached API description of a server, returning an error if missing or too old.
+func readAPIDocCache(server string) (*apiDoc, error) {
+	data, err := os.ReadFile(getAPIDocCacheFile(server))
+	if err != nil {
+		return nil, err
+	}
+	var doc apiDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if time.Since(doc.Timestamp) > apiDocCacheValidity {
+		// l10n-ignore
+		return nil, errors.New("outdated API description cache")
+	}
+	return &doc, nil
+}
+
+func writeAPIDocCache(doc *apiDoc) error {
+	cacheFile := getAPIDocCacheFile(doc.Server)
+	if err := os.MkdirAll(path.Dir(cacheFile), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, data, 0600)
+}
+
+// namespaces returns the sorted namespaces of the API.
+func (d *apiDoc) namespaces() []string {
+	namespaces := []string{}
+	for namespace := range d.Namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// findMethods returns all the overloads of a method by its path.
+func (d *apiDoc) findMethods(methodPath string) []introspection.Method {
+	methodPath = strings.Trim(methodPath, "/")
+	index := strings.LastIndex(methodPath, "/")
+	if index < 0 {
+		return nil
+	}
+	methods := []introspection.Method{}
+	for _, method := range d.Namespaces[methodPath[:index]] {
+		if method.Name == methodPath[index+1:] {
+			methods = append(methods, method)
+		}
+	}
+	sortMethods(methods)
+	return methods
+}
+
+// completePath returns the namespaces and method paths starting with toComplete.
+//
+// Only the next level of the namespaces hierarchy is returned, with a trailing slash.
+func (d *apiDoc) completePath(toComplete string) ([]string, cobra.ShellCompDirective) {
+	found := map[string]bool{}
+	for namespace, methods := range d.Namespaces {
+		for _, method := range methods {
+			methodPath := namespace + "/" + method.Name
+			if !strings.HasPrefix(methodPath, toComplete) {
+				continue
+			}
+			if next := strings.Index(methodPath[len(toComplete):], "/"); next >= 0 {
+				methodPath = methodPath[:len(toComplete)+next+1]
+			}
+			found[methodPath] = true
+		}
+	}
+
+	directive := cobra.ShellCompDirectiveNoFileComp
+	candidates := []string{}
+	for candidate := range found {
+		if strings.HasSuffix(candidate, "/") {
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+	return candidates, directive
+}
+
+// completeParameters returns the key= parameters of a method not already set.
+func (d *apiDoc) completeParameters(methodPath string, args []string, toComplete string) (
+	[]string, cobra.ShellCompDirective,
+) {
+	directive := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	if strings.Contains(toComplete, "=") {
+		return nil, directive
+	}
+	given := map[string]bool{}
+	for _, arg := range args {
+		given[strings.SplitN(arg, "=", 2)[0]] = true
+	}
+
+	found := map[string]bool{}
+	for _, method := range d.findMethods(methodPath) {
+		for _, parameter := range method.GetParameters() {
+			if parameter.Name != "" && !given[parameter.Name] && strings.HasPrefix(parameter.Name, toComplete) {
+				found[parameter.Name+"="] = true
+			}
+		}
+	}
+	candidates := []string{}
+	for candidate := range found {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+	return candidates, directive
+}
+
+// sortMethods sorts the methods by name and number of parameters.
+func sortMethods(methods []introspection.Method) {
+	sort.SliceStable(methods, func(i, j int) bool {
+		if methods[i].Name == methods[j].Name {
+			return len(methods[i].Parameters) < len(methods[j].Parameters)
+		}
+		return methods[i].Name < methods[j].Name
+	})
+}
+
+// formatParameters returns the parameter names, or their types if the server does not report the names.
+func formatParameters(parameters []introspection.Parameter) string {
+	names := []string{}
+	for _, parameter := range parameters {
+		if parameter.Name != "" {
+			names = append(names, parameter.Name)
+		} else {
+			names = append(names, parameter.Type)
+		}
+	}
+	return strings.Join(names, ", ")
+}
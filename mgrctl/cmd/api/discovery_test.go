@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api/introspection"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+var testDoc = apiDoc{
+	Server:    "server",
+	Timestamp: time.Now(),
+	Namespaces: map[string][]introspection.Method{
+		"system": {
+			{Name: "listSystems", Parameters: []string{"string sessionKey"}},
+			{Name: "getDetails", Parameters: []string{"string sessionKey", "int sid"}},
+			{
+				Name:       "scheduleReboot",
+				Parameters: []string{"string sessionKey", "int sid", "dateTime.iso8601 earliestOccurrence"},
+			},
+		},
+		"system/config": {
+			{Name: "listFiles", Parameters: []string{"string sessionKey", "int sid", "int listLocal"}},
+		},
+		"user": {
+			{Name: "listAssignableRoles", Parameters: []string{"string"}},
+		},
+	},
+}
+
+func TestCompletePath(t *testing.T) {
+	type testCase struct {
+		toComplete string
+		expected   []string
+	}
+	cases := []testCase{
+		{"", []string{"system/", "user/"}},
+		{"sys", []string{"system/"}},
+		{"system/", []string{"system/config/", "system/getDetails", "system/listSystems", "system/scheduleReboot"}},
+		{"system/con", []string{"system/config/"}},
+		{"user/list", []string{"user/listAssignableRoles"}},
+	}
+
+	for _, test := range cases {
+		candidates, _ := testDoc.completePath(test.toComplete)
+		testutils.AssertEquals(t, "Wrong candidates for "+test.toComplete,
+			strings.Join(test.expected, " "), strings.Join(candidates, " "),
+		)
+	}
+
+	_, directive := testDoc.completePath("sys")
+	testutils.AssertTrue(t, "Namespaces should not be followed by a space", directive&cobra.ShellCompDirectiveNoSpace != 0)
+	_, directive = testDoc.completePath("user/list")
+	testutils.AssertTrue(t, "Methods should be followed by a space", directive&cobra.ShellCompDirectiveNoSpace == 0)
+}
+
+func TestCompleteParameters(t *testing.T) {
+	candidates, _ := testDoc.completeParameters("system/scheduleReboot", []string{}, "")
+	testutils.AssertEquals(t, "Wrong parameters", "earliestOccurrence= sid=", strings.Join(candidates, " "))
+
+	candidates, _ = testDoc.completeParameters("system/scheduleReboot", []string{"sid=1"}, "")
+	testutils.AssertEquals(t, "Set parameter not skipped", "earliestOccurrence=", strings.Join(candidates, " "))
+
+	candidates, _ = testDoc.completeParameters("system/scheduleReboot", []string{}, "sid=")
+	testutils.AssertEquals(t, "Values should not be completed", 0, len(candidates))
+
+	candidates, _ = testDoc.completeParameters("user/listAssignableRoles", []string{}, "")
+	testutils.AssertEquals(t, "Parameters without names should be skipped", 0, len(candidates))
+}
+
+func TestAPIDocCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if err := writeAPIDocCache(&testDoc); err != nil {
+		t.Fatalf("failed to write the cache: %s", err)
+	}
+	doc, err := readAPIDocCache("server")
+	if err != nil {
+		t.Fatalf("failed to read the cache: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cached namespaces", 3, len(doc.Namespaces))
+
+	outdated := testDoc
+	outdated.Timestamp = time.Now().Add(-2 * apiDocCacheValidity)
+	if err := writeAPIDocCache(&outdated); err != nil {
+		t.Fatalf("failed to write the cache: %s", err)
+	}
+	_, err = readAPIDocCache("server")
+	testutils.AssertTrue(t, "Outdated cache should not be used", err != nil)
+}
@@ -5,7 +5,6 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -20,6 +19,9 @@ import (
 
 func runGet(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, args []string) error {
 	log.Debug().Msgf("Running GET command %s", args[0])
+	if err := utils.ValidateOutputFormat(flags.Output); err != nil {
+		return err
+	}
 	client, err := api.Init(&flags.ConnectionDetails)
 	if err == nil && (client.Details.User != "" || client.Details.InSession) {
 		err = client.Login()
@@ -35,13 +37,5 @@ func runGet(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, args []stri
 		return utils.Errorf(err, L("error in query '%s'"), path)
 	}
 
-	// TODO do this only when result is JSON or TEXT. Watchout for binary data
-	// Decode JSON to the string and pretty print it
-	out, err := json.MarshalIndent(res.Result, "", "  ")
-	if err != nil {
-		return err
-	}
-	fmt.Print(string(out))
-
-	return nil
+	return printResponse(flags.Output, path, res)
 }
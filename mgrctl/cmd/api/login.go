@@ -19,11 +19,15 @@ import (
 func runLogin(_ *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, _ []string) error {
 	log.Debug().Msg("Running login command")
 
-	if api.IsAlreadyLoggedIn() && !flags.ForceLogin {
-		return errors.New(L("Refusing to overwrite existing login. Use --force to ignore this check."))
+	if flags.Token != "" {
+		return errors.New(L("API tokens are not stored, pass them to each command instead"))
 	}
 
 	utils.AskIfMissing(&flags.Server, cmd.Flag("api-server").Usage, 0, 0, utils.IsWellFormedFQDN)
+	if api.IsAlreadyLoggedIn(&flags.ConnectionDetails) && !flags.ForceLogin {
+		return errors.New(L("Refusing to overwrite existing login. Use --force to ignore this check."))
+	}
+
 	utils.AskIfMissing(&flags.User, cmd.Flag("api-user").Usage, 0, 0, nil)
 	utils.AskPasswordIfMissingOnce(&flags.Password, cmd.Flag("api-password").Usage, 0, 0)
 
@@ -38,14 +42,14 @@ func runLogin(_ *types.GlobalFlags, flags *apiFlags, cmd *cobra.Command, _ []str
 		return err
 	}
 
-	log.Info().Msg(L("Login credentials verified."))
+	log.Info().Msgf(L("Login credentials verified and stored in %s context."), flags.Context)
 	return nil
 }
 
-func runLogout(_ *types.GlobalFlags, _ *apiFlags, _ *cobra.Command, _ []string) error {
+func runLogout(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, _ []string) error {
 	log.Debug().Msg("Running logout command")
 
-	if err := api.RemoveLoginCreds(); err != nil {
+	if err := api.RemoveLoginCreds(&flags.ConnectionDetails); err != nil {
 		return err
 	}
 	log.Info().Msg(L("Successfully logged out"))
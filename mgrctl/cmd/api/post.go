@@ -6,7 +6,6 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -20,6 +19,9 @@ import (
 
 func runPost(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, args []string) error {
 	log.Debug().Msgf("Running POST command %s", args[0])
+	if err := utils.ValidateOutputFormat(flags.Output); err != nil {
+		return err
+	}
 	client, err := api.Init(&flags.ConnectionDetails)
 	if err == nil {
 		err = client.Login()
@@ -51,14 +53,5 @@ func runPost(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, args []str
 		return utils.Errorf(err, L("error in query '%s'"), path)
 	}
 
-	if !res.Success {
-		log.Error().Msg(res.Message)
-	}
-	out, err := json.MarshalIndent(res.Result, "", "  ")
-	if err != nil {
-		log.Fatal().Err(err)
-	}
-	fmt.Print(string(out))
-
-	return nil
+	return printResponse(flags.Output, path, res)
 }
@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// apiBatch is the content of the file passed to the run command.
+type apiBatch struct {
+	Steps []apiStep `json:"steps"`
+}
+
+// apiStep is one API call of a batch.
+type apiStep struct {
+	Name   string                 `json:"name"`
+	Method string                 `json:"method"`
+	Path   string                 `json:"path"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// stepResult is the outcome of an API call of a batch.
+type stepResult struct {
+	Name    string      `json:"name,omitempty"`
+	Path    string      `json:"path"`
+	Success bool        `json:"success"`
+	Result  interface{} `json:"result,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+func runRun(_ *types.GlobalFlags, flags *apiFlags, _ *cobra.Command, _ []string) error {
+	if err := utils.ValidateOutputFormat(flags.Output); err != nil {
+		return err
+	}
+	if flags.File == "" {
+		return errors.New(L("a steps file is required, use --file to set it"))
+	}
+	content, err := os.ReadFile(flags.File)
+	if err != nil {
+		return utils.Errorf(err, L("failed to read %s"), flags.File)
+	}
+	batch, err := parseBatch(content)
+	if err != nil {
+		return utils.Errorf(err, L("invalid steps file %s"), flags.File)
+	}
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err == nil {
+		err = client.Login()
+	}
+	if err != nil {
+		return utils.Errorf(err, L("unable to login to the server"))
+	}
+
+	results := runSteps(client, batch.Steps, flags.Continue)
+	if err := utils.PrintOutput(os.Stdout, flags.Output, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf(L("%[1]d of %[2]d API calls failed"), failed, len(batch.Steps))
+	}
+	return nil
+}
+
+// parseBatch decodes and validates the YAML or JSON steps of a batch.
+func parseBatch(content []byte) (*apiBatch, error) {
+	var batch apiBatch
+	if err := yaml.UnmarshalStrict(content, &batch); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for i, step := range batch.Steps {
+		if step.Path == "" {
+			return nil, fmt.Errorf(L("step %d has no path"), i+1)
+		}
+		method := strings.ToLower(step.Method)
+		if method == "" {
+			method = "get"
+		}
+		if method != "get" && method != "post" {
+			return nil, fmt.Errorf(L("unsupported method %[1]s in step %[2]d"), step.Method, i+1)
+		}
+		batch.Steps[i].Method = method
+
+		if step.Name != "" {
+			if names[step.Name] {
+				return nil, fmt.Errorf(L("duplicate step name: %s"), step.Name)
+			}
+			names[step.Name] = true
+		}
+	}
+	return &batch, nil
+}
+
+// runSteps calls the API for each step, stopping at the first failure unless keepGoing is set.
+//
+// The results of the named steps are available to the templates in the parameters of the next steps.
+func runSteps(client *api.APIClient, steps []apiStep, keepGoing bool) []stepResult {
+	results := []stepResult{}
+	data := map[string]interface{}{}
+
+	for i, step := range steps {
+		log.Debug().Msgf("Running step %d: %s %s", i+1, step.Method, step.Path)
+		result := stepResult{Name: step.Name, Path: step.Path}
+
+		res, err := runStep(client, step, data)
+		if err != nil {
+			result.Message = err.Error()
+		} else {
+			result.Success = true
+			result.Result = res
+			if step.Name != "" {
+				data[step.Name] = res
+			}
+		}
+		results = append(results, result)
+
+		if !result.Success && !keepGoing {
+			break
+		}
+	}
+	return results
+}
+
+func runStep(client *api.APIClient, step apiStep, data map[string]interface{}) (interface{}, error) {
+	params, err := renderValue(step.Params, data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to render the parameters"))
+	}
+	paramsMap, _ := params.(map[string]interface{})
+
+	var res interface{}
+	if step.Method == "post" {
+		res, err = api.PostResult[interface{}](client, step.Path, paramsMap)
+	} else {
+		query := url.Values{}
+		for key, value := range paramsMap {
+			query.Set(key, formatQueryValue(value))
+		}
+		res, err = api.GetResult[interface{}](client, step.Path, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Keep the IDs as integers for the next templates
+	return utils.NormalizeNumbers(res)
+}
+
+// formatQueryValue converts a parameter to a query string value.
+//
+// The numbers decoded from YAML are floats that would be printed with an exponent, like large IDs.
+func formatQueryValue(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// renderValue executes the templates found in the strings of a parameter value.
+//
+// A string made of a single template expression rendering an integer or boolean results in a value of that
+// type, so IDs from previous steps are passed as numbers.
+func renderValue(value interface{}, data map[string]interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		return renderString(typed, data)
+	case map[string]interface{}:
+		rendered := map[string]interface{}{}
+		for key, item := range typed {
+			renderedItem, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := []interface{}{}
+		for _, item := range typed {
+			renderedItem, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, renderedItem)
+		}
+		return rendered, nil
+	}
+	return value, nil
+}
+
+func renderString(value string, data map[string]interface{}) (interface{}, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	tmpl, err := template.New("param").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	rendered := buf.String()
+
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") && strings.Count(trimmed, "{{") == 1 {
+		if integer, err := strconv.ParseInt(rendered, 10, 64); err == nil {
+			return integer, nil
+		}
+		if rendered == "true" || rendered == "false" {
+			return rendered == "true", nil
+		}
+	}
+	return rendered, nil
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/mocks"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+const testSteps = `
+steps:
+  - name: org
+    method: post
+    path: org/create
+    params:
+      orgName: example
+  - path: org/getDetails
+    params:
+      orgId: "{{ .org.id }}"
+  - path: system/getDetails
+    params:
+      sid: 1000010000
+  - name: user
+    method: POST
+    path: user/create
+    params:
+      login: "admin-{{ .org.name }}"
+      orgId: "{{ .org.id }}"
+`
+
+func TestParseBatch(t *testing.T) {
+	batch, err := parseBatch([]byte(testSteps))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong steps count", 4, len(batch.Steps))
+	testutils.AssertEquals(t, "Wrong default method", "get", batch.Steps[1].Method)
+	testutils.AssertEquals(t, "Method not lowercased", "post", batch.Steps[3].Method)
+
+	_, err = parseBatch([]byte(`{"steps": [{"name": "a", "path": "p"}, {"name": "a", "path": "p"}]}`))
+	testutils.AssertTrue(t, "Duplicate names not detected", err != nil)
+
+	_, err = parseBatch([]byte(`{"steps": [{"method": "delete", "path": "p"}]}`))
+	testutils.AssertTrue(t, "Invalid method not detected", err != nil)
+}
+
+func TestRunSteps(t *testing.T) {
+	batch, err := parseBatch([]byte(testSteps))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var createUserParams map[string]interface{}
+	client := &api.APIClient{
+		Details: &api.ConnectionDetails{Server: "server"},
+		BaseURL: "https://server/rhn/manager/api",
+		Client: &mocks.MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				switch req.URL.Path {
+				case "/rhn/manager/api/org/create":
+					return testutils.GetResponse(200, `{"success": true, "result": {"id": 1000010000, "name": "example"}}`)
+				case "/rhn/manager/api/org/getDetails":
+					testutils.AssertEquals(t, "Wrong query", "orgId=1000010000", req.URL.RawQuery)
+					return testutils.GetResponse(200, `{"success": false, "message": "no such org"}`)
+				case "/rhn/manager/api/system/getDetails":
+					// Large literal numbers are not sent with an exponent
+					testutils.AssertEquals(t, "Wrong literal number query", "sid=1000010000", req.URL.RawQuery)
+					return testutils.GetResponse(200, `{"success": true, "result": {}}`)
+				case "/rhn/manager/api/user/create":
+					body, _ := io.ReadAll(req.Body)
+					if err := json.Unmarshal(body, &createUserParams); err != nil {
+						t.Errorf("invalid body: %s", err)
+					}
+					return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+				}
+				t.Errorf("unexpected call to %s", req.URL.Path)
+				return testutils.GetResponse(404, "{}")
+			},
+		},
+	}
+
+	// Stop on error
+	results := runSteps(client, batch.Steps, false)
+	testutils.AssertEquals(t, "Wrong results count", 2, len(results))
+	testutils.AssertTrue(t, "First call should succeed", results[0].Success)
+	testutils.AssertEquals(t, "Wrong failure message", "no such org", results[1].Message)
+
+	// Continue on error
+	results = runSteps(client, batch.Steps, true)
+	testutils.AssertEquals(t, "Wrong results count", 4, len(results))
+	testutils.AssertTrue(t, "Literal number call should succeed", results[2].Success)
+	testutils.AssertTrue(t, "Last call should succeed", results[3].Success)
+	testutils.AssertEquals(t, "Wrong rendered string", interface{}("admin-example"), createUserParams["login"])
+	// The single template expression results in a number
+	testutils.AssertEquals(t, "Wrong rendered ID", interface{}(float64(1000010000)), createUserParams["orgId"])
+}
+
+func TestRenderMissingKey(t *testing.T) {
+	_, err := renderValue(map[string]interface{}{"id": "{{ .missing.id }}"}, map[string]interface{}{})
+	testutils.AssertTrue(t, "Expected an error for an unknown step", err != nil)
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := testutils.NewFakeAPIServer(t)
+	server.AddOrg("myorg", "admin", "secret")
+
+	stepsFile := path.Join(t.TempDir(), "steps.yaml")
+	steps := `
+steps:
+  - name: org
+    path: org/getDetails
+    params:
+      name: myorg
+  - method: post
+    path: user/create
+    params:
+      login: "user{{ .org.id }}"
+      password: secret
+`
+	if err := os.WriteFile(stepsFile, []byte(steps), 0600); err != nil {
+		t.Fatalf("failed to write the steps file: %s", err)
+	}
+
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs([]string{"run", "-f", stepsFile, "--api-server", server.Host(), "--api-cacert", server.CACertFile(t),
+		"--api-user", "admin", "--api-password", "secret", "--api-credstore", "plain",
+	})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "User not created", "user1", server.Users[1].Login)
+}
@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/activationkey"
+	"github.com/uyuni-project/uyuni-tools/shared/api/systemgroup"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// activationKeySpec is the spec of an ActivationKey resource, named after the key including its organization
+// prefix.
+//
+// The entitlements are only used to create the key.
+// The child channels and system groups are left untouched if not set.
+type activationKeySpec struct {
+	Description      string   `json:"description"`
+	BaseChannel      string   `json:"baseChannel"`
+	UsageLimit       int      `json:"usageLimit"`
+	UniversalDefault bool     `json:"universalDefault"`
+	Disabled         bool     `json:"disabled"`
+	ContactMethod    string   `json:"contactMethod"`
+	Entitlements     []string `json:"entitlements"`
+	ChildChannels    []string `json:"childChannels"`
+	SystemGroups     []string `json:"systemGroups"`
+}
+
+type activationKeyHandler struct{}
+
+func (h *activationKeyHandler) decode(res *resource) (interface{}, error) {
+	spec, err := decodeSpec[activationKeySpec](res)
+	if err != nil {
+		return nil, err
+	}
+	if spec.ContactMethod == "" {
+		spec.ContactMethod = "default"
+	}
+	return spec, nil
+}
+
+func (h *activationKeyHandler) list(client *api.APIClient) ([]string, error) {
+	keys, err := activationkey.ListActivationKeys(client)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, key := range keys {
+		names = append(names, key.Key)
+	}
+	return names, nil
+}
+
+func (h *activationKeyHandler) plan(client *api.APIClient, res *resource, exists bool) (*change, error) {
+	spec := res.spec.(*activationKeySpec)
+	if !exists {
+		details := []string{}
+		diffListField(&details, "childChannels", spec.ChildChannels, nil)
+		diffListField(&details, "systemGroups", spec.SystemGroups, nil)
+		return &change{
+			action:  actionCreate,
+			kind:    res.Kind,
+			name:    res.Name,
+			details: details,
+			run: func() error {
+				return createActivationKey(client, res.Name, spec)
+			},
+		}, nil
+	}
+
+	current, err := activationkey.GetDetails(client, res.Name)
+	if err != nil {
+		return nil, err
+	}
+	currentBaseChannel := current.BaseChannelLabel
+	if currentBaseChannel == "none" {
+		currentBaseChannel = ""
+	}
+
+	details := []string{}
+	diffField(&details, "description", current.Description, spec.Description)
+	diffField(&details, "baseChannel", currentBaseChannel, spec.BaseChannel)
+	diffField(&details, "usageLimit", current.UsageLimit, spec.UsageLimit)
+	diffField(&details, "universalDefault", current.UniversalDefault, spec.UniversalDefault)
+	diffField(&details, "disabled", current.Disabled, spec.Disabled)
+	diffField(&details, "contactMethod", current.ContactMethod, spec.ContactMethod)
+	detailsChanged := len(details) > 0
+
+	var addedChannels, removedChannels []string
+	if spec.ChildChannels != nil {
+		addedChannels, removedChannels = diffLists(current.ChildChannelLabels, spec.ChildChannels)
+		diffListField(&details, "childChannels", addedChannels, removedChannels)
+	}
+
+	var addedGroups, removedGroups []string
+	if spec.SystemGroups != nil {
+		groupNames, err := getSystemGroupNames(client, current.ServerGroupIDs)
+		if err != nil {
+			return nil, err
+		}
+		addedGroups, removedGroups = diffLists(groupNames, spec.SystemGroups)
+		diffListField(&details, "systemGroups", addedGroups, removedGroups)
+	}
+
+	if len(details) == 0 {
+		return nil, nil
+	}
+	return &change{
+		action:  actionUpdate,
+		kind:    res.Kind,
+		name:    res.Name,
+		details: details,
+		run: func() error {
+			if detailsChanged {
+				if err := activationkey.SetDetails(client, res.Name, toUpdateRequest(spec)); err != nil {
+					return err
+				}
+			}
+			if err := updateChildChannels(client, res.Name, addedChannels, removedChannels); err != nil {
+				return err
+			}
+			return updateSystemGroups(client, res.Name, addedGroups, removedGroups)
+		},
+	}, nil
+}
+
+func (h *activationKeyHandler) prune(client *api.APIClient, _ *caller, name string) *change {
+	return &change{
+		action: actionDelete,
+		kind:   "ActivationKey",
+		name:   name,
+		run: func() error {
+			return activationkey.Delete(client, name)
+		},
+	}
+}
+
+func createActivationKey(client *api.APIClient, name string, spec *activationKeySpec) error {
+	key, err := activationkey.Create(client, activationkey.CreateRequest{
+		Key:              name,
+		Description:      spec.Description,
+		BaseChannelLabel: spec.BaseChannel,
+		UsageLimit:       spec.UsageLimit,
+		Entitlements:     spec.Entitlements,
+		UniversalDefault: spec.UniversalDefault,
+	})
+	if err != nil {
+		return err
+	}
+	if key != name {
+		log.Warn().Msgf(L("Activation key %[1]s was created as %[2]s, rename the resource to match"), name, key)
+	}
+
+	if spec.Disabled || spec.ContactMethod != "default" {
+		if err := activationkey.SetDetails(client, key, toUpdateRequest(spec)); err != nil {
+			return err
+		}
+	}
+	if err := updateChildChannels(client, key, spec.ChildChannels, nil); err != nil {
+		return err
+	}
+	return updateSystemGroups(client, key, spec.SystemGroups, nil)
+}
+
+func toUpdateRequest(spec *activationKeySpec) activationkey.UpdateRequest {
+	return activationkey.UpdateRequest{
+		Description:      spec.Description,
+		BaseChannelLabel: spec.BaseChannel,
+		UsageLimit:       spec.UsageLimit,
+		UniversalDefault: spec.UniversalDefault,
+		Disabled:         spec.Disabled,
+		ContactMethod:    spec.ContactMethod,
+	}
+}
+
+func updateChildChannels(client *api.APIClient, key string, added []string, removed []string) error {
+	if len(added) > 0 {
+		if err := activationkey.AddChildChannels(client, key, added); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		return activationkey.RemoveChildChannels(client, key, removed)
+	}
+	return nil
+}
+
+// updateSystemGroups adds and removes system groups by name.
+//
+// The names are resolved when applying as the groups may have been created by the same run.
+func updateSystemGroups(client *api.APIClient, key string, added []string, removed []string) error {
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	ids, err := getSystemGroupIDs(client)
+	if err != nil {
+		return err
+	}
+	toIDs := func(names []string) ([]int, error) {
+		result := []int{}
+		for _, name := range names {
+			id, ok := ids[name]
+			if !ok {
+				return nil, fmt.Errorf(L("no system group named %s"), name)
+			}
+			result = append(result, id)
+		}
+		return result, nil
+	}
+
+	if len(added) > 0 {
+		addedIDs, err := toIDs(added)
+		if err != nil {
+			return err
+		}
+		if err := activationkey.AddServerGroups(client, key, addedIDs); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		removedIDs, err := toIDs(removed)
+		if err != nil {
+			return err
+		}
+		return activationkey.RemoveServerGroups(client, key, removedIDs)
+	}
+	return nil
+}
+
+func getSystemGroupIDs(client *api.APIClient) (map[string]int, error) {
+	groups, err := systemgroup.ListAllGroups(client)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]int{}
+	for _, group := range groups {
+		ids[group.Name] = group.ID
+	}
+	return ids, nil
+}
+
+func getSystemGroupNames(client *api.APIClient, groupIDs []int) ([]string, error) {
+	ids, err := getSystemGroupIDs(client)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name, id := range ids {
+		for _, groupID := range groupIDs {
+			if id == groupID {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type applyFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	File                  []string
+	Prune                 bool
+	PruneKinds            []string
+}
+
+// resourcesHelp returns the description of the resources files shared by the commands.
+func resourcesHelp() string {
+	return L(`The files contain YAML documents separated by --- lines, each describing a resource:
+
+kind: SystemGroup
+name: web
+spec:
+  description: Web servers
+---
+kind: User
+name: jdoe
+spec:
+  password: secret
+  firstName: John
+  lastName: Doe
+  email: jdoe@example.com
+  roles: [system_group_admin]
+
+The supported kinds are Organization, User, ActivationKey, SystemGroup and ConfigChannel.
+Directories passed to --file are replaced by the YAML and JSON files they contain.
+
+With --prune, the existing resources of the kinds present in the files but not defined in them are deleted,
+except for the organizations. Use --pruneKinds to only prune some kinds: organizations are only pruned if
+listed there. The logged in user, its organization and the default organization are never deleted.`)
+}
+
+// NewCommand generates the command applying resources files.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	var flags applyFlags
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: L("Create or update the server configuration from resources files"),
+		Long: L(`Create or update the server configuration from resources files.
+
+The missing resources are created, the changed ones updated.
+
+`) + resourcesHelp(),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runApply)
+		},
+	}
+	addFlags(cmd)
+	return cmd
+}
+
+// NewDiffCommand generates the command showing the changes the apply command would do.
+func NewDiffCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	var flags applyFlags
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: L("Show the changes needed for the server configuration to match resources files"),
+		Long: L(`Show the changes the apply command would do without changing anything.
+
+`) + resourcesHelp(),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runDiff)
+		},
+	}
+	addFlags(cmd)
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP("file", "f", []string{}, L("Resources file or directory, can be repeated"))
+	cmd.Flags().Bool("prune", false, L("Delete the resources of the same kinds missing in the files"))
+	cmd.Flags().StringSlice("pruneKinds", []string{},
+		L("Kinds of the resources to prune, implies --prune. Organizations are only pruned if listed"),
+	)
+	_ = cmd.MarkFlagRequired("file")
+	api.AddAPIFlags(cmd)
+}
+
+func runApply(_ *types.GlobalFlags, flags *applyFlags, _ *cobra.Command, _ []string) error {
+	changes, err := prepareChanges(flags)
+	if err != nil {
+		return err
+	}
+	return applyChanges(changes, os.Stdout)
+}
+
+func runDiff(_ *types.GlobalFlags, flags *applyFlags, _ *cobra.Command, _ []string) error {
+	changes, err := prepareChanges(flags)
+	if err != nil {
+		return err
+	}
+	return printChanges(changes, os.Stdout)
+}
+
+// prepareChanges reads the resources files and computes the changes to apply.
+func prepareChanges(flags *applyFlags) ([]*change, error) {
+	if len(flags.File) == 0 {
+		return nil, errors.New(L("at least one resources file is required, use --file to set it"))
+	}
+	pruneKinds, err := getPruneKinds(flags.Prune, flags.PruneKinds)
+	if err != nil {
+		return nil, err
+	}
+	handlers := newHandlers()
+	resources, err := readResources(flags.File, handlers)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := api.Init(&flags.ConnectionDetails)
+	if err == nil {
+		err = client.Login()
+	}
+	if err != nil {
+		return nil, utils.Errorf(err, L("unable to login to the server"))
+	}
+
+	changes, err := planChanges(client, handlers, resources, pruneKinds)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func printChanges(changes []*change, out io.Writer) error {
+	if len(changes) == 0 {
+		log.Info().Msg(L("No change needed"))
+		return nil
+	}
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(out, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyChanges runs the changes in order, stopping at the first failure.
+func applyChanges(changes []*change, out io.Writer) error {
+	if err := printChanges(changes, out); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		log.Debug().Msgf("Running %s of %s %s", change.action, change.kind, change.name)
+		if err := change.run(); err != nil {
+			return utils.Errorf(err, L("failed to apply the changes of %[1]s %[2]s"), change.kind, change.name)
+		}
+	}
+	if len(changes) > 0 {
+		log.Info().Msgf(L("%d changes applied"), len(changes))
+	}
+	return nil
+}
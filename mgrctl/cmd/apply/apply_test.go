@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+const testResources = `kind: SystemGroup
+name: web
+spec:
+  description: Web servers
+---
+# Empty documents are ignored
+---
+kind: User
+name: jdoe
+spec:
+  password: secret
+  firstName: John
+  lastName: Doe
+  email: jdoe@example.com
+  roles: [system_group_admin]
+---
+kind: User
+name: admin
+spec:
+  password: secret
+  email: admin@example.com
+`
+
+func TestParseResources(t *testing.T) {
+	resources, err := parseResources([]byte(testResources), newHandlers())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong number of resources", 3, len(resources))
+	testutils.AssertEquals(t, "Wrong kind", "User", resources[1].Kind)
+	spec := resources[1].spec.(*userSpec)
+	testutils.AssertEquals(t, "Wrong email", "jdoe@example.com", spec.Email)
+	testutils.AssertEquals(t, "Wrong roles", "system_group_admin", strings.Join(spec.Roles, ","))
+
+	invalid := map[string]string{
+		"unknown kind":  "kind: Foo\nname: foo\n",
+		"missing name":  "kind: SystemGroup\n",
+		"unknown field": "kind: SystemGroup\nname: web\nspec:\n  foo: bar\n",
+		"missing pass":  "kind: User\nname: jdoe\n",
+		"channel type":  "kind: ConfigChannel\nname: conf\nspec:\n  type: foo\n",
+	}
+	for name, content := range invalid {
+		if _, err := parseResources([]byte(content), newHandlers()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadResourcesDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("kind: SystemGroup\nname: web\n")
+	for _, name := range []string{"a.yaml", "b.yml"} {
+		if err := os.WriteFile(path.Join(dir, name), content, 0600); err != nil {
+			t.Fatalf("failed to write test file: %s", err)
+		}
+	}
+	_, err := readResources([]string{dir}, newHandlers())
+	if err == nil || !strings.Contains(err.Error(), "defined in both") {
+		t.Errorf("expected a duplicate error, got: %v", err)
+	}
+}
+
+// newTestServer starts a fake server with a web system group and handlers for the users and groups calls.
+//
+// It returns the flags to call the API and the parameters of the calls.
+func newTestServer(t *testing.T) (*testutils.FakeAPIServer, []string, map[string]map[string]interface{}) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+
+	calls := map[string]map[string]interface{}{}
+	record := func(path string, result interface{}) testutils.FakeAPIHandler {
+		return func(_ *testutils.FakeUser, params map[string]interface{}) (interface{}, error) {
+			calls[path] = params
+			return result, nil
+		}
+	}
+	server.Handle(http.MethodGet, "systemgroup/listAllGroups", true,
+		record("systemgroup/listAllGroups", []map[string]interface{}{
+			{"id": 1, "name": "web", "description": "Old description"},
+			{"id": 2, "name": "db", "description": "Databases"},
+		}),
+	)
+	server.Handle(http.MethodGet, "systemgroup/getDetails", true,
+		record("systemgroup/getDetails", map[string]interface{}{"id": 1, "name": "web", "description": "Old"}),
+	)
+	server.Handle(http.MethodPost, "systemgroup/update", true, record("systemgroup/update", map[string]int{}))
+	server.Handle(http.MethodPost, "systemgroup/delete", true, record("systemgroup/delete", 1))
+	server.Handle(http.MethodGet, "user/listRoles", true, record("user/listRoles", []string{"org_admin"}))
+	server.Handle(http.MethodPost, "user/addRole", true, record("user/addRole", 1))
+	server.Handle(http.MethodPost, "user/setDetails", true, record("user/setDetails", 1))
+	server.Handle(http.MethodPost, "user/delete", true, record("user/delete", 1))
+	return server, apiArgs, calls
+}
+
+func TestApply(t *testing.T) {
+	server, apiArgs, calls := newTestServer(t)
+	file := path.Join(t.TempDir(), "resources.yaml")
+	if err := os.WriteFile(file, []byte(testResources), 0600); err != nil {
+		t.Fatalf("failed to write test file: %s", err)
+	}
+
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(append([]string{"-f", file}, apiArgs...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testutils.AssertEquals(t, "Wrong updated group", interface{}("web"), calls["systemgroup/update"]["systemGroupName"])
+	testutils.AssertEquals(t, "Wrong group description", interface{}("Web servers"),
+		calls["systemgroup/update"]["description"],
+	)
+
+	testutils.AssertEquals(t, "Wrong number of users", 2, len(server.Users))
+	created := server.Users[1]
+	testutils.AssertEquals(t, "Wrong created user", "jdoe", created.Login)
+	testutils.AssertEquals(t, "Wrong created user email", "jdoe@example.com", created.Email)
+	testutils.AssertEquals(t, "Wrong added role", interface{}("system_group_admin"), calls["user/addRole"]["role"])
+
+	details, _ := calls["user/setDetails"]["details"].(map[string]interface{})
+	testutils.AssertEquals(t, "Wrong updated user", interface{}("admin"), calls["user/setDetails"]["login"])
+	testutils.AssertEquals(t, "Wrong updated email", interface{}("admin@example.com"), details["email"])
+	_, hasPassword := details["password"]
+	testutils.AssertTrue(t, "The password should not be updated", !hasPassword)
+}
+
+func TestDiffPrune(t *testing.T) {
+	server, _, calls := newTestServer(t)
+	client, err := api.Init(&api.ConnectionDetails{
+		Server: server.Host(), CApath: server.CACertFile(t), CredStore: api.CredStorePlain,
+		User: testutils.FakeAdminLogin, Password: testutils.FakeAdminPassword,
+	})
+	if err == nil {
+		err = client.Login()
+	}
+	if err != nil {
+		t.Fatalf("failed to login: %s", err)
+	}
+
+	handlers := newHandlers()
+	resources, err := parseResources([]byte("kind: SystemGroup\nname: web\nspec:\n  description: Old\n"+
+		"---\nkind: User\nname: jdoe\nspec:\n  password: secret\n"), handlers)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pruneKinds, err := getPruneKinds(true, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertTrue(t, "Organizations should not be pruned by default", !pruneKinds["Organization"])
+	changes, err := planChanges(client, handlers, resources, pruneKinds)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := printChanges(changes, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The up to date web group is unchanged, the logged in admin user is never pruned
+	testutils.AssertEquals(t, "Wrong diff", "+ User jdoe\n- SystemGroup db\n", out.String())
+	_, updated := calls["systemgroup/update"]
+	testutils.AssertTrue(t, "diff should not change anything", !updated)
+}
+
+func TestPruneUnknownCaller(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	server.AddToken("mytoken", "admin")
+	client, err := api.Init(&api.ConnectionDetails{
+		Server: server.Host(), CApath: server.CACertFile(t), Token: "mytoken", CredStore: api.CredStorePlain,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize the client: %s", err)
+	}
+
+	handlers := newHandlers()
+	resources, err := parseResources([]byte("kind: User\nname: jdoe\nspec:\n  password: secret\n"), handlers)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Without knowing the user running the command, it could be deleted
+	if _, err := planChanges(client, handlers, resources, map[string]bool{"User": true}); err == nil {
+		t.Error("pruning without knowing the user running the command should fail")
+	}
+
+	// Without pruning the user is not needed
+	if _, err := planChanges(client, handlers, resources, map[string]bool{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	_, err = getPruneKinds(false, []string{"Foo"})
+	testutils.AssertTrue(t, "unsupported kinds to prune should fail", err != nil)
+}
+
+func TestDiffOrganizations(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := testutils.NewFakeAPIServer(t)
+	server.AddOrg("default", "root", "secret")
+	server.AddOrg("myorg", "admin", "secret")
+	server.AddOrg("other", "otheradmin", "secret")
+	server.AddOrg("old", "oldadmin", "secret")
+
+	client, err := api.Init(&api.ConnectionDetails{
+		Server: server.Host(), CApath: server.CACertFile(t), User: "admin", Password: "secret",
+		CredStore: api.CredStorePlain,
+	})
+	if err == nil {
+		err = client.Login()
+	}
+	if err != nil {
+		t.Fatalf("failed to login: %s", err)
+	}
+
+	handlers := newHandlers()
+	resources, err := parseResources([]byte("kind: Organization\nname: other\nspec:\n  admin:\n"+
+		"    login: otheradmin\n    password: secret\n"), handlers)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Organizations are only pruned when explicitly requested
+	pruneKinds, err := getPruneKinds(true, []string{"Organization"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	changes, err := planChanges(client, handlers, resources, pruneKinds)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var out bytes.Buffer
+	if err := printChanges(changes, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The default organization and the one of the logged in user are never pruned
+	testutils.AssertEquals(t, "Wrong diff", "- Organization old\n", out.String())
+
+	// A different administrator cannot be applied to an existing organization
+	resources[0].spec.(*orgSpec).Admin.Login = "root"
+	_, err = planChanges(client, handlers, resources, map[string]bool{})
+	testutils.AssertTrue(t, "a different organization administrator should fail", err != nil)
+}
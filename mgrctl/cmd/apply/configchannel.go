@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/configchannel"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// configChannelSpec is the spec of a ConfigChannel resource, named after the channel label.
+//
+// The name defaults to the label and the type to normal.
+type configChannelSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+type configChannelHandler struct{}
+
+func (h *configChannelHandler) decode(res *resource) (interface{}, error) {
+	spec, err := decodeSpec[configChannelSpec](res)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Name == "" {
+		spec.Name = res.Name
+	}
+	if spec.Type == "" {
+		spec.Type = "normal"
+	}
+	if spec.Type != "normal" && spec.Type != "state" {
+		return nil, fmt.Errorf(L("unsupported configuration channel type %s, use normal or state"), spec.Type)
+	}
+	return spec, nil
+}
+
+func (h *configChannelHandler) list(client *api.APIClient) ([]string, error) {
+	channels, err := configchannel.ListGlobals(client)
+	if err != nil {
+		return nil, err
+	}
+	labels := []string{}
+	for _, channel := range channels {
+		labels = append(labels, channel.Label)
+	}
+	return labels, nil
+}
+
+func (h *configChannelHandler) plan(client *api.APIClient, res *resource, exists bool) (*change, error) {
+	spec := res.spec.(*configChannelSpec)
+	if !exists {
+		return &change{
+			action: actionCreate,
+			kind:   res.Kind,
+			name:   res.Name,
+			run: func() error {
+				_, err := configchannel.Create(client, res.Name, spec.Name, spec.Description, spec.Type)
+				return err
+			},
+		}, nil
+	}
+
+	channel, err := configchannel.GetDetails(client, res.Name)
+	if err != nil {
+		return nil, err
+	}
+	if channel.TypeLabel() != spec.Type {
+		return nil, fmt.Errorf(L("the type of configuration channel %[1]s cannot be changed from %[2]s to %[3]s"),
+			res.Name, channel.TypeLabel(), spec.Type)
+	}
+	details := []string{}
+	diffField(&details, "name", channel.Name, spec.Name)
+	diffField(&details, "description", channel.Description, spec.Description)
+	if len(details) == 0 {
+		return nil, nil
+	}
+	return &change{
+		action:  actionUpdate,
+		kind:    res.Kind,
+		name:    res.Name,
+		details: details,
+		run: func() error {
+			_, err := configchannel.Update(client, res.Name, spec.Name, spec.Description)
+			return err
+		},
+	}, nil
+}
+
+func (h *configChannelHandler) prune(client *api.APIClient, _ *caller, name string) *change {
+	return &change{
+		action: actionDelete,
+		kind:   "ConfigChannel",
+		name:   name,
+		run: func() error {
+			return configchannel.DeleteChannels(client, []string{name})
+		},
+	}
+}
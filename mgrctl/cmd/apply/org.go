@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/org"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// orgSpec is the spec of an Organization resource.
+//
+// The administrator is only used to create the organization: for existing ones it is only checked to be one of
+// their administrators. Use a User resource to manage its details.
+type orgSpec struct {
+	Admin      orgAdminSpec `json:"admin"`
+	UsePamAuth bool         `json:"usePamAuth"`
+}
+
+type orgAdminSpec struct {
+	Login     string `json:"login"`
+	Password  string `json:"password"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+}
+
+// defaultOrgID is the ID of the organization created with the server.
+const defaultOrgID = 1
+
+type orgHandler struct {
+	ids map[string]int
+}
+
+func (h *orgHandler) decode(res *resource) (interface{}, error) {
+	spec, err := decodeSpec[orgSpec](res)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Admin.Login == "" {
+		return nil, errors.New(L("the organization administrator login is required"))
+	}
+	if spec.Admin.Password == "" && !spec.UsePamAuth {
+		return nil, errors.New(L("the organization administrator password is required"))
+	}
+	return spec, nil
+}
+
+func (h *orgHandler) list(client *api.APIClient) ([]string, error) {
+	orgs, err := org.ListOrgs(client)
+	if err != nil {
+		return nil, err
+	}
+	h.ids = map[string]int{}
+	names := []string{}
+	for _, organization := range orgs {
+		h.ids[organization.Name] = organization.ID
+		names = append(names, organization.Name)
+	}
+	return names, nil
+}
+
+func (h *orgHandler) plan(client *api.APIClient, res *resource, exists bool) (*change, error) {
+	if exists {
+		// Nothing can be changed on an organization, but do not silently ignore a different administrator
+		return nil, h.checkAdmin(client, res)
+	}
+	spec := res.spec.(*orgSpec)
+	return &change{
+		action:  actionCreate,
+		kind:    res.Kind,
+		name:    res.Name,
+		details: []string{"admin: " + spec.Admin.Login},
+		run: func() error {
+			admin := types.User{
+				Login:     spec.Admin.Login,
+				Password:  spec.Admin.Password,
+				FirstName: spec.Admin.FirstName,
+				LastName:  spec.Admin.LastName,
+				Email:     spec.Admin.Email,
+			}
+			_, err := org.Create(client, res.Name, &admin, spec.UsePamAuth)
+			return err
+		},
+	}, nil
+}
+
+// checkAdmin returns an error if the administrator of the spec is not one of the organization ones.
+func (h *orgHandler) checkAdmin(client *api.APIClient, res *resource) error {
+	spec := res.spec.(*orgSpec)
+	users, err := org.ListUsers(client, h.ids[res.Name])
+	if err != nil {
+		return err
+	}
+	for _, orgUser := range users {
+		if orgUser.Login == spec.Admin.Login && orgUser.IsOrgAdmin {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		L("%[1]s is not an administrator of organization %[2]s and it can only be set when creating it"),
+		spec.Admin.Login, res.Name,
+	)
+}
+
+func (h *orgHandler) prune(client *api.APIClient, owner *caller, name string) *change {
+	id := h.ids[name]
+	if id == defaultOrgID || id == owner.orgID {
+		// Never delete the default organization or the one of the user applying the changes
+		return nil
+	}
+	return &change{
+		action: actionDelete,
+		kind:   "Organization",
+		name:   name,
+		run: func() error {
+			return org.Delete(client, id)
+		},
+	}
+}
@@ -0,0 +1,361 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/user"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// resource is a document of the files passed to the apply and diff commands.
+type resource struct {
+	Kind string          `json:"kind"`
+	Name string          `json:"name"`
+	Spec json.RawMessage `json:"spec,omitempty"`
+
+	// spec is the spec decoded by the handler of the kind.
+	spec interface{}
+}
+
+// kindHandler reconciles the resources of a kind.
+type kindHandler interface {
+	// decode parses and checks the spec of a resource.
+	decode(res *resource) (interface{}, error)
+	// list returns the names of the existing resources.
+	list(client *api.APIClient) ([]string, error)
+	// plan returns the change making a resource match its spec or nil if it is up to date.
+	plan(client *api.APIClient, res *resource, exists bool) (*change, error)
+	// prune returns the change removing a resource missing in the files or nil if it has to be kept.
+	prune(client *api.APIClient, owner *caller, name string) *change
+}
+
+// caller is the user running the command, never pruned with its organization.
+type caller struct {
+	login string
+	orgID int
+}
+
+// getCaller finds the user running the command on the server.
+//
+// The user is the one passed to the command or the owner of the stored session.
+func getCaller(client *api.APIClient) (*caller, error) {
+	login := client.Details.User
+	if login == "" {
+		return nil, errors.New(L("cannot find the user running the command, set it with --api-user to prune"))
+	}
+	details, err := user.GetDetails(client, login)
+	if err != nil {
+		return nil, utils.Errorf(err, L("cannot find the user running the command, refusing to prune"))
+	}
+	return &caller{login: login, orgID: details.OrgID}, nil
+}
+
+// kinds lists the supported resource kinds in the order they need to be created.
+var kinds = []string{"Organization", "SystemGroup", "ConfigChannel", "User", "ActivationKey"}
+
+// explicitPruneKinds lists the kinds only pruned when requested by name, as deleting them removes too much.
+var explicitPruneKinds = []string{"Organization"}
+
+// getPruneKinds returns the kinds of resources to prune.
+//
+// The kinds can be passed explicitly, otherwise all of them are pruned if prune is true, except the ones that need
+// to be named explicitly.
+func getPruneKinds(prune bool, explicitKinds []string) (map[string]bool, error) {
+	pruneKinds := map[string]bool{}
+	for _, kind := range explicitKinds {
+		if !slices.Contains(kinds, kind) {
+			return nil, fmt.Errorf(L("unsupported resource kind %[1]s, supported kinds are: %[2]s"),
+				kind, strings.Join(kinds, ", "))
+		}
+		pruneKinds[kind] = true
+	}
+	if prune && len(explicitKinds) == 0 {
+		for _, kind := range kinds {
+			if !slices.Contains(explicitPruneKinds, kind) {
+				pruneKinds[kind] = true
+			}
+		}
+	}
+	return pruneKinds, nil
+}
+
+func newHandlers() map[string]kindHandler {
+	return map[string]kindHandler{
+		"Organization":  &orgHandler{},
+		"SystemGroup":   &systemGroupHandler{},
+		"ConfigChannel": &configChannelHandler{},
+		"User":          &userHandler{},
+		"ActivationKey": &activationKeyHandler{},
+	}
+}
+
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+	actionDelete = "delete"
+)
+
+// change is an operation needed to converge a resource.
+type change struct {
+	action  string
+	kind    string
+	name    string
+	details []string
+	run     func() error
+}
+
+// String returns the diff-like description of the change.
+func (c *change) String() string {
+	symbol := map[string]string{actionCreate: "+", actionUpdate: "~", actionDelete: "-"}[c.action]
+	lines := []string{fmt.Sprintf("%s %s %s", symbol, c.kind, c.name)}
+	for _, detail := range c.details {
+		lines = append(lines, "    "+detail)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readResources reads the resources from files or the YAML and JSON files of directories.
+func readResources(paths []string, handlers map[string]kindHandler) ([]*resource, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []*resource{}
+	defined := map[string]string{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to read %s"), file)
+		}
+		fileResources, err := parseResources(content, handlers)
+		if err != nil {
+			return nil, utils.Errorf(err, L("invalid resources file %s"), file)
+		}
+		for _, res := range fileResources {
+			id := res.Kind + "/" + res.Name
+			if previous, ok := defined[id]; ok {
+				return nil, fmt.Errorf(L("%[1]s %[2]s is defined in both %[3]s and %[4]s"),
+					res.Kind, res.Name, previous, file)
+			}
+			defined[id] = file
+			resources = append(resources, res)
+		}
+	}
+	return resources, nil
+}
+
+// expandPaths replaces the directories by the YAML and JSON files they contain.
+func expandPaths(paths []string) ([]string, error) {
+	files := []string{}
+	for _, filePath := range paths {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, filePath)
+			continue
+		}
+		entries, err := os.ReadDir(filePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch path.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, path.Join(filePath, entry.Name()))
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// parseResources decodes the YAML documents of a file.
+func parseResources(content []byte, handlers map[string]kindHandler) ([]*resource, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	resources := []*resource{}
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, err
+		}
+		if string(data) == "null" {
+			// Empty document
+			continue
+		}
+
+		var res resource
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&res); err != nil {
+			return nil, err
+		}
+		handler, ok := handlers[res.Kind]
+		if !ok {
+			return nil, fmt.Errorf(L("unsupported resource kind %[1]s, supported kinds are: %[2]s"),
+				res.Kind, strings.Join(kinds, ", "))
+		}
+		if res.Name == "" {
+			return nil, fmt.Errorf(L("a %s resource has no name"), res.Kind)
+		}
+		res.spec, err = handler.decode(&res)
+		if err != nil {
+			return nil, utils.Errorf(err, L("invalid spec for %[1]s %[2]s"), res.Kind, res.Name)
+		}
+		resources = append(resources, &res)
+	}
+	return resources, nil
+}
+
+// decodeSpec decodes the spec of a resource, rejecting the unknown fields.
+func decodeSpec[T interface{}](res *resource) (*T, error) {
+	var spec T
+	if len(res.Spec) == 0 || string(res.Spec) == "null" {
+		return &spec, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(res.Spec))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// planChanges computes the changes needed for the server to match the resources.
+//
+// The existing resources of the kinds found in the files and in pruneKinds, but not defined in the files, are
+// deleted. The creations and updates are ordered to create the dependencies first, the deletions come last.
+func planChanges(
+	client *api.APIClient, handlers map[string]kindHandler, resources []*resource, pruneKinds map[string]bool,
+) ([]*change, error) {
+	changes := []*change{}
+	deletions := []*change{}
+
+	var owner *caller
+	if len(pruneKinds) > 0 {
+		var err error
+		if owner, err = getCaller(client); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kind := range kinds {
+		kindResources := []*resource{}
+		wanted := map[string]bool{}
+		for _, res := range resources {
+			if res.Kind == kind {
+				kindResources = append(kindResources, res)
+				wanted[res.Name] = true
+			}
+		}
+		if len(kindResources) == 0 {
+			continue
+		}
+
+		handler := handlers[kind]
+		names, err := handler.list(client)
+		if err != nil {
+			return nil, err
+		}
+		existing := map[string]bool{}
+		for _, name := range names {
+			existing[name] = true
+		}
+
+		for _, res := range kindResources {
+			planned, err := handler.plan(client, res, existing[res.Name])
+			if err != nil {
+				return nil, err
+			}
+			if planned != nil {
+				changes = append(changes, planned)
+			}
+		}
+
+		if pruneKinds[kind] {
+			sort.Strings(names)
+			kindDeletions := []*change{}
+			for _, name := range names {
+				if wanted[name] {
+					continue
+				}
+				if deletion := handler.prune(client, owner, name); deletion != nil {
+					kindDeletions = append(kindDeletions, deletion)
+				}
+			}
+			deletions = append(kindDeletions, deletions...)
+		}
+	}
+	return append(changes, deletions...), nil
+}
+
+// diffField adds a line to details if the current and wanted values differ.
+func diffField(details *[]string, field string, current interface{}, wanted interface{}) {
+	currentJSON, _ := json.Marshal(current)
+	wantedJSON, _ := json.Marshal(wanted)
+	if !bytes.Equal(currentJSON, wantedJSON) {
+		*details = append(*details, fmt.Sprintf("%s: %s -> %s", field, currentJSON, wantedJSON))
+	}
+}
+
+// diffLists returns the values missing in current and those not in wanted, sorted.
+func diffLists(current []string, wanted []string) (added []string, removed []string) {
+	currentSet := map[string]bool{}
+	for _, value := range current {
+		currentSet[value] = true
+	}
+	wantedSet := map[string]bool{}
+	for _, value := range wanted {
+		wantedSet[value] = true
+		if !currentSet[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range current {
+		if !wantedSet[value] {
+			removed = append(removed, value)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// diffListField adds the lines describing the values to add and remove to details.
+func diffListField(details *[]string, field string, added []string, removed []string) {
+	if len(added) > 0 {
+		*details = append(*details, fmt.Sprintf("%s: + %s", field, strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		*details = append(*details, fmt.Sprintf("%s: - %s", field, strings.Join(removed, ", ")))
+	}
+}
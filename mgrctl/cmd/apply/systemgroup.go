@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/systemgroup"
+)
+
+// systemGroupSpec is the spec of a SystemGroup resource.
+type systemGroupSpec struct {
+	Description string `json:"description"`
+}
+
+type systemGroupHandler struct{}
+
+func (h *systemGroupHandler) decode(res *resource) (interface{}, error) {
+	return decodeSpec[systemGroupSpec](res)
+}
+
+func (h *systemGroupHandler) list(client *api.APIClient) ([]string, error) {
+	groups, err := systemgroup.ListAllGroups(client)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return names, nil
+}
+
+func (h *systemGroupHandler) plan(client *api.APIClient, res *resource, exists bool) (*change, error) {
+	spec := res.spec.(*systemGroupSpec)
+	if !exists {
+		return &change{
+			action: actionCreate,
+			kind:   res.Kind,
+			name:   res.Name,
+			run: func() error {
+				_, err := systemgroup.Create(client, res.Name, spec.Description)
+				return err
+			},
+		}, nil
+	}
+
+	group, err := systemgroup.GetDetails(client, res.Name)
+	if err != nil {
+		return nil, err
+	}
+	details := []string{}
+	diffField(&details, "description", group.Description, spec.Description)
+	if len(details) == 0 {
+		return nil, nil
+	}
+	return &change{
+		action:  actionUpdate,
+		kind:    res.Kind,
+		name:    res.Name,
+		details: details,
+		run: func() error {
+			return systemgroup.Update(client, res.Name, spec.Description)
+		},
+	}, nil
+}
+
+func (h *systemGroupHandler) prune(client *api.APIClient, _ *caller, name string) *change {
+	return &change{
+		action: actionDelete,
+		kind:   "SystemGroup",
+		name:   name,
+		run: func() error {
+			return systemgroup.Delete(client, name)
+		},
+	}
+}
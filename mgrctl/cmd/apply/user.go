@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"errors"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/api/user"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// userSpec is the spec of a User resource, named after the user login.
+//
+// The password is only used to create the user.
+// The roles are left untouched if not set.
+type userSpec struct {
+	Password   string   `json:"password"`
+	FirstName  string   `json:"firstName"`
+	LastName   string   `json:"lastName"`
+	Email      string   `json:"email"`
+	UsePamAuth bool     `json:"usePamAuth"`
+	Roles      []string `json:"roles"`
+}
+
+type userHandler struct{}
+
+func (h *userHandler) decode(res *resource) (interface{}, error) {
+	spec, err := decodeSpec[userSpec](res)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Password == "" && !spec.UsePamAuth {
+		return nil, errors.New(L("the user password is required"))
+	}
+	return spec, nil
+}
+
+func (h *userHandler) list(client *api.APIClient) ([]string, error) {
+	users, err := user.ListUsers(client)
+	if err != nil {
+		return nil, err
+	}
+	logins := []string{}
+	for _, item := range users {
+		logins = append(logins, item.Login)
+	}
+	return logins, nil
+}
+
+func (h *userHandler) plan(client *api.APIClient, res *resource, exists bool) (*change, error) {
+	spec := res.spec.(*userSpec)
+	if !exists {
+		details := []string{}
+		diffListField(&details, "roles", spec.Roles, nil)
+		return &change{
+			action:  actionCreate,
+			kind:    res.Kind,
+			name:    res.Name,
+			details: details,
+			run: func() error {
+				if err := user.Create(client, toUser(res.Name, spec), spec.UsePamAuth); err != nil {
+					return err
+				}
+				return updateRoles(client, res.Name, spec.Roles, nil)
+			},
+		}, nil
+	}
+
+	current, err := user.GetDetails(client, res.Name)
+	if err != nil {
+		return nil, err
+	}
+	details := []string{}
+	diffField(&details, "firstName", current.FirstName, spec.FirstName)
+	diffField(&details, "lastName", current.LastName, spec.LastName)
+	diffField(&details, "email", current.Email, spec.Email)
+	detailsChanged := len(details) > 0
+
+	var added, removed []string
+	if spec.Roles != nil {
+		roles, err := user.ListRoles(client, res.Name)
+		if err != nil {
+			return nil, err
+		}
+		added, removed = diffLists(roles, spec.Roles)
+		diffListField(&details, "roles", added, removed)
+	}
+	if len(details) == 0 {
+		return nil, nil
+	}
+
+	return &change{
+		action:  actionUpdate,
+		kind:    res.Kind,
+		name:    res.Name,
+		details: details,
+		run: func() error {
+			if detailsChanged {
+				// Do not reset the password
+				userDetails := toUser(res.Name, spec)
+				userDetails.Password = ""
+				if err := user.SetDetails(client, userDetails); err != nil {
+					return err
+				}
+			}
+			return updateRoles(client, res.Name, added, removed)
+		},
+	}, nil
+}
+
+func (h *userHandler) prune(client *api.APIClient, owner *caller, name string) *change {
+	if name == owner.login {
+		// Never delete the user applying the changes
+		return nil
+	}
+	return &change{
+		action: actionDelete,
+		kind:   "User",
+		name:   name,
+		run: func() error {
+			return user.Delete(client, name)
+		},
+	}
+}
+
+func toUser(login string, spec *userSpec) *types.User {
+	return &types.User{
+		Login:     login,
+		Password:  spec.Password,
+		FirstName: spec.FirstName,
+		LastName:  spec.LastName,
+		Email:     spec.Email,
+	}
+}
+
+func updateRoles(client *api.APIClient, login string, added []string, removed []string) error {
+	for _, role := range added {
+		if err := user.AddRole(client, login, role); err != nil {
+			return err
+		}
+	}
+	for _, role := range removed {
+		if err := user.RemoveRole(client, login, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
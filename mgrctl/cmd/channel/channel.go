@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type channelFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	Output                string
+	Parent                string
+	Name                  string
+	Label                 string
+	Summary               string
+	Arch                  string
+	Description           string
+	Original              bool
+	Wait                  bool
+	Timeout               time.Duration
+	Interval              time.Duration
+	Backend               string
+}
+
+// NewCommand generates the software channels management command.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	var flags channelFlags
+
+	channelCmd := &cobra.Command{
+		Use:   "channel",
+		Short: L("Manage software channels"),
+		Run: func(cmd *cobra.Command, _ []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: L("List the software channels"),
+		Long:  L("List the software channels visible to the user or the children of a base channel."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runList)
+		},
+	}
+	listCmd.Flags().String("parent", "", L("Label of the base channel to list the children of"))
+
+	showCmd := &cobra.Command{
+		Use:   "show label",
+		Short: L("Show the details of a software channel"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runShow)
+		},
+	}
+
+	cloneCmd := &cobra.Command{
+		Use:   "clone source",
+		Short: L("Clone a software channel"),
+		Long: L(`Clone a software channel with its packages and patches.
+
+The --label and --name flags are required.
+The other values that are not set are taken from the source channel.
+
+Example:
+# mgrctl channel clone sles15-sp6-pool-x86_64 --label dev-sles15-sp6-pool-x86_64 --name "Dev SLES 15 SP6"`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runClone)
+		},
+	}
+	cloneCmd.Flags().String("label", "", L("Label of the new channel"))
+	cloneCmd.Flags().String("name", "", L("Name of the new channel"))
+	cloneCmd.Flags().String("summary", "", L("Summary of the new channel"))
+	cloneCmd.Flags().String("parent", "", L("Label of the parent of the new channel"))
+	cloneCmd.Flags().String("arch", "", L("Architecture label of the new channel"))
+	cloneCmd.Flags().String("description", "", L("Description of the new channel"))
+	cloneCmd.Flags().Bool("original", false, L("Clone only the original packages, without the patches"))
+	_ = cloneCmd.MarkFlagRequired("label")
+	_ = cloneCmd.MarkFlagRequired("name")
+
+	syncCmd := &cobra.Command{
+		Use:   "sync label",
+		Short: L("Synchronize the repositories of a software channel"),
+		Long: L(`Schedule the synchronization of the repositories of a software channel.
+
+With --wait, the command returns once the last synchronization date of the channel changed.
+If the server container can be reached, the command also fails as soon as the synchronization log
+reports a finished synchronization without updating that date.
+
+Example:
+# mgrctl channel sync sles15-sp6-updates-x86_64 --wait --timeout 6h`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runSync)
+		},
+	}
+	syncCmd.Flags().Bool("wait", false, L("Wait for the synchronization to finish"))
+	syncCmd.Flags().Duration("timeout", 2*time.Hour,
+		L("Maximum time to wait for the synchronization, 0 to wait forever"),
+	)
+	syncCmd.Flags().Duration("interval", 30*time.Second, L("Time between two checks of the synchronization status"))
+	utils.AddBackendFlag(syncCmd)
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete label...",
+		Short: L("Delete software channels"),
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runDelete)
+		},
+	}
+
+	channelCmd.AddCommand(listCmd)
+	channelCmd.AddCommand(showCmd)
+	channelCmd.AddCommand(cloneCmd)
+	channelCmd.AddCommand(syncCmd)
+	channelCmd.AddCommand(deleteCmd)
+	api.AddAPIFlags(channelCmd)
+	utils.AddOutputFlag(channelCmd)
+
+	return channelCmd
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestClone(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+
+	var cloneParams map[string]interface{}
+	server.Handle(http.MethodPost, "channel/software/clone", true,
+		func(_ *testutils.FakeUser, params map[string]interface{}) (interface{}, error) {
+			cloneParams = params
+			return 123, nil
+		},
+	)
+
+	args := append([]string{"clone", "sles15-pool", "--label", "dev-sles15-pool", "--name", "Dev pool",
+		"--summary", "Dev summary", "--parent", "dev-base", "--arch", "channel-x86_64",
+		"--description", "Dev description", "--original",
+	}, apiArgs...)
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testutils.AssertEquals(t, "Wrong source channel", interface{}("sles15-pool"), cloneParams["channelLabel"])
+	testutils.AssertEquals(t, "Wrong original state", interface{}(true), cloneParams["originalState"])
+	details, _ := cloneParams["details"].(map[string]interface{})
+	expected := map[string]string{
+		"label":        "dev-sles15-pool",
+		"name":         "Dev pool",
+		"summary":      "Dev summary",
+		"parent_label": "dev-base",
+		"arch_label":   "channel-x86_64",
+		"description":  "Dev description",
+	}
+	for key, value := range expected {
+		testutils.AssertEquals(t, "Wrong clone detail "+key, interface{}(value), details[key])
+	}
+}
+
+func TestSyncWait(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+
+	lastSync := "2025-01-01T10:00:00Z"
+	checks := 0
+	server.Handle(http.MethodGet, "channel/software/getDetails", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			checks++
+			// The synchronization finishes after a few checks
+			if checks == 3 {
+				lastSync = "2025-01-02T10:00:00Z"
+			}
+			return map[string]interface{}{
+				"label":             "sles15-updates",
+				"yumrepo_last_sync": lastSync,
+				"contentSources":    []map[string]interface{}{{"id": 1, "label": "updates"}},
+			}, nil
+		},
+	)
+	synced := false
+	server.Handle(http.MethodPost, "channel/software/syncRepo", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			synced = true
+			return 1, nil
+		},
+	)
+
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(append([]string{"sync", "sles15-updates", "--wait", "--interval", "1ms"}, apiArgs...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertTrue(t, "Synchronization not triggered", synced)
+	testutils.AssertEquals(t, "Wrong number of status checks", 3, checks)
+}
+
+func TestSyncTimeout(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+
+	server.Handle(http.MethodGet, "channel/software/getDetails", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{
+				"label":          "sles15-updates",
+				"contentSources": []map[string]interface{}{{"id": 1, "label": "updates"}},
+			}, nil
+		},
+	)
+	server.Handle(http.MethodPost, "channel/software/syncRepo", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return 1, nil
+		},
+	)
+
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(append([]string{"sync", "sles15-updates", "--wait", "--interval", "1ms", "--timeout", "10ms"},
+		apiArgs...,
+	))
+	testutils.AssertTrue(t, "Expected a timeout error", cmd.Execute() != nil)
+}
+
+func TestDeleteFailure(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+	server.Handle(http.MethodPost, "channel/software/delete", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return nil, errors.New("No such channel")
+		},
+	)
+
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(append([]string{"delete", "missing"}, apiArgs...))
+	err := cmd.Execute()
+	testutils.AssertTrue(t, "Expected an error", err != nil)
+}
+
+func TestSyncFailure(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	server.LoggedInArgs(t)
+	server.Handle(http.MethodGet, "channel/software/getDetails", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{
+				"label":             "sles15-updates",
+				"yumrepo_last_sync": "2025-01-01T10:00:00Z",
+				"contentSources":    []map[string]interface{}{{"id": 1, "label": "updates"}},
+			}, nil
+		},
+	)
+	server.Handle(http.MethodPost, "channel/software/syncRepo", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return 1, nil
+		},
+	)
+
+	flags := channelFlags{
+		ConnectionDetails: api.ConnectionDetails{
+			Server: server.Host(), CApath: server.CACertFile(t), CredStore: api.CredStorePlain,
+			User: testutils.FakeAdminLogin, Password: testutils.FakeAdminPassword,
+		},
+		Wait:     true,
+		Interval: time.Millisecond,
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	oldRun := "2025/01/01 10:00:00 +00:00 ERROR: old failure\n2025/01/01 10:00:00 +00:00 Total time: 0:00:10\n"
+	newRun := "2025/01/02 10:00:00 +00:00 ERROR: Repository failed to download\n" +
+		"2025/01/02 10:00:00 +00:00 Sync completed.\n2025/01/02 10:00:00 +00:00 Total time: 0:00:03\n"
+	reads := 0
+	syncLog := &reposyncLog{
+		path: "/var/log/rhn/reposync/sles15-updates.log",
+		exec: func(command string, args ...string) ([]byte, error) {
+			if command == "sh" {
+				return []byte(fmt.Sprintf("%d\n", len(oldRun))), nil
+			}
+			testutils.AssertEquals(t, "Wrong log offset", fmt.Sprintf("+%d", len(oldRun)+1), args[1])
+			reads++
+			if reads < 3 {
+				return []byte("2025/01/02 10:00:00 +00:00 Syncing...\n"), nil
+			}
+			return []byte(newRun), nil
+		},
+	}
+
+	err = syncChannel(client, &flags, "sles15-updates", syncLog)
+	if err == nil {
+		t.Fatal("expected a synchronization failure")
+	}
+	testutils.AssertTrue(t, "Missing sync error: "+err.Error(),
+		strings.Contains(err.Error(), "ERROR: Repository failed to download"),
+	)
+	testutils.AssertTrue(t, "Old errors should be ignored: "+err.Error(), !strings.Contains(err.Error(), "old failure"))
+	testutils.AssertEquals(t, "Wrong number of log reads", 3, reads)
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/channel"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func runClone(_ *types.GlobalFlags, flags *channelFlags, _ *cobra.Command, args []string) error {
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	request := channel.CloneRequest{
+		Name:        flags.Name,
+		Label:       flags.Label,
+		Summary:     flags.Summary,
+		ParentLabel: flags.Parent,
+		ArchLabel:   flags.Arch,
+		Description: flags.Description,
+	}
+	if _, err := channel.Clone(client, args[0], request, flags.Original); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Channel %[1]s cloned into %[2]s"), args[0], flags.Label)
+	return nil
+}
+
+func runDelete(_ *types.GlobalFlags, flags *channelFlags, _ *cobra.Command, args []string) error {
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	for _, label := range args {
+		if err := channel.Delete(client, label); err != nil {
+			return err
+		}
+		log.Info().Msgf(L("Channel %s deleted"), label)
+	}
+	return nil
+}
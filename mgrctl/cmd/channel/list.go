@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/channel"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func runList(_ *types.GlobalFlags, flags *channelFlags, cmd *cobra.Command, _ []string) error {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	if flags.Parent != "" {
+		children, err := channel.ListChildren(client, flags.Parent)
+		if err != nil {
+			return err
+		}
+		return utils.PrintOutput(os.Stdout, format, children)
+	}
+
+	channels, err := channel.ListSoftwareChannels(client)
+	if err != nil {
+		return err
+	}
+	return utils.PrintOutput(os.Stdout, format, channels)
+}
+
+func runShow(_ *types.GlobalFlags, flags *channelFlags, cmd *cobra.Command, args []string) error {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	details, err := channel.GetDetails(client, args[0])
+	if err != nil {
+		return err
+	}
+	return utils.PrintOutput(os.Stdout, format, details)
+}
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/channel"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// reposyncLogDir is the folder of the server container holding the synchronization logs of the channels.
+const reposyncLogDir = "/var/log/rhn/reposync"
+
+// reposyncEndMarker is logged by spacewalk-repo-sync at the end of each run, even failed ones.
+const reposyncEndMarker = "Total time:"
+
+func runSync(_ *types.GlobalFlags, flags *channelFlags, _ *cobra.Command, args []string) error {
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	var syncLog *reposyncLog
+	if flags.Wait {
+		syncLog = newReposyncLog(flags.Backend, args[0])
+	}
+	return syncChannel(client, flags, args[0], syncLog)
+}
+
+// reposyncLog reads the synchronization log of a channel in the server container.
+type reposyncLog struct {
+	exec   func(command string, args ...string) ([]byte, error)
+	path   string
+	offset int
+}
+
+// newReposyncLog returns the synchronization log reader of a channel.
+//
+// Returns nil if the server container cannot be reached, like when the server is remote.
+func newReposyncLog(backend string, label string) *reposyncLog {
+	cnx, err := shared.NewContainerConnection(backend, shared.DefaultContainer)
+	if err == nil {
+		_, err = cnx.GetCommand()
+	}
+	if err != nil {
+		log.Debug().Err(err).Msg("Cannot read the synchronization log, only waiting for the last sync date to change")
+		return nil
+	}
+	return &reposyncLog{exec: cnx.Exec, path: path.Join(reposyncLogDir, label+".log")}
+}
+
+// start skips the existing content of the log to only look at the coming synchronization.
+func (l *reposyncLog) start() {
+	out, err := l.exec("sh", "-c", fmt.Sprintf("stat -c %%s %s 2>/dev/null || echo 0", l.path))
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to get the size of %s", l.path)
+		return
+	}
+	if size, err := strconv.Atoi(strings.TrimSpace(string(out))); err == nil {
+		l.offset = size
+	}
+}
+
+// finished returns whether the synchronization is over and the errors it logged.
+func (l *reposyncLog) finished() (bool, []string) {
+	out, err := l.exec("tail", "-c", fmt.Sprintf("+%d", l.offset+1), l.path)
+	if err != nil {
+		// The log file may not be created yet
+		log.Debug().Err(err).Msgf("Failed to read %s", l.path)
+		return false, nil
+	}
+	content := string(out)
+	if !strings.Contains(content, reposyncEndMarker) {
+		return false, nil
+	}
+	errorLines := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, "ERROR") {
+			errorLines = append(errorLines, strings.TrimSpace(line))
+		}
+	}
+	return true, errorLines
+}
+
+// syncChannel triggers the repositories synchronization and waits for it if requested.
+//
+// The synchronization is considered finished when the last synchronization date of the channel changes.
+// If syncLog is not nil, a finished synchronization without a new date is considered as failed.
+func syncChannel(client *api.APIClient, flags *channelFlags, label string, syncLog *reposyncLog) error {
+	before, err := channel.GetDetails(client, label)
+	if err != nil {
+		return err
+	}
+	if flags.Wait && len(before.ContentSources) == 0 {
+		return fmt.Errorf(L("channel %s has no repository to synchronize"), label)
+	}
+
+	if syncLog != nil {
+		syncLog.start()
+	}
+	if err := channel.SyncRepo(client, label); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Synchronization of channel %s scheduled"), label)
+	if !flags.Wait {
+		return nil
+	}
+
+	isSynced := func() (bool, error) {
+		details, err := channel.GetDetails(client, label)
+		if err != nil {
+			return false, err
+		}
+		log.Debug().Msgf("Last synchronization of channel %s: %s", label, details.LastSync)
+		return details.LastSync != "" && details.LastSync != before.LastSync, nil
+	}
+
+	log.Info().Msgf(L("Waiting for the synchronization of channel %s to finish"), label)
+	done, err := utils.WaitFor(flags.Timeout, flags.Interval, func() (bool, error) {
+		if synced, err := isSynced(); err != nil || synced {
+			return synced, err
+		}
+		if syncLog == nil {
+			return false, nil
+		}
+		finished, errorLines := syncLog.finished()
+		if !finished {
+			return false, nil
+		}
+		// The date may have been updated right before the end of the log was written
+		if synced, err := isSynced(); err != nil || synced {
+			return synced, err
+		}
+		return false, syncError(label, syncLog.path, errorLines)
+	})
+	if err != nil {
+		return err
+	}
+	if !done {
+		return fmt.Errorf(L("synchronization of channel %[1]s not finished after %[2]s"), label, flags.Timeout)
+	}
+	log.Info().Msgf(L("Channel %s synchronized"), label)
+	return nil
+}
+
+func syncError(label string, logPath string, errorLines []string) error {
+	if len(errorLines) == 0 {
+		return fmt.Errorf(L("synchronization of channel %[1]s failed, see %[2]s for details"), label, logPath)
+	}
+	return utils.Errorf(errors.New(strings.Join(errorLines, "\n")),
+		L("synchronization of channel %[1]s failed, see %[2]s for details"), label, logPath,
+	)
+}
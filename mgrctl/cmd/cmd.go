@@ -11,9 +11,12 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/api"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/apply"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/channel"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/cp"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/exec"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/proxy"
+	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/system"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/term"
 	"github.com/uyuni-project/uyuni-tools/shared/completion"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
@@ -52,11 +55,15 @@ func NewUyunictlCommand() *cobra.Command {
 
 	apiCmd := api.NewCommand(globalFlags)
 	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(apply.NewCommand(globalFlags))
+	rootCmd.AddCommand(apply.NewDiffCommand(globalFlags))
+	rootCmd.AddCommand(channel.NewCommand(globalFlags))
 	rootCmd.AddCommand(exec.NewCommand(globalFlags))
 	rootCmd.AddCommand(term.NewCommand(globalFlags))
 	rootCmd.AddCommand(cp.NewCommand(globalFlags))
 	rootCmd.AddCommand(completion.NewCommand(globalFlags))
 	rootCmd.AddCommand(proxy.NewCommand(globalFlags))
+	rootCmd.AddCommand(system.NewCommand(globalFlags))
 
 	rootCmd.AddCommand(utils.GetConfigHelpCommand())
 
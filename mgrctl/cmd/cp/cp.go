@@ -7,17 +7,17 @@ package cp
 import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
-	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
-	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
 type flagpole struct {
-	User    string
-	Group   string
-	Backend string
+	User      string
+	Group     string
+	Preserve  bool
+	Backend   string
+	Container string
 }
 
 // NewCommand copy file to and from the containers.
@@ -25,11 +25,17 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	flags := &flagpole{}
 
 	cpCmd := &cobra.Command{
-		Use:   "cp [path/to/source.file] [path/to/destination.file]",
+		Use:   "cp [path/to/source...] [path/to/destination]",
 		Short: L("Copy files to and from the containers"),
-		Long: L(`Takes a source and destination parameters.
-	One of them can be prefixed with 'server:' to indicate the path is within the server pod.`),
-		Args: cobra.ExactArgs(2),
+		Long: L(`Takes one or more sources and a destination parameters.
+	Either the sources or the destination can be prefixed with 'server:' to indicate the path is within the container.
+
+	The sources may contain glob patterns and directories are copied recursively.
+	Like cp, the sources are copied inside the destination if it is an existing directory or ends with a slash.
+
+	Example:
+	# mgrctl cp 'server:/srv/www/htdocs/pub/*.iso' /tmp/isos/`),
+		Args: cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			viper, err := utils.ReadConfig(cmd, utils.GlobalConfigFilename, globalFlags.ConfigPath)
 			if err != nil {
@@ -44,12 +50,18 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 
 	cpCmd.Flags().String("user", "", L("User or UID to set on the destination file"))
 	cpCmd.Flags().String("group", "susemanager", L("Group or GID to set on the destination file"))
+	cpCmd.Flags().BoolP("preserve", "p", false, L("Preserve the permissions and modification times of the files"))
 
 	utils.AddBackendFlag(cpCmd)
+	shared.AddContainerFlag(cpCmd)
 	return cpCmd
 }
 
 func run(flags *flagpole, _ *cobra.Command, args []string) error {
-	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter)
-	return cnx.Copy(args[0], args[1], flags.User, flags.Group)
+	cnx, err := shared.NewContainerConnection(flags.Backend, flags.Container)
+	if err != nil {
+		return err
+	}
+	options := shared.CopyOptions{User: flags.User, Group: flags.Group, Preserve: flags.Preserve}
+	return cnx.CopyFiles(args[:len(args)-1], args[len(args)-1], options)
 }
@@ -15,9 +15,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/shared"
-	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
-	"github.com/uyuni-project/uyuni-tools/shared/podman"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
@@ -27,6 +25,7 @@ type flagpole struct {
 	Interactive bool
 	Tty         bool
 	Backend     string
+	Container   string
 }
 
 // NewCommand returns a new cobra.Command for exec.
@@ -48,11 +47,15 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	execCmd.Flags().BoolP("tty", "t", false, L("Stdin is a TTY"))
 
 	utils.AddBackendFlag(execCmd)
+	shared.AddContainerFlag(execCmd)
 	return execCmd
 }
 
 func run(_ *types.GlobalFlags, flags *flagpole, _ *cobra.Command, args []string) error {
-	cnx := shared.NewConnection(flags.Backend, podman.ServerContainerName, kubernetes.ServerFilter)
+	cnx, err := shared.NewContainerConnection(flags.Backend, flags.Container)
+	if err != nil {
+		return err
+	}
 	podName, err := cnx.GetPodName()
 	if err != nil {
 		return err
@@ -81,7 +84,7 @@ func run(_ *types.GlobalFlags, flags *flagpole, _ *cobra.Command, args []string)
 		if namespace == "" {
 			log.Fatal().Err(err)
 		}
-		commandArgs = append(commandArgs, "-n", namespace, "-c", "uyuni", "--")
+		commandArgs = append(commandArgs, "-n", namespace, "-c", cnx.GetKubernetesContainer(), "--")
 	}
 
 	newEnv := []string{}
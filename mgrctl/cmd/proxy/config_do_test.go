@@ -345,3 +345,43 @@ func TestSuccessProxyCreateConfigGenerateWhenAllParamsProvidedSuccess(t *testing
 		fmt.Sprintf("%v", expectedConfigFileData),
 		fmt.Sprintf("%v", storedConfigFile))
 }
+
+// tests the proxy create config generate command against a fake API server.
+func TestProxyCreateConfigGenerateWithFakeServer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := testutils.NewFakeAPIServer(t)
+	server.AddOrg("myorg", "admin", "secret")
+
+	testDir := t.TempDir()
+	testFiles := setupTestFiles(t, testDir)
+
+	flags := &proxyCreateConfigFlags{
+		ConnectionDetails: api.ConnectionDetails{
+			Server:    server.Host(),
+			User:      "admin",
+			Password:  "secret",
+			CApath:    server.CACertFile(t),
+			CredStore: api.CredStorePlain,
+		},
+		Proxy: proxyFlags{
+			Name:     "proxy.example.com",
+			Port:     8022,
+			Parent:   "server.example.com",
+			MaxCache: 2048,
+			Email:    "admin@example.com",
+		},
+		Output: path.Join(testDir, "config"),
+		SSL: proxyConfigSSLFlags{
+			Ca: caFlags{
+				SSLPair:  types.SSLPair{Cert: testFiles.CaCrtFilePath, Key: testFiles.CaKeyFilePath},
+				Password: dummyCaPasswordContents,
+			},
+		},
+	}
+
+	err := proxyCreateConfig(flags, api.Init, proxyApi.ContainerConfig, proxyApi.ContainerConfigGenerate)
+	testutils.AssertTrue(t, "Unexpected error executing ProxyCreateConfigGenerate", err == nil)
+	testutils.AssertTrue(t, "File configuration file was not stored", utils.FileExists(flags.Output+".tar.gz"))
+	testutils.AssertEquals(t, "Wrong number of requests", 1, len(server.ProxyConfigs))
+	testutils.AssertEquals(t, "Wrong CA key", interface{}(dummyCaKeyContents), server.ProxyConfigs[0]["caKey"])
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/channel"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	"github.com/uyuni-project/uyuni-tools/shared/api/systemgroup"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func runList(_ *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, _ []string) error {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	systems, err := listSystems(client, flags)
+	if err != nil {
+		return err
+	}
+	return utils.PrintOutput(os.Stdout, format, systems)
+}
+
+// listSystems returns the systems matching all the filters of the flags.
+func listSystems(client *api.APIClient, flags *systemFlags) ([]system.SystemOverview, error) {
+	filters, err := getFilters(client, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	systems := []system.SystemOverview{}
+	err = system.ForEachSystem(client, func(item system.SystemOverview) error {
+		for _, filter := range filters {
+			if !filter[item.ID] {
+				return nil
+			}
+		}
+		systems = append(systems, item)
+		return nil
+	})
+	return systems, err
+}
+
+// getFilters returns the IDs of the systems matching each of the requested filters.
+func getFilters(client *api.APIClient, flags *systemFlags) ([]map[int]bool, error) {
+	filters := []map[int]bool{}
+
+	if flags.Group != "" {
+		systems, err := systemgroup.ListSystemsMinimal(client, flags.Group)
+		if err != nil {
+			return nil, err
+		}
+		filter := map[int]bool{}
+		for _, item := range systems {
+			filter[item.ID] = true
+		}
+		filters = append(filters, filter)
+	}
+
+	if flags.Channel != "" {
+		systems, err := channel.ListSubscribedSystems(client, flags.Channel)
+		if err != nil {
+			return nil, err
+		}
+		filter := map[int]bool{}
+		for _, item := range systems {
+			filter[item.ID] = true
+		}
+		filters = append(filters, filter)
+	}
+
+	if flags.Outdated {
+		systems, err := system.ListOutOfDateSystems(client)
+		if err != nil {
+			return nil, err
+		}
+		filter := map[int]bool{}
+		for _, item := range systems {
+			filter[item.ID] = true
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func runShow(_ *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, args []string) error {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+
+	sids, err := resolveSystems(client, args)
+	if err != nil {
+		return err
+	}
+	details, err := system.GetDetails(client, sids[0])
+	if err != nil {
+		return err
+	}
+	return utils.PrintOutput(os.Stdout, format, details)
+}
@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/schedule"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// actionResult is the output of the commands scheduling actions.
+type actionResult struct {
+	Action   int    `json:"action"`
+	SystemID int    `json:"system_id,omitempty"`
+	System   string `json:"system,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+func runPatch(_ *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, args []string) error {
+	format, earliest, err := getScheduleOptions(cmd, flags)
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+	sids, err := resolveSystems(client, args)
+	if err != nil {
+		return err
+	}
+
+	errataIDs, err := getErrataToInstall(client, sids, flags.Advisory)
+	if err != nil {
+		return err
+	}
+	if len(errataIDs) == 0 {
+		log.Info().Msg(L("No patch to install"))
+		return utils.PrintOutput(os.Stdout, format, []actionResult{})
+	}
+
+	actions, err := system.ScheduleApplyErrata(client, sids, errataIDs, earliest)
+	if err != nil {
+		return err
+	}
+	return handleActions(client, flags, format, actions)
+}
+
+// getErrataToInstall returns the IDs of the relevant patches for the systems.
+//
+// If advisories is not empty, only the patches with those names are returned.
+func getErrataToInstall(client *api.APIClient, sids []int, advisories []string) ([]int, error) {
+	selected := map[string]bool{}
+	for _, advisory := range advisories {
+		selected[advisory] = false
+	}
+
+	found := map[int]bool{}
+	errataIDs := []int{}
+	for _, sid := range sids {
+		errata, err := system.GetRelevantErrata(client, sid)
+		if err != nil {
+			return nil, err
+		}
+		for _, erratum := range errata {
+			if _, ok := selected[erratum.AdvisoryName]; len(selected) > 0 && !ok {
+				continue
+			}
+			selected[erratum.AdvisoryName] = true
+			if !found[erratum.ID] {
+				found[erratum.ID] = true
+				errataIDs = append(errataIDs, erratum.ID)
+			}
+		}
+	}
+
+	for _, advisory := range advisories {
+		if !selected[advisory] {
+			log.Warn().Msgf(L("Patch %s is not relevant for any of the systems"), advisory)
+		}
+	}
+	return errataIDs, nil
+}
+
+func runReboot(_ *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, args []string) error {
+	format, earliest, err := getScheduleOptions(cmd, flags)
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+	sids, err := resolveSystems(client, args)
+	if err != nil {
+		return err
+	}
+
+	actions := []int{}
+	for _, sid := range sids {
+		action, err := system.ScheduleReboot(client, sid, earliest)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, action)
+	}
+	return handleActions(client, flags, format, actions)
+}
+
+func runRun(_ *types.GlobalFlags, flags *systemFlags, cmd *cobra.Command, args []string) error {
+	format, earliest, err := getScheduleOptions(cmd, flags)
+	if err != nil {
+		return err
+	}
+	script, err := getScript(flags)
+	if err != nil {
+		return err
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		return err
+	}
+	sids, err := resolveSystems(client, args)
+	if err != nil {
+		return err
+	}
+
+	action, err := system.ScheduleScriptRun(client, sids, script, flags.ScriptTimeout, earliest)
+	if err != nil {
+		return err
+	}
+	return handleActions(client, flags, format, []int{action})
+}
+
+// getScript returns the content of the script to run from the --script or --command flag.
+func getScript(flags *systemFlags) (string, error) {
+	if (flags.Script == "") == (flags.Command == "") {
+		return "", errors.New(L("either --script or --command is required"))
+	}
+	if flags.Command != "" {
+		return fmt.Sprintf("#!/bin/sh\n%s\n", flags.Command), nil
+	}
+	content, err := os.ReadFile(flags.Script)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to read %s"), flags.Script)
+	}
+	return string(content), nil
+}
+
+// getScheduleOptions returns the output format and the earliest execution date of the actions.
+func getScheduleOptions(cmd *cobra.Command, flags *systemFlags) (string, time.Time, error) {
+	format, err := utils.GetOutputFormat(cmd, flags.Output, "table")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	earliest := time.Now()
+	if flags.Earliest != "" {
+		earliest, err = time.Parse(time.RFC3339, flags.Earliest)
+		if err != nil {
+			return "", time.Time{}, utils.Errorf(err, L("invalid earliest date %s"), flags.Earliest)
+		}
+	}
+	return format, earliest, nil
+}
+
+// handleActions prints the scheduled actions, or their results per system once finished if --wait is set.
+//
+// An error is returned if any of the actions failed on any system.
+func handleActions(client *api.APIClient, flags *systemFlags, format string, actions []int) error {
+	if !flags.Wait {
+		results := []actionResult{}
+		for _, action := range actions {
+			results = append(results, actionResult{Action: action})
+		}
+		return utils.PrintOutput(os.Stdout, format, results)
+	}
+
+	if err := waitForActions(client, flags, actions); err != nil {
+		return err
+	}
+
+	results, err := getActionResults(client, actions)
+	if err != nil {
+		return err
+	}
+	if err := utils.PrintOutput(os.Stdout, format, results); err != nil {
+		return err
+	}
+
+	failed := []string{}
+	for _, result := range results {
+		if result.Status == "failed" {
+			failed = append(failed, fmt.Sprintf("%d/%s", result.Action, result.System))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf(L("actions failed: %s"), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// waitForActions waits until none of the actions is queued or running on any system.
+func waitForActions(client *api.APIClient, flags *systemFlags, actions []int) error {
+	log.Info().Msg(L("Waiting for the actions to finish"))
+	pending := actions
+	done, err := utils.WaitFor(flags.Timeout, flags.Interval, func() (bool, error) {
+		stillPending := []int{}
+		for _, action := range pending {
+			systems, err := schedule.ListInProgressSystems(client, action)
+			if err != nil {
+				return false, err
+			}
+			log.Debug().Msgf("Action %d in progress on %d systems", action, len(systems))
+			if len(systems) > 0 {
+				stillPending = append(stillPending, action)
+			}
+		}
+		pending = stillPending
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !done {
+		return fmt.Errorf(L("actions %[1]v not finished after %[2]s"), pending, flags.Timeout)
+	}
+	return nil
+}
+
+// getActionResults returns the status of finished actions on each system.
+func getActionResults(client *api.APIClient, actions []int) ([]actionResult, error) {
+	results := []actionResult{}
+	for _, action := range actions {
+		completed, err := schedule.ListCompletedSystems(client, action)
+		if err != nil {
+			return nil, err
+		}
+		failed, err := schedule.ListFailedSystems(client, action)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, toActionResults(action, "completed", completed)...)
+		results = append(results, toActionResults(action, "failed", failed)...)
+	}
+	return results, nil
+}
+
+func toActionResults(action int, status string, systems []schedule.ActionSystem) []actionResult {
+	results := []actionResult{}
+	for _, item := range systems {
+		results = append(results, actionResult{
+			Action:   action,
+			SystemID: item.ServerID,
+			System:   item.ServerName,
+			Status:   status,
+			Message:  item.Message,
+		})
+	}
+	return results
+}
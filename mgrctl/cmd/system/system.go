@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+type systemFlags struct {
+	api.ConnectionDetails `mapstructure:"api"`
+	Output                string
+	Group                 string
+	Channel               string
+	Outdated              bool
+	Advisory              []string
+	Script                string
+	Command               string
+	ScriptTimeout         int
+	Earliest              string
+	Wait                  bool
+	Timeout               time.Duration
+	Interval              time.Duration
+}
+
+// NewCommand generates the systems management command.
+func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
+	var flags systemFlags
+
+	systemCmd := &cobra.Command{
+		Use:   "system",
+		Short: L("Manage the registered systems"),
+		Run: func(cmd *cobra.Command, _ []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: L("List the systems"),
+		Long: L(`List the systems visible to the user.
+
+The filters can be combined to list only the systems matching all of them.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runList)
+		},
+	}
+	listCmd.Flags().String("group", "", L("List only the systems of this system group"))
+	listCmd.Flags().String("channel", "", L("List only the systems subscribed to this software channel label"))
+	listCmd.Flags().Bool("outdated", false, L("List only the systems with packages to update"))
+
+	showCmd := &cobra.Command{
+		Use:   "show system",
+		Short: L("Show the details of a system"),
+		Long:  L("Show the details of a system given by its ID or name."),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runShow)
+		},
+	}
+
+	patchCmd := &cobra.Command{
+		Use:   "patch system...",
+		Short: L("Schedule the installation of patches on systems"),
+		Long: L(`Schedule the installation of patches on systems given by their ID or name.
+
+All the relevant patches are installed unless some are selected using --advisory.
+The identifiers of the scheduled actions are printed.
+
+Example:
+# mgrctl system patch web1.example.com web2.example.com --advisory SUSE-2025-1234 --wait`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runPatch)
+		},
+	}
+	patchCmd.Flags().StringSlice("advisory", []string{}, L("Name of a patch to install, can be repeated"))
+
+	rebootCmd := &cobra.Command{
+		Use:   "reboot system...",
+		Short: L("Schedule the reboot of systems"),
+		Long: L(`Schedule the reboot of systems given by their ID or name.
+
+The identifiers of the scheduled actions are printed.`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runReboot)
+		},
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run system...",
+		Short: L("Schedule a script to run on systems"),
+		Long: L(`Schedule a script to run as root on systems given by their ID or name.
+
+The script is either read from a file or built from a shell command.
+The identifier of the scheduled action is printed.
+
+Example:
+# mgrctl system run web1.example.com --command "systemctl restart apache2" --wait`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return utils.CommandHelper(globalFlags, cmd, args, &flags, nil, runRun)
+		},
+	}
+	runCmd.Flags().String("script", "", L("Path to the script to run"))
+	runCmd.Flags().String("command", "", L("Shell command to run"))
+	runCmd.Flags().Int("scriptTimeout", 600, L("Number of seconds after which the script is stopped"))
+
+	for _, cmd := range []*cobra.Command{patchCmd, rebootCmd, runCmd} {
+		cmd.Flags().String("earliest", "", L("Earliest date to run the action, in RFC 3339 format. Default: now"))
+		cmd.Flags().Bool("wait", false, L("Wait for the actions to finish and fail if any of them failed"))
+		cmd.Flags().Duration("timeout", 0, L("Maximum time to wait for the actions, 0 to wait forever"))
+		cmd.Flags().Duration("interval", 10*time.Second, L("Time between two checks of the actions status"))
+	}
+
+	systemCmd.AddCommand(listCmd)
+	systemCmd.AddCommand(showCmd)
+	systemCmd.AddCommand(patchCmd)
+	systemCmd.AddCommand(rebootCmd)
+	systemCmd.AddCommand(runCmd)
+	api.AddAPIFlags(systemCmd)
+	utils.AddOutputFlag(systemCmd)
+
+	return systemCmd
+}
+
+// resolveSystems converts the system IDs or names to IDs.
+func resolveSystems(client *api.APIClient, args []string) ([]int, error) {
+	sids := []int{}
+	for _, arg := range args {
+		if sid, err := strconv.Atoi(arg); err == nil {
+			sids = append(sids, sid)
+			continue
+		}
+		systems, err := system.GetID(client, arg)
+		if err != nil {
+			return nil, err
+		}
+		switch len(systems) {
+		case 0:
+			return nil, fmt.Errorf(L("no system named %s"), arg)
+		case 1:
+			sids = append(sids, systems[0].ID)
+		default:
+			return nil, fmt.Errorf(L("several systems are named %s, use the system ID instead"), arg)
+		}
+	}
+	return sids, nil
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func systemsHandler(ids ...int) testutils.FakeAPIHandler {
+	return func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+		systems := []map[string]interface{}{}
+		for _, id := range ids {
+			systems = append(systems, map[string]interface{}{"id": id, "name": "system"})
+		}
+		return systems, nil
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	server.LoggedInArgs(t)
+	server.Handle(http.MethodGet, "system/listSystems", true, systemsHandler(1, 2, 3, 4))
+	server.Handle(http.MethodGet, "system/listOutOfDateSystems", true, systemsHandler(2, 3))
+
+	var group interface{}
+	server.Handle(http.MethodGet, "systemgroup/listSystemsMinimal", true,
+		func(user *testutils.FakeUser, params map[string]interface{}) (interface{}, error) {
+			group = params["systemGroupName"]
+			return systemsHandler(1, 3, 4)(user, params)
+		},
+	)
+
+	flags := systemFlags{
+		ConnectionDetails: api.ConnectionDetails{
+			Server: server.Host(), CApath: server.CACertFile(t), CredStore: api.CredStorePlain,
+			User: testutils.FakeAdminLogin, Password: testutils.FakeAdminPassword,
+		},
+		Group:    "web",
+		Outdated: true,
+	}
+	client, err := api.InitAndLogin(&flags.ConnectionDetails)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	systems, err := listSystems(client, &flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testutils.AssertEquals(t, "Wrong group", interface{}("web"), group)
+	testutils.AssertEquals(t, "Wrong number of systems", 1, len(systems))
+	testutils.AssertEquals(t, "Wrong system", 3, systems[0].ID)
+}
+
+func TestPatchWaitFailed(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+	server.Handle(http.MethodGet, "system/getId", true, systemsHandler(42))
+	server.Handle(http.MethodGet, "system/getRelevantErrata", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return []map[string]interface{}{
+				{"id": 10, "advisory_name": "SUSE-2025-1"},
+				{"id": 11, "advisory_name": "SUSE-2025-2"},
+			}, nil
+		},
+	)
+
+	var scheduleParams map[string]interface{}
+	server.Handle(http.MethodPost, "system/scheduleApplyErrata", true,
+		func(_ *testutils.FakeUser, params map[string]interface{}) (interface{}, error) {
+			scheduleParams = params
+			return []int{100}, nil
+		},
+	)
+
+	checks := 0
+	server.Handle(http.MethodGet, "schedule/listInProgressSystems", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			checks++
+			if checks < 3 {
+				return []map[string]interface{}{{"server_id": 42, "server_name": "web1"}}, nil
+			}
+			return []interface{}{}, nil
+		},
+	)
+	server.Handle(http.MethodGet, "schedule/listCompletedSystems", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return []interface{}{}, nil
+		},
+	)
+	server.Handle(http.MethodGet, "schedule/listFailedSystems", true,
+		func(_ *testutils.FakeUser, _ map[string]interface{}) (interface{}, error) {
+			return []map[string]interface{}{{"server_id": 42, "server_name": "web1", "message": "conflict"}}, nil
+		},
+	)
+
+	args := append([]string{"patch", "web1", "--advisory", "SUSE-2025-2", "--wait", "--interval", "1ms"},
+		apiArgs...)
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "100/web1") {
+		t.Errorf("expected failed action error, got: %v", err)
+	}
+
+	testutils.AssertEquals(t, "Wrong systems", interface{}([]interface{}{float64(42)}), scheduleParams["sids"])
+	testutils.AssertEquals(t, "Wrong patches", interface{}([]interface{}{float64(11)}), scheduleParams["errataIds"])
+	testutils.AssertEquals(t, "Wrong number of status checks", 3, checks)
+}
+
+func TestRunCommand(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	apiArgs := server.LoggedInArgs(t)
+
+	var scriptParams map[string]interface{}
+	server.Handle(http.MethodPost, "system/scheduleScriptRun", true,
+		func(_ *testutils.FakeUser, params map[string]interface{}) (interface{}, error) {
+			scriptParams = params
+			return 200, nil
+		},
+	)
+
+	args := append([]string{"run", "12", "13", "--command", "uptime", "--scriptTimeout", "30"}, apiArgs...)
+	cmd := NewCommand(&types.GlobalFlags{})
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testutils.AssertEquals(t, "Wrong systems", interface{}([]interface{}{float64(12), float64(13)}),
+		scriptParams["sids"])
+	testutils.AssertEquals(t, "Wrong script", interface{}("#!/bin/sh\nuptime\n"), scriptParams["script"])
+	testutils.AssertEquals(t, "Wrong timeout", interface{}(float64(30)), scriptParams["timeout"])
+}
+
+func TestRunRequiresScript(t *testing.T) {
+	_, err := getScript(&systemFlags{})
+	testutils.AssertTrue(t, "missing script should fail", err != nil)
+
+	_, err = getScript(&systemFlags{Script: "script.sh", Command: "uptime"})
+	testutils.AssertTrue(t, "script and command should fail", err != nil)
+}
@@ -7,6 +7,7 @@ package term
 import (
 	"github.com/spf13/cobra"
 	"github.com/uyuni-project/uyuni-tools/mgrctl/cmd/exec"
+	"github.com/uyuni-project/uyuni-tools/shared"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
 	"github.com/uyuni-project/uyuni-tools/shared/types"
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
@@ -18,7 +19,7 @@ var newExecCmd = exec.NewCommand
 func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "term",
-		Short: L("Run a terminal inside the server container"),
+		Short: L("Run a terminal inside the server container or the one set by --container"),
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			execCmd := newExecCmd(globalFlags)
 			execArgs := []string{"-i", "-t"}
@@ -26,6 +27,10 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 			if err == nil {
 				execArgs = append(execArgs, "--backend", backend)
 			}
+			container, err := cmd.Flags().GetString("container")
+			if err == nil {
+				execArgs = append(execArgs, "--container", container)
+			}
 			if err := execCmd.Flags().Parse(execArgs); err != nil {
 				return err
 			}
@@ -34,5 +39,6 @@ func NewCommand(globalFlags *types.GlobalFlags) *cobra.Command {
 	}
 
 	utils.AddBackendFlag(cmd)
+	shared.AddContainerFlag(cmd)
 	return cmd
 }
@@ -29,13 +29,16 @@ func TestExecute(t *testing.T) {
 			if backend, err := cmd.Flags().GetString("backend"); err != nil || backend != "mybackend" {
 				t.Error("backend flag not passed")
 			}
+			if container, err := cmd.Flags().GetString("container"); err != nil || container != "db" {
+				t.Error("container flag not passed")
+			}
 			return errors.New("some error")
 		}
 		return execCmd
 	}
 
 	cmd := NewCommand(&globalFlags)
-	if err := cmd.Flags().Parse([]string{"--backend", "mybackend"}); err != nil {
+	if err := cmd.Flags().Parse([]string{"--backend", "mybackend", "-C", "db"}); err != nil {
 		t.Errorf("failed to parse flags: %s", err)
 	}
 	if err := cmd.RunE(cmd, []string{}); err.Error() != "some error" {
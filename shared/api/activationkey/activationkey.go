@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package activationkey
+
+import (
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListActivationKeys returns the activation keys of the user's organization.
+func ListActivationKeys(client *api.APIClient) ([]ActivationKey, error) {
+	keys, err := api.GetResult[[]ActivationKey](client, "activationkey/listActivationKeys", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the activation keys"))
+	}
+	return keys, nil
+}
+
+// GetDetails returns an activation key.
+func GetDetails(client *api.APIClient, key string) (*ActivationKey, error) {
+	details, err := api.GetResult[ActivationKey](client, "activationkey/getDetails", url.Values{"key": {key}})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the details of activation key %s"), key)
+	}
+	return &details, nil
+}
+
+// Create creates an activation key and returns the key as the server may have prefixed or generated it.
+func Create(client *api.APIClient, request CreateRequest) (string, error) {
+	key, err := api.PostResult[string](client, "activationkey/create", CreateRequestToMap(request))
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to create activation key %s"), request.Key)
+	}
+	return key, nil
+}
+
+// SetDetails updates an activation key.
+func SetDetails(client *api.APIClient, key string, request UpdateRequest) error {
+	data := map[string]interface{}{
+		"key":     key,
+		"details": UpdateRequestToMap(request),
+	}
+	if _, err := api.PostResult[int](client, "activationkey/setDetails", data); err != nil {
+		return utils.Errorf(err, L("failed to update activation key %s"), key)
+	}
+	return nil
+}
+
+// Delete removes an activation key.
+func Delete(client *api.APIClient, key string) error {
+	data := map[string]interface{}{
+		"key": key,
+	}
+	if _, err := api.PostResult[int](client, "activationkey/delete", data); err != nil {
+		return utils.Errorf(err, L("failed to delete activation key %s"), key)
+	}
+	return nil
+}
+
+// AddChildChannels adds child channels to an activation key.
+func AddChildChannels(client *api.APIClient, key string, labels []string) error {
+	data := map[string]interface{}{
+		"key":                key,
+		"childChannelLabels": labels,
+	}
+	if _, err := api.PostResult[int](client, "activationkey/addChildChannels", data); err != nil {
+		return utils.Errorf(err, L("failed to add child channels to activation key %s"), key)
+	}
+	return nil
+}
+
+// RemoveChildChannels removes child channels from an activation key.
+func RemoveChildChannels(client *api.APIClient, key string, labels []string) error {
+	data := map[string]interface{}{
+		"key":                key,
+		"childChannelLabels": labels,
+	}
+	if _, err := api.PostResult[int](client, "activationkey/removeChildChannels", data); err != nil {
+		return utils.Errorf(err, L("failed to remove child channels from activation key %s"), key)
+	}
+	return nil
+}
+
+// AddServerGroups adds system groups to an activation key.
+func AddServerGroups(client *api.APIClient, key string, groupIDs []int) error {
+	data := map[string]interface{}{
+		"key":            key,
+		"serverGroupIds": groupIDs,
+	}
+	if _, err := api.PostResult[int](client, "activationkey/addServerGroups", data); err != nil {
+		return utils.Errorf(err, L("failed to add system groups to activation key %s"), key)
+	}
+	return nil
+}
+
+// RemoveServerGroups removes system groups from an activation key.
+func RemoveServerGroups(client *api.APIClient, key string, groupIDs []int) error {
+	data := map[string]interface{}{
+		"key":            key,
+		"serverGroupIds": groupIDs,
+	}
+	if _, err := api.PostResult[int](client, "activationkey/removeServerGroups", data); err != nil {
+		return utils.Errorf(err, L("failed to remove system groups from activation key %s"), key)
+	}
+	return nil
+}
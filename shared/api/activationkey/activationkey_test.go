@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package activationkey_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/activationkey"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils/apitest"
+)
+
+func TestGetDetails(t *testing.T) {
+	var path string
+	var query string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		query = req.URL.RawQuery
+		return testutils.GetResponse(200, `{"success": true, "result": {
+			"key": "1-sles", "description": "SLES minions", "usage_limit": 0,
+			"base_channel_label": "sles15-sp6-pool-x86_64", "child_channel_labels": ["sles15-sp6-updates-x86_64"],
+			"server_group_ids": [12], "universal_default": true, "contact_method": "default"
+		}}`)
+	})
+
+	key, err := activationkey.GetDetails(client, "1-sles")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/activationkey/getDetails", path)
+	testutils.AssertEquals(t, "Wrong query", "key=1-sles", query)
+	testutils.AssertEquals(t, "Wrong key", "1-sles", key.Key)
+	testutils.AssertEquals(t, "Wrong base channel", "sles15-sp6-pool-x86_64", key.BaseChannelLabel)
+	testutils.AssertEquals(t, "Wrong child channels", []string{"sles15-sp6-updates-x86_64"}, key.ChildChannelLabels)
+	testutils.AssertEquals(t, "Wrong groups", []int{12}, key.ServerGroupIDs)
+	testutils.AssertTrue(t, "Should be universal default", key.UniversalDefault)
+}
+
+func TestCreate(t *testing.T) {
+	var path string
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": "1-sles"}`)
+	})
+
+	key, err := activationkey.Create(client, activationkey.CreateRequest{Key: "sles", UsageLimit: 3})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/activationkey/create", path)
+	testutils.AssertEquals(t, "The prefixed key should be returned", "1-sles", key)
+	testutils.AssertEquals(t, "Wrong key", interface{}("sles"), data["key"])
+	testutils.AssertEquals(t, "Wrong usage limit", interface{}(float64(3)), data["usageLimit"])
+}
+
+func TestSetDetails(t *testing.T) {
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	err := activationkey.SetDetails(client, "1-sles", activationkey.UpdateRequest{Description: "Updated"})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong key", interface{}("1-sles"), data["key"])
+	details, ok := data["details"].(map[string]interface{})
+	testutils.AssertTrue(t, "Missing details", ok)
+	testutils.AssertEquals(t, "Wrong description", interface{}("Updated"), details["description"])
+	testutils.AssertEquals(t, "Wrong unlimited usage", interface{}(true), details["unlimited_usage_limit"])
+}
+
+func TestAddServerGroups(t *testing.T) {
+	var path string
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	err := activationkey.AddServerGroups(client, "1-sles", []int{12, 13})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/activationkey/addServerGroups", path)
+	testutils.AssertEquals(t, "Wrong groups", interface{}([]interface{}{float64(12), float64(13)}), data["serverGroupIds"])
+}
+
+func TestDeleteFailure(t *testing.T) {
+	client := apitest.NewMockAPIClient(t, func(_ *http.Request) (*http.Response, error) {
+		return testutils.GetResponse(200, `{"success": false, "message": "Could not find activation key: 1-foo"}`)
+	})
+
+	err := activationkey.Delete(client, "1-foo")
+	testutils.AssertTrue(t, "Missing error", err != nil)
+	testutils.AssertTrue(t, "Server message missing from error",
+		strings.HasSuffix(err.Error(), "Could not find activation key: 1-foo"))
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package activationkey
+
+// Mappings for the models/Schemas in scope of the activation key API
+
+// CreateRequestToMap maps the CreateRequest to a map.
+func CreateRequestToMap(request CreateRequest) map[string]interface{} {
+	entitlements := request.Entitlements
+	if entitlements == nil {
+		entitlements = []string{}
+	}
+	data := map[string]interface{}{
+		"key":              request.Key,
+		"description":      request.Description,
+		"baseChannelLabel": request.BaseChannelLabel,
+		"entitlements":     entitlements,
+		"universalDefault": request.UniversalDefault,
+	}
+	if request.UsageLimit > 0 {
+		data["usageLimit"] = request.UsageLimit
+	}
+	return data
+}
+
+// UpdateRequestToMap maps the UpdateRequest to the details map of the activationkey/setDetails endpoint.
+func UpdateRequestToMap(request UpdateRequest) map[string]interface{} {
+	data := map[string]interface{}{
+		"description":        request.Description,
+		"base_channel_label": request.BaseChannelLabel,
+		"universal_default":  request.UniversalDefault,
+		"disabled":           request.Disabled,
+	}
+	if request.UsageLimit > 0 {
+		data["usage_limit"] = request.UsageLimit
+	} else {
+		data["unlimited_usage_limit"] = true
+	}
+	if request.ContactMethod != "" {
+		data["contact_method"] = request.ContactMethod
+	}
+	return data
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package activationkey
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestCreateRequestToMap(t *testing.T) {
+	data := CreateRequestToMap(CreateRequest{
+		Key:              "sles",
+		Description:      "SLES minions",
+		BaseChannelLabel: "sles15-sp6-pool-x86_64",
+	})
+
+	expected := map[string]interface{}{
+		"key":              "sles",
+		"description":      "SLES minions",
+		"baseChannelLabel": "sles15-sp6-pool-x86_64",
+		"entitlements":     []string{},
+		"universalDefault": false,
+	}
+	testutils.AssertEquals(t, "Unexpected create data", expected, data)
+
+	data = CreateRequestToMap(CreateRequest{Key: "limited", UsageLimit: 10})
+	testutils.AssertEquals(t, "Wrong usage limit", interface{}(10), data["usageLimit"])
+}
+
+func TestUpdateRequestToMap(t *testing.T) {
+	data := UpdateRequestToMap(UpdateRequest{
+		Description:   "SLES minions",
+		Disabled:      true,
+		ContactMethod: "ssh-push",
+	})
+
+	expected := map[string]interface{}{
+		"description":           "SLES minions",
+		"base_channel_label":    "",
+		"universal_default":     false,
+		"disabled":              true,
+		"unlimited_usage_limit": true,
+		"contact_method":        "ssh-push",
+	}
+	testutils.AssertEquals(t, "Unexpected update details", expected, data)
+
+	data = UpdateRequestToMap(UpdateRequest{UsageLimit: 5})
+	testutils.AssertEquals(t, "Wrong usage limit", interface{}(5), data["usage_limit"])
+	_, unlimited := data["unlimited_usage_limit"]
+	testutils.AssertTrue(t, "Usage limit should not be unlimited", !unlimited)
+	_, hasContact := data["contact_method"]
+	testutils.AssertTrue(t, "Empty contact method should not be sent", !hasContact)
+}
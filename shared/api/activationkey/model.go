@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package activationkey
+
+// Models/Schemas for the activation key API.
+
+// ActivationKey is an activation key as returned by the API.
+type ActivationKey struct {
+	Key                string   `json:"key"`
+	Description        string   `json:"description"`
+	UsageLimit         int      `json:"usage_limit"`
+	BaseChannelLabel   string   `json:"base_channel_label"`
+	ChildChannelLabels []string `json:"child_channel_labels"`
+	Entitlements       []string `json:"entitlements"`
+	ServerGroupIDs     []int    `json:"server_group_ids"`
+	PackageNames       []string `json:"package_names"`
+	UniversalDefault   bool     `json:"universal_default"`
+	Disabled           bool     `json:"disabled"`
+	ContactMethod      string   `json:"contact_method"`
+}
+
+// CreateRequest is the request schema of the activationkey/create endpoint.
+//
+// An empty key lets the server generate one and an empty base channel means the default one.
+// A zero usage limit means unlimited.
+type CreateRequest struct {
+	Key              string
+	Description      string
+	BaseChannelLabel string
+	UsageLimit       int
+	Entitlements     []string
+	UniversalDefault bool
+}
+
+// UpdateRequest is the request schema of the activationkey/setDetails endpoint.
+type UpdateRequest struct {
+	Description      string
+	BaseChannelLabel string
+	UsageLimit       int
+	UniversalDefault bool
+	Disabled         bool
+	ContactMethod    string
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"time"
@@ -29,11 +30,22 @@ func AddAPIFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().String("api-server", "", L("FQDN of the server to connect to"))
 	cmd.PersistentFlags().String("api-user", "", L("API user username"))
 	cmd.PersistentFlags().String("api-password", "", L("Password for the API user"))
+	cmd.PersistentFlags().String("api-token", "",
+		L("API token to use instead of the user and password. Can also be set using UYUNI_API_TOKEN"),
+	)
 	cmd.PersistentFlags().String("api-cacert", "", L("Path to a cert file of the CA"))
 	cmd.PersistentFlags().Bool("api-insecure", false, L("If set, server certificate will not be checked for validity"))
+	cmd.PersistentFlags().String("context", "", L("Name of the stored API context to use instead of the current one"))
+	_ = cmd.PersistentFlags().SetAnnotation("context", utils.ConfigKeyAnnotation, []string{"api.context"})
+	cmd.PersistentFlags().Int("api-retries", 3, L("Number of retries of the API requests failing temporarily"))
+	cmd.PersistentFlags().String("api-credstore", CredStoreAuto,
+		L(`Where to store the API credentials. Possible values: 'auto', 'keyring', 'file', 'plain'.
+'auto' uses the Secret Service or kernel keyring if available, or a file encrypted with a passphrase.
+'plain' stores the session in clear text without the password.`),
+	)
 }
 
-var redactRegex = regexp.MustCompile(`(((pxt-session-cookie)|(JSESSIONID))=)[^ ";]+`)
+var redactRegex = regexp.MustCompile(`(((pxt-session-cookie)|(JSESSIONID))=|Bearer )[^ ";]+`)
 
 func redactHeaders(header string) string {
 	return redactRegex.ReplaceAllString(header, "${1}<REDACTED>")
@@ -51,11 +63,41 @@ func logTraceHeader(v *http.Header) {
 	log.Trace().Msg(redactHeaders(string(b)))
 }
 
+// sendRequest sends the request, retrying it or logging in again if needed.
 func (c *APIClient) sendRequest(req *http.Request) (*http.Response, error) {
+	res, err := c.sendRequestWithRetries(req)
+	if !c.canRelogin(req, err) {
+		return res, err
+	}
+
+	log.Info().Msg(L("Session expired, logging in again"))
+	if loginErr := c.login(); loginErr != nil {
+		return nil, utils.Errorf(loginErr, L("failed to login again after session expiration"))
+	}
+	if c.Details.InSession {
+		// Update the stored session for the next calls
+		if err := storeLoginCreds(c, false); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to store the new session"))
+		}
+	}
+	if err := rewindRequest(req); err != nil {
+		return nil, err
+	}
+	return c.sendRequestWithRetries(req)
+}
+
+// doRequest sends the request once.
+//
+// HTTP error statuses are returned as statusError.
+func (c *APIClient) doRequest(req *http.Request) (*http.Response, error) {
 	log.Debug().Msgf("Sending %s request %s", req.Method, req.URL)
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Accept", "application/json; charset=utf-8")
-	if c.AuthCookie != nil {
+	// The cookie may have changed since a previous attempt
+	req.Header.Del("Cookie")
+	if c.Details.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Details.Token)
+	} else if c.AuthCookie != nil {
 		req.AddCookie(c.AuthCookie)
 	}
 
@@ -70,28 +112,37 @@ func (c *APIClient) sendRequest(req *http.Request) (*http.Response, error) {
 	logTraceHeader(&res.Header)
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		if res.StatusCode == 401 {
-			return nil, errors.New(L("401: unauthorized"))
-		}
-		var errResponse map[string]string
-		if res.Body != nil {
-			body, err := io.ReadAll(res.Body)
-			if err == nil {
-				if err = json.Unmarshal(body, &errResponse); err == nil {
-					errorMessage := fmt.Sprintf("%d: '%s'", res.StatusCode, errResponse["message"])
-					return nil, errors.New(errorMessage)
-				}
-				errorMessage := fmt.Sprintf("%d: '%s'", res.StatusCode, string(body))
-				return nil, errors.New(errorMessage)
-			}
-		}
-		return nil, fmt.Errorf(L("unknown error: %d"), res.StatusCode)
+		return nil, newStatusError(res)
 	}
 	log.Debug().Msgf("Received response with code %d", res.StatusCode)
+	res.Body = traceBody(res.Body)
 
 	return res, nil
 }
 
+// newStatusError creates the error for an HTTP response with an error status and closes its body.
+func newStatusError(res *http.Response) error {
+	statusErr := &statusError{StatusCode: res.StatusCode}
+	if res.StatusCode == http.StatusUnauthorized {
+		statusErr.message = L("401: unauthorized")
+		return statusErr
+	}
+	statusErr.message = fmt.Sprintf(L("unknown error: %d"), res.StatusCode)
+	if res.Body != nil {
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err == nil {
+			var errResponse map[string]string
+			if err = json.Unmarshal(body, &errResponse); err == nil {
+				statusErr.message = fmt.Sprintf("%d: '%s'", res.StatusCode, errResponse["message"])
+			} else {
+				statusErr.message = fmt.Sprintf("%d: '%s'", res.StatusCode, string(body))
+			}
+		}
+	}
+	return statusErr
+}
+
 // Init returns a HTTPClient object for further API use.
 //
 // Provided connectionDetails must have Server specified with FQDN to the
@@ -100,9 +151,13 @@ func (c *APIClient) sendRequest(req *http.Request) (*http.Response, error) {
 // Optionaly connectionDetails can have user name and password set and Init
 // will try to login to the host.
 // caCert can be set to use custom CA certificate to validate target host.
+// The stored session of the context named in connectionDetails is used if any,
+// or the current context one.
 func Init(conn *ConnectionDetails) (*APIClient, error) {
 	// Load stored credentials as it also loads up server URL and CApath
-	getStoredConnectionDetails(conn)
+	if err := getStoredConnectionDetails(conn); err != nil {
+		return nil, err
+	}
 
 	caCertPool, err := x509.SystemCertPool()
 	if err != nil {
@@ -143,14 +198,19 @@ func Init(conn *ConnectionDetails) (*APIClient, error) {
 }
 
 // Login to the server using stored or provided credentials.
+//
+// Nothing is done when using an API token.
 func (c *APIClient) Login() error {
+	if c.Details.Token != "" {
+		return nil
+	}
 	if c.Details.InSession {
 		if err := c.sessionValidity(); err == nil {
 			// Session is valid
 			return nil
 		}
 		log.Warn().Msg(L("Cached session is expired."))
-		if err := RemoveLoginCreds(); err != nil {
+		if err := RemoveLoginCreds(c.Details); err != nil {
 			log.Warn().Err(err).Msg(L("Failed to remove stored credentials!"))
 		}
 	}
@@ -160,6 +220,18 @@ func (c *APIClient) Login() error {
 	return c.login()
 }
 
+// InitAndLogin creates an API client and logs in.
+func InitAndLogin(conn *ConnectionDetails) (*APIClient, error) {
+	client, err := Init(conn)
+	if err == nil {
+		err = client.Login()
+	}
+	if err != nil {
+		return nil, utils.Errorf(err, L("unable to login to the server"))
+	}
+	return client, nil
+}
+
 func (c *APIClient) login() error {
 	conn := c.Details
 	url := fmt.Sprintf("%s/%s", c.BaseURL, "auth/login")
@@ -216,7 +288,7 @@ func (c *APIClient) Logout() error {
 	if _, err := c.Post("auth/logout", nil); err != nil {
 		return utils.Errorf(err, L("failed to logout from the server"))
 	}
-	return RemoveLoginCreds()
+	return RemoveLoginCreds(c.Details)
 }
 
 // ValidateCreds checks if the login credentials are valid.
@@ -239,7 +311,7 @@ func (c *APIClient) Post(path string, data map[string]interface{}) (*http.Respon
 		return nil, err
 	}
 
-	log.Trace().Msgf("payload: %s", string(jsonData))
+	log.Trace().Msgf("payload: %s", truncateForTrace(jsonData))
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -289,13 +361,7 @@ func Post[T interface{}](client *APIClient, path string, data map[string]interfa
 	defer res.Body.Close()
 
 	var response APIResponse[T]
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	log.Trace().Msgf("response: %s", string(body))
-
-	if err = json.Unmarshal(body, &response); err != nil {
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
 		return nil, err
 	}
 
@@ -322,3 +388,37 @@ func Get[T interface{}](client *APIClient, path string) (*APIResponse[T], error)
 
 	return &response, nil
 }
+
+// PostResult issues a POST HTTP request to the API and returns the result of a successful call.
+//
+// The message of a call failing on the server side is returned as an error.
+func PostResult[T interface{}](client *APIClient, path string, data map[string]interface{}) (T, error) {
+	var result T
+	res, err := Post[T](client, path, data)
+	if err != nil {
+		return result, err
+	}
+	if !res.Success {
+		return result, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
+
+// GetResult issues an HTTP GET request to the API with the query parameters and returns the result of a
+// successful call.
+//
+// The message of a call failing on the server side is returned as an error.
+func GetResult[T interface{}](client *APIClient, path string, params url.Values) (T, error) {
+	var result T
+	if len(params) > 0 {
+		path = fmt.Sprintf("%s?%s", path, params.Encode())
+	}
+	res, err := Get[T](client, path)
+	if err != nil {
+		return result, err
+	}
+	if !res.Success {
+		return result, errors.New(res.Message)
+	}
+	return res.Result, nil
+}
@@ -4,7 +4,13 @@
 
 package api
 
-import "testing"
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
 
 func TestRedactHeaders(t *testing.T) {
 	data := [][]string{
@@ -16,6 +22,10 @@ func TestRedactHeaders(t *testing.T) {
 			`"pxt-session-cookie=supersecret; Max-Age=0;"`,
 			`"pxt-session-cookie=<REDACTED>; Max-Age=0;"`,
 		},
+		{
+			`"Authorization": ["Bearer supersecret"]`,
+			`"Authorization": ["Bearer <REDACTED>"]`,
+		},
 	}
 
 	for i, testCase := range data {
@@ -29,3 +39,33 @@ func TestRedactHeaders(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenAuthentication(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := testutils.NewFakeAPIServer(t)
+	server.AddOrg("myorg", "admin", "secret")
+	server.AddToken("mytoken", "admin")
+
+	conn := ConnectionDetails{Server: server.Host(), CApath: server.CACertFile(t), CredStore: CredStorePlain}
+	client, err := Init(&conn)
+	if err != nil {
+		t.Fatalf("failed to initialize the client: %s", err)
+	}
+	_, err = GetResult[[]string](client, "user/listAssignableRoles", nil)
+	testutils.AssertTrue(t, "Calls without token should fail", err != nil)
+
+	client.Details.Token = "mytoken"
+	if err := client.Login(); err != nil {
+		t.Fatalf("login should not be needed with a token: %s", err)
+	}
+	if _, err := GetResult[[]string](client, "user/listAssignableRoles", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client.Details.Token = "wrongtoken"
+	_, err = client.Get("user/listAssignableRoles")
+	var statusErr *statusError
+	testutils.AssertTrue(t, "Calls with a wrong token should fail with 401",
+		errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized,
+	)
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListSoftwareChannels returns the software channels visible to the user.
+func ListSoftwareChannels(client *api.APIClient) ([]ChannelSummary, error) {
+	channels, err := api.GetResult[[]ChannelSummary](client, "channel/listSoftwareChannels", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the software channels"))
+	}
+	return channels, nil
+}
+
+// GetDetails returns the details of a software channel.
+func GetDetails(client *api.APIClient, label string) (*ChannelDetails, error) {
+	details, err := api.GetResult[ChannelDetails](client, "channel/software/getDetails", labelParam(label))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the details of channel %s"), label)
+	}
+	return &details, nil
+}
+
+// ListChildren returns the child channels of a base channel.
+func ListChildren(client *api.APIClient, label string) ([]ChannelDetails, error) {
+	children, err := api.GetResult[[]ChannelDetails](client, "channel/software/listChildren", labelParam(label))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the children of channel %s"), label)
+	}
+	return children, nil
+}
+
+// ListSubscribedSystems returns the systems subscribed to a channel.
+func ListSubscribedSystems(client *api.APIClient, label string) ([]SubscribedSystem, error) {
+	systems, err := api.GetResult[[]SubscribedSystem](client, "channel/software/listSubscribedSystems",
+		labelParam(label),
+	)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the systems subscribed to channel %s"), label)
+	}
+	return systems, nil
+}
+
+// Clone clones a channel and returns the identifier of the new channel.
+//
+// If originalState is true, only the original packages and patches are cloned.
+func Clone(client *api.APIClient, label string, request CloneRequest, originalState bool) (int, error) {
+	data := map[string]interface{}{
+		"channelLabel":  label,
+		"details":       CloneRequestToMap(request),
+		"originalState": originalState,
+	}
+	id, err := api.PostResult[int](client, "channel/software/clone", data)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to clone channel %s"), label)
+	}
+	return id, nil
+}
+
+// SyncRepo triggers the synchronization of the repositories of a channel.
+func SyncRepo(client *api.APIClient, label string) error {
+	data := map[string]interface{}{
+		"channelLabel": label,
+	}
+	if _, err := api.PostResult[int](client, "channel/software/syncRepo", data); err != nil {
+		return utils.Errorf(err, L("failed to synchronize channel %s"), label)
+	}
+	return nil
+}
+
+// Delete removes a software channel.
+func Delete(client *api.APIClient, label string) error {
+	data := map[string]interface{}{
+		"channelLabel": label,
+	}
+	if _, err := api.PostResult[int](client, "channel/software/delete", data); err != nil {
+		return utils.Errorf(err, L("failed to delete channel %s"), label)
+	}
+	return nil
+}
+
+func labelParam(label string) url.Values {
+	return url.Values{"channelLabel": {label}}
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+// Mappings for the models/Schemas in scope of the channel API
+
+// CloneRequestToMap maps the CloneRequest to a map, skipping the empty values.
+func CloneRequestToMap(request CloneRequest) map[string]interface{} {
+	data := map[string]interface{}{
+		"name":  request.Name,
+		"label": request.Label,
+	}
+	optional := map[string]string{
+		"summary":      request.Summary,
+		"parent_label": request.ParentLabel,
+		"arch_label":   request.ArchLabel,
+		"description":  request.Description,
+	}
+	for key, value := range optional {
+		if value != "" {
+			data[key] = value
+		}
+	}
+	return data
+}
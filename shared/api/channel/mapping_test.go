@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestCloneRequestToMap(t *testing.T) {
+	data := CloneRequestToMap(CloneRequest{
+		Name:        "Clone of SLES",
+		Label:       "clone-sles",
+		ParentLabel: "clone-base",
+	})
+
+	expected := map[string]interface{}{
+		"name":         "Clone of SLES",
+		"label":        "clone-sles",
+		"parent_label": "clone-base",
+	}
+	testutils.AssertEquals(t, "Unexpected clone details", expected, data)
+}
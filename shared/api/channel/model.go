@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package channel
+
+// Models/Schemas for the channel API.
+
+// ChannelSummary is a software channel as returned by the channel list endpoints.
+type ChannelSummary struct {
+	Label       string `json:"label"`
+	Name        string `json:"name"`
+	ParentLabel string `json:"parent_label"`
+	EndOfLife   string `json:"end_of_life"`
+	Arch        string `json:"arch"`
+}
+
+// ContentSource is a repository synchronized into a channel.
+type ContentSource struct {
+	ID        int    `json:"id"`
+	Label     string `json:"label"`
+	SourceURL string `json:"sourceUrl"`
+	Type      string `json:"type"`
+}
+
+// ChannelDetails is the response schema of the channel/software/getDetails endpoint.
+type ChannelDetails struct {
+	ID                 int             `json:"id"`
+	Name               string          `json:"name"`
+	Label              string          `json:"label"`
+	ArchName           string          `json:"arch_name"`
+	ArchLabel          string          `json:"arch_label"`
+	Summary            string          `json:"summary"`
+	Description        string          `json:"description"`
+	ChecksumLabel      string          `json:"checksum_label"`
+	LastModified       string          `json:"last_modified"`
+	GPGKeyURL          string          `json:"gpg_key_url"`
+	EndOfLife          string          `json:"end_of_life"`
+	ParentChannelLabel string          `json:"parent_channel_label"`
+	CloneOriginal      string          `json:"clone_original"`
+	LastSync           string          `json:"yumrepo_last_sync"`
+	ContentSources     []ContentSource `json:"contentSources"`
+}
+
+// SubscribedSystem is a system subscribed to a channel.
+type SubscribedSystem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CloneRequest is the request schema of the channel/software/clone endpoint.
+//
+// Only the name and label are mandatory, the other values are taken from the original channel if empty.
+type CloneRequest struct {
+	Name        string
+	Label       string
+	Summary     string
+	ParentLabel string
+	ArchLabel   string
+	Description string
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configchannel
+
+import (
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListGlobals returns the global configuration channels.
+func ListGlobals(client *api.APIClient) ([]ConfigChannel, error) {
+	channels, err := api.GetResult[[]ConfigChannel](client, "configchannel/listGlobals", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the configuration channels"))
+	}
+	return channels, nil
+}
+
+// ChannelExists returns whether a configuration channel exists.
+func ChannelExists(client *api.APIClient, label string) (bool, error) {
+	exists, err := api.GetResult[int](client, "configchannel/channelExists", labelParam(label))
+	if err != nil {
+		return false, utils.Errorf(err, L("failed to check if configuration channel %s exists"), label)
+	}
+	return exists == 1, nil
+}
+
+// GetDetails returns a configuration channel.
+func GetDetails(client *api.APIClient, label string) (*ConfigChannel, error) {
+	channel, err := api.GetResult[ConfigChannel](client, "configchannel/getDetails", labelParam(label))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the details of configuration channel %s"), label)
+	}
+	return &channel, nil
+}
+
+// Create creates a configuration channel.
+//
+// channelType is one of normal, state or an empty string for the default.
+func Create(client *api.APIClient, label string, name string, description string, channelType string) (
+	*ConfigChannel, error,
+) {
+	data := map[string]interface{}{
+		"label":       label,
+		"name":        name,
+		"description": description,
+	}
+	if channelType != "" {
+		data["type"] = channelType
+	}
+	channel, err := api.PostResult[ConfigChannel](client, "configchannel/create", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to create configuration channel %s"), label)
+	}
+	return &channel, nil
+}
+
+// Update changes the name and description of a configuration channel.
+func Update(client *api.APIClient, label string, name string, description string) (*ConfigChannel, error) {
+	data := map[string]interface{}{
+		"label":       label,
+		"name":        name,
+		"description": description,
+	}
+	channel, err := api.PostResult[ConfigChannel](client, "configchannel/update", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to update configuration channel %s"), label)
+	}
+	return &channel, nil
+}
+
+// DeleteChannels removes configuration channels.
+func DeleteChannels(client *api.APIClient, labels []string) error {
+	data := map[string]interface{}{
+		"labels": labels,
+	}
+	if _, err := api.PostResult[int](client, "configchannel/deleteChannels", data); err != nil {
+		return utils.Errorf(err, L("failed to delete the configuration channels"))
+	}
+	return nil
+}
+
+// ListFiles returns the files of a configuration channel.
+func ListFiles(client *api.APIClient, label string) ([]FileInfo, error) {
+	files, err := api.GetResult[[]FileInfo](client, "configchannel/listFiles", labelParam(label))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the files of configuration channel %s"), label)
+	}
+	return files, nil
+}
+
+func labelParam(label string) url.Values {
+	return url.Values{"label": {label}}
+}
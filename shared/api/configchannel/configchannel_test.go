@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configchannel_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/configchannel"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils/apitest"
+)
+
+func TestListGlobals(t *testing.T) {
+	var path string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return testutils.GetResponse(200, `{"success": true, "result": [
+			{"id": 1, "orgId": 1, "label": "motd", "name": "MOTD", "description": "Message of the day",
+				"type": {"id": 1, "label": "normal", "name": "Normal", "priority": 1}}
+		]}`)
+	})
+
+	channels, err := configchannel.ListGlobals(client)
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/configchannel/listGlobals", path)
+	testutils.AssertEquals(t, "Wrong channels count", 1, len(channels))
+	testutils.AssertEquals(t, "Wrong label", "motd", channels[0].Label)
+	testutils.AssertEquals(t, "Wrong type label", "normal", channels[0].TypeLabel())
+}
+
+func TestChannelExists(t *testing.T) {
+	var query string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	exists, err := configchannel.ChannelExists(client, "motd")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong query", "label=motd", query)
+	testutils.AssertTrue(t, "Channel should exist", exists)
+}
+
+func TestCreate(t *testing.T) {
+	var path string
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		data = nil
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": {
+			"id": 2, "orgId": 1, "label": "states", "name": "States", "description": "Salt states",
+			"configChannelType": {"id": 3, "label": "state", "name": "State", "priority": 1}
+		}}`)
+	})
+
+	channel, err := configchannel.Create(client, "states", "States", "Salt states", "state")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/configchannel/create", path)
+	expected := map[string]interface{}{
+		"label":       "states",
+		"name":        "States",
+		"description": "Salt states",
+		"type":        "state",
+	}
+	testutils.AssertEquals(t, "Wrong data", expected, data)
+	testutils.AssertEquals(t, "Wrong type label", "state", channel.TypeLabel())
+
+	_, err = configchannel.Create(client, "files", "Files", "", "")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	_, hasType := data["type"]
+	testutils.AssertTrue(t, "Empty type should not be sent", !hasType)
+}
+
+func TestDeleteChannels(t *testing.T) {
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	err := configchannel.DeleteChannels(client, []string{"motd", "states"})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong labels", interface{}([]interface{}{"motd", "states"}), data["labels"])
+}
+
+func TestListFiles(t *testing.T) {
+	var path string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return testutils.GetResponse(200, `{"success": true, "result": [
+			{"type": "file", "path": "/etc/motd", "last_modified": "2025-03-01T10:00:00Z"}
+		]}`)
+	})
+
+	files, err := configchannel.ListFiles(client, "motd")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/configchannel/listFiles", path)
+	testutils.AssertEquals(t, "Wrong files count", 1, len(files))
+	testutils.AssertEquals(t, "Wrong path", "/etc/motd", files[0].Path)
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configchannel
+
+// Models/Schemas for the configuration channel API.
+
+// ChannelType describes the kind of a configuration channel.
+type ChannelType struct {
+	ID       int    `json:"id"`
+	Label    string `json:"label"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+}
+
+// ConfigChannel is a configuration channel as returned by the API.
+type ConfigChannel struct {
+	ID          int    `json:"id"`
+	OrgID       int    `json:"orgId"`
+	Label       string `json:"label"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Type is set by the configchannel/listGlobals endpoint.
+	Type *ChannelType `json:"type,omitempty"`
+	// ConfigChannelType is set by the other endpoints.
+	ConfigChannelType *ChannelType `json:"configChannelType,omitempty"`
+}
+
+// TypeLabel returns the label of the channel type, whichever endpoint returned the channel.
+func (c *ConfigChannel) TypeLabel() string {
+	if c.ConfigChannelType != nil {
+		return c.ConfigChannelType.Label
+	}
+	if c.Type != nil {
+		return c.Type.Label
+	}
+	return ""
+}
+
+// FileInfo is a file of a configuration channel.
+type FileInfo struct {
+	Type         string `json:"type"`
+	Path         string `json:"path"`
+	LastModified string `json:"last_modified"`
+}
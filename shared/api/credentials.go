@@ -5,8 +5,10 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path"
 
@@ -15,44 +17,235 @@ import (
 	"github.com/uyuni-project/uyuni-tools/shared/utils"
 )
 
+// defaultContext is the name of the context used when none is provided.
+const defaultContext = "default"
+
+// Names of the credentials stores.
+const (
+	// CredStoreAuto uses the keyring if available or the encrypted file.
+	CredStoreAuto = "auto"
+	// CredStoreKeyring uses the Secret Service or the kernel keyring.
+	CredStoreKeyring = "keyring"
+	// CredStoreFile uses a file encrypted with a passphrase.
+	CredStoreFile = "file"
+	// CredStorePlain uses a plain JSON file, the passwords are not stored in it.
+	CredStorePlain = "plain"
+)
+
+// errNotStored is returned by the credentials backends when nothing is stored.
+// It is never shown to the user.
+// l10n-ignore
+var errNotStored = errors.New("no stored credentials")
+
+// undecodableError is returned when the stored credentials are corrupted.
+type undecodableError struct {
+	err error
+}
+
+// Error returns the message of the decoding error.
+func (e *undecodableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the decoding error.
+func (e *undecodableError) Unwrap() error {
+	return e.err
+}
+
+// isUndecodable returns whether the error is about corrupted credentials, in which case they can be overwritten.
+//
+// Other errors like a wrong passphrase need to be reported to not lose the stored credentials.
+func isUndecodable(err error) bool {
+	var undecodable *undecodableError
+	return errors.As(err, &undecodable)
+}
+
+// credentialsBackend stores the serialized API credentials.
+type credentialsBackend interface {
+	// String returns a description of the backend for the messages.
+	String() string
+	// exists returns whether credentials are stored.
+	exists() bool
+	// load returns the stored credentials or errNotStored.
+	load() ([]byte, error)
+	// store replaces the stored credentials.
+	store(data []byte) error
+	// remove deletes the stored credentials.
+	remove() error
+	// secure returns whether the backend can hold passwords.
+	secure() bool
+}
+
+// newCredentialsBackend returns the backend for the credentials store name.
+//
+// If interactive is false, the backends never prompt for a passphrase.
+func newCredentialsBackend(store string, interactive bool) (credentialsBackend, error) {
+	switch store {
+	case "", CredStoreAuto:
+		if backend := newKeyringBackend(); backend != nil {
+			return backend, nil
+		}
+		return newEncryptedFileBackend(getAPIEncryptedCredsFile(), interactive), nil
+	case CredStoreKeyring:
+		if backend := newKeyringBackend(); backend != nil {
+			return backend, nil
+		}
+		return nil, errors.New(L("neither the Secret Service nor the kernel keyring is available"))
+	case CredStoreFile:
+		return newEncryptedFileBackend(getAPIEncryptedCredsFile(), interactive), nil
+	case CredStorePlain:
+		return &plainFileBackend{path: getAPICredsFile()}, nil
+	}
+	return nil, fmt.Errorf(L("unsupported credentials store: %s"), store)
+}
+
+// credentialsBackend returns the backend of the connection credentials store, creating it on first use.
+func (c *ConnectionDetails) credentialsBackend() (credentialsBackend, error) {
+	if c.backend == nil {
+		backend, err := newCredentialsBackend(c.CredStore, !c.NonInteractive)
+		if err != nil {
+			return nil, err
+		}
+		log.Debug().Msgf("Using %s to store the API credentials", backend)
+		c.backend = backend
+	}
+	return c.backend, nil
+}
+
 // StoreLoginCreds stores the API credentials for future API use.
+//
+// The credentials are stored in the context of the connection details.
+// If not set, the context of the server or the current one is used, unless the current context
+// is for another server: a new context named after the server is then created.
+// The stored context becomes the current one.
 func StoreLoginCreds(client *APIClient) error {
+	return storeLoginCreds(client, true)
+}
+
+func storeLoginCreds(client *APIClient, makeCurrent bool) error {
 	if client.AuthCookie.Value == "" {
 		return errors.New(L("not logged in, session cookie is missing"))
 	}
-	// Future: Add support for more servers if needed in the future
-	auth := []authStorage{
-		{
-			Session: client.AuthCookie.Value,
-			Server:  client.Details.Server,
-			CApath:  client.Details.CApath,
-		},
+
+	backend, err := client.Details.credentialsBackend()
+	if err != nil {
+		return err
+	}
+	config, err := readAuthConfig(backend)
+	if isUndecodable(err) {
+		log.Warn().Err(err).Msg(L("Cannot load stored credentials, overwriting them"))
+		config = &authConfig{}
+	} else if err != nil {
+		return err
+	}
+
+	name := client.Details.Context
+	if name == "" {
+		name = config.contextNameFor(client.Details.Server)
+	}
+	auth := authStorage{
+		Name:     name,
+		Session:  client.AuthCookie.Value,
+		Server:   client.Details.Server,
+		CApath:   client.Details.CApath,
+		User:     client.Details.User,
+		Password: client.Details.Password,
+	}
+	if existing := config.find(name); existing != nil {
+		*existing = auth
+	} else {
+		config.Contexts = append(config.Contexts, auth)
+	}
+	if makeCurrent || config.CurrentContext == "" {
+		config.CurrentContext = name
 	}
+	client.Details.Context = name
 
-	authData, err := json.Marshal(auth)
+	return writeAuthConfig(backend, config)
+}
+
+// RemoveLoginCreds removes the stored API credentials of the connection context, the current one if empty.
+//
+// The stored credentials are removed with the last context.
+func RemoveLoginCreds(conn *ConnectionDetails) error {
+	backend, err := conn.credentialsBackend()
 	if err != nil {
-		return utils.Errorf(err, L("unable to create credentials json"))
+		return err
+	}
+	config, err := readAuthConfig(backend)
+	if isUndecodable(err) {
+		// The corrupted credentials have been removed while reading them
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	name := config.resolveName(conn.Context)
+	contexts := []authStorage{}
+	for _, auth := range config.Contexts {
+		if auth.Name != name {
+			contexts = append(contexts, auth)
+		}
+	}
+	if len(contexts) == len(config.Contexts) {
+		return fmt.Errorf(L("no stored API context named %s"), name)
 	}
+	if len(contexts) == 0 {
+		return backend.remove()
+	}
+	config.Contexts = contexts
+	if config.CurrentContext == name {
+		config.CurrentContext = ""
+	}
+	return writeAuthConfig(backend, config)
+}
 
-	err = os.WriteFile(getAPICredsFile(), authData, 0600)
+// ListContexts returns the API contexts stored in the connection credentials store.
+func ListContexts(conn *ConnectionDetails) ([]Context, error) {
+	backend, err := conn.credentialsBackend()
 	if err != nil {
-		return utils.Errorf(err, L("unable to write credentials store %s"), getAPICredsFile())
+		return nil, err
 	}
-	return nil
+	config, err := readAuthConfig(backend)
+	if err != nil {
+		return nil, err
+	}
+	contexts := []Context{}
+	for _, auth := range config.Contexts {
+		contexts = append(contexts, Context{
+			Name:    auth.Name,
+			Server:  auth.Server,
+			Current: auth.Name == config.CurrentContext,
+		})
+	}
+	return contexts, nil
 }
 
-// RemoveLoginCreds removes the stored API credentials.
-func RemoveLoginCreds() error {
-	// Future: Multi-server support will need some parsing here
-	return os.Remove(getAPICredsFile())
+// UseContext changes the current API context.
+func UseContext(conn *ConnectionDetails, name string) error {
+	backend, err := conn.credentialsBackend()
+	if err != nil {
+		return err
+	}
+	config, err := readAuthConfig(backend)
+	if err != nil {
+		return err
+	}
+	if config.find(name) == nil {
+		return fmt.Errorf(L("no stored API context named %s"), name)
+	}
+	config.CurrentContext = name
+	return writeAuthConfig(backend, config)
 }
 
 // Asks for not provided ConnectionDetails or errors out.
 func getLoginCredentials(conn *ConnectionDetails) error {
 	// If user name provided, but no password and not loaded
-	utils.AskIfMissing(&conn.Server, L("API server URL"), 0, 0, nil)
-	utils.AskIfMissing(&conn.User, L("API server user"), 0, 0, nil)
-	utils.AskPasswordIfMissingOnce(&conn.Password, L("API server password"), 0, 0)
+	if !conn.NonInteractive {
+		utils.AskIfMissing(&conn.Server, L("API server URL"), 0, 0, nil)
+		utils.AskIfMissing(&conn.User, L("API server user"), 0, 0, nil)
+		utils.AskPasswordIfMissingOnce(&conn.Password, L("API server password"), 0, 0)
+	}
 
 	if conn.User == "" || conn.Password == "" {
 		return errors.New(L("No credentials provided"))
@@ -62,61 +255,266 @@ func getLoginCredentials(conn *ConnectionDetails) error {
 }
 
 // Fills ConnectionDetails with cached credentials if possible.
-func getStoredConnectionDetails(conn *ConnectionDetails) {
-	if IsAlreadyLoggedIn() && conn.User == "" {
-		if err := loadLoginCreds(conn); err != nil {
-			log.Warn().Err(err).Msg(L("Cannot load stored credentials"))
-			if err := RemoveLoginCreds(); err != nil {
-				log.Warn().Err(err).Msg(L("Failed to remove stored credentials!"))
-			}
-		} else {
-			// We have connection cookie
-			conn.InSession = true
+//
+// An error is only returned if the requested context is not stored.
+// Nothing is loaded if a user or an API token is provided.
+func getStoredConnectionDetails(conn *ConnectionDetails) error {
+	if conn.User != "" || conn.Token != "" {
+		return nil
+	}
+	backend, err := conn.credentialsBackend()
+	if err != nil {
+		return err
+	}
+	if !backend.exists() && !utils.FileExists(getAPICredsFile()) {
+		return nil
+	}
+	if err := loadLoginCreds(conn); err != nil {
+		if conn.Context != "" {
+			return err
 		}
+		log.Debug().Err(err).Msg("Not using stored credentials")
+		return nil
 	}
+	// We have connection cookie
+	conn.InSession = true
+	return nil
 }
 
 // Read stored session and server details.
+//
+// The context is the one from the connection details, the one matching the server or the current one.
 func loadLoginCreds(connection *ConnectionDetails) error {
-	data, err := os.ReadFile(getAPICredsFile())
+	backend, err := connection.credentialsBackend()
 	if err != nil {
-		return utils.Errorf(err, L("unable to read credentials file %s"), getAPICredsFile())
+		return err
 	}
-	authStore := []authStorage{}
-	err = json.Unmarshal(data, &authStore)
+	config, err := readAuthConfig(backend)
 	if err != nil {
-		return utils.Errorf(err, L("unable to decode credentials file"))
+		return err
 	}
 
-	if len(authStore) == 0 {
-		return errors.New(L("no credentials loaded"))
+	var authData *authStorage
+	if connection.Context != "" {
+		authData = config.find(connection.Context)
+		if authData == nil {
+			return fmt.Errorf(L("no stored API context named %s"), connection.Context)
+		}
+	} else if connection.Server != "" {
+		authData = config.findServer(connection.Server)
+		if authData == nil {
+			return errors.New(L("specified api server does not match with stored credentials"))
+		}
+	} else {
+		authData = config.find(config.resolveName(""))
+		if authData == nil {
+			return errors.New(L("no credentials loaded"))
+		}
 	}
 
-	// Currently we support storing data only to one server
-	// Future: add support for more servers if wanted
-
-	authData := authStore[0]
-
 	if connection.Server != "" && connection.Server != authData.Server {
 		return errors.New(L("specified api server does not match with stored credentials"))
 	}
+	connection.Context = authData.Name
 	connection.Server = authData.Server
 	if authData.CApath != "" {
 		connection.CApath = authData.CApath
 	}
+	// The user and password are needed to login again once the session expired.
+	// A password passed through the flags or environment is kept, for stores not saving it.
+	if connection.User == "" {
+		connection.User = authData.User
+		if connection.Password == "" {
+			connection.Password = authData.Password
+		}
+	}
 
 	connection.Cookie = authData.Session
 
 	return nil
 }
 
-// IsAlreadyLoggedIn returns true if credentials file already exists.
+// IsAlreadyLoggedIn returns true if credentials are stored for the connection context.
 //
+// If no context is set, the one StoreLoginCreds would use for the server is checked.
 // Does not check for credentials validity.
-func IsAlreadyLoggedIn() bool {
-	return utils.FileExists(getAPICredsFile())
+func IsAlreadyLoggedIn(conn *ConnectionDetails) bool {
+	backend, err := conn.credentialsBackend()
+	if err != nil {
+		return false
+	}
+	config, err := readAuthConfig(backend)
+	if err != nil {
+		return false
+	}
+	name := conn.Context
+	if name == "" {
+		name = config.contextNameFor(conn.Server)
+	}
+	return config.find(name) != nil
 }
 
 func getAPICredsFile() string {
 	return path.Join(utils.GetUserConfigDir(), apiCredentialsStore)
 }
+
+func getAPIEncryptedCredsFile() string {
+	return path.Join(utils.GetUserConfigDir(), apiEncryptedCredentialsStore)
+}
+
+// readAuthConfig reads the stored credentials, converting the single server format if needed.
+//
+// Nothing stored results in an empty configuration.
+// Credentials from the plain JSON file are moved to the backend if it is a secure one.
+// If that requires asking for a passphrase, the plain file is read instead
+// until the credentials are written again.
+// Credentials that cannot be decoded are removed.
+func readAuthConfig(backend credentialsBackend) (*authConfig, error) {
+	if backend.secure() && !backend.exists() && utils.FileExists(getAPICredsFile()) {
+		if fileBackend, ok := backend.(*encryptedFileBackend); ok && !fileBackend.hasPassphrase() {
+			log.Debug().Msgf("Reading the API credentials from %s, set %s to move them to %s",
+				getAPICredsFile(), PassphraseEnv, backend,
+			)
+			backend = &plainFileBackend{path: getAPICredsFile()}
+		} else if err := migratePlainCredentials(backend); err != nil {
+			log.Warn().Err(err).Msgf(L("Failed to move the stored API credentials to %s"), backend)
+		}
+	}
+
+	config := authConfig{}
+	data, err := backend.load()
+	if errors.Is(err, errNotStored) {
+		return &config, nil
+	}
+	if err == nil {
+		err = decodeAuthConfig(data, &config)
+	}
+	if isUndecodable(err) {
+		log.Warn().Err(err).Msg(L("Cannot load stored credentials"))
+		if err := backend.remove(); err != nil {
+			log.Warn().Err(err).Msg(L("Failed to remove stored credentials!"))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func decodeAuthConfig(data []byte, config *authConfig) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		// Older versions stored the credentials of a single server in a list
+		if err := json.Unmarshal(data, &config.Contexts); err != nil {
+			return &undecodableError{utils.Errorf(err, L("unable to decode credentials file"))}
+		}
+		for i := range config.Contexts {
+			if config.Contexts[i].Name == "" {
+				config.Contexts[i].Name = defaultContext
+			}
+		}
+		if len(config.Contexts) > 0 {
+			config.CurrentContext = config.Contexts[0].Name
+		}
+		return nil
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return &undecodableError{utils.Errorf(err, L("unable to decode credentials file"))}
+	}
+	return nil
+}
+
+// migratePlainCredentials moves the credentials from the plain JSON file to a secure backend.
+func migratePlainCredentials(backend credentialsBackend) error {
+	plain := plainFileBackend{path: getAPICredsFile()}
+	data, err := plain.load()
+	if err != nil {
+		return err
+	}
+	if err := backend.store(data); err != nil {
+		return err
+	}
+	log.Info().Msgf(L("Moved the stored API credentials from %[1]s to %[2]s"), getAPICredsFile(), backend)
+	return plain.remove()
+}
+
+func writeAuthConfig(backend credentialsBackend, config *authConfig) error {
+	toStore := *config
+	if !backend.secure() {
+		toStore.Contexts = []authStorage{}
+		for _, auth := range config.Contexts {
+			auth.Password = ""
+			toStore.Contexts = append(toStore.Contexts, auth)
+		}
+	}
+
+	authData, err := json.Marshal(toStore)
+	if err != nil {
+		return utils.Errorf(err, L("unable to create credentials json"))
+	}
+	if err := backend.store(authData); err != nil {
+		return err
+	}
+
+	// The credentials read from the plain file, if any, are now in the secure backend
+	if backend.secure() && utils.FileExists(getAPICredsFile()) {
+		if err := os.Remove(getAPICredsFile()); err != nil {
+			log.Warn().Err(err).Msgf(L("Failed to remove %s"), getAPICredsFile())
+		}
+	}
+	return nil
+}
+
+// resolveName returns the context name or the current one if empty.
+func (c *authConfig) resolveName(name string) string {
+	if name != "" {
+		return name
+	}
+	if c.CurrentContext != "" {
+		return c.CurrentContext
+	}
+	return defaultContext
+}
+
+// contextNameFor returns the name of the context to store the credentials of a server when none is given.
+//
+// The context of the server is reused if any, then the current one if it has no server or the same one.
+// Otherwise a new context named after the server is used to keep the credentials of the other servers.
+func (c *authConfig) contextNameFor(server string) string {
+	if server != "" {
+		if auth := c.findServer(server); auth != nil {
+			return auth.Name
+		}
+	}
+	current := c.find(c.resolveName(""))
+	if server == "" || current == nil || current.Server == "" {
+		return c.resolveName("")
+	}
+	name := server
+	for i := 2; c.find(name) != nil; i++ {
+		name = fmt.Sprintf("%s-%d", server, i)
+	}
+	return name
+}
+
+func (c *authConfig) find(name string) *authStorage {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
+
+func (c *authConfig) findServer(server string) *authStorage {
+	// Prefer the current context if several ones point to the same server
+	if current := c.find(c.CurrentContext); current != nil && current.Server == server {
+		return current
+	}
+	for i := range c.Contexts {
+		if c.Contexts[i].Server == server {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
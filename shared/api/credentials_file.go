@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase of the encrypted credentials file.
+const PassphraseEnv = "UYUNI_API_PASSPHRASE"
+
+// scrypt parameters recommended for interactive logins.
+const (
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltSize     = 16
+)
+
+// plainFileBackend stores the credentials in a JSON file readable by the user only.
+type plainFileBackend struct {
+	path string
+}
+
+func (b *plainFileBackend) String() string {
+	return b.path
+}
+
+func (b *plainFileBackend) exists() bool {
+	return utils.FileExists(b.path)
+}
+
+func (b *plainFileBackend) load() ([]byte, error) {
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNotStored
+	}
+	if err != nil {
+		return nil, utils.Errorf(err, L("unable to read credentials file %s"), b.path)
+	}
+	return data, nil
+}
+
+func (b *plainFileBackend) store(data []byte) error {
+	if err := os.WriteFile(b.path, data, 0600); err != nil {
+		return utils.Errorf(err, L("unable to write credentials store %s"), b.path)
+	}
+	return nil
+}
+
+func (b *plainFileBackend) remove() error {
+	return os.Remove(b.path)
+}
+
+func (b *plainFileBackend) secure() bool {
+	return false
+}
+
+// encryptedFileBackend stores the credentials in a file encrypted using a key derived from a passphrase.
+//
+// The passphrase is read from the UYUNI_API_PASSPHRASE environment variable or asked interactively.
+type encryptedFileBackend struct {
+	plainFileBackend
+	passphrase  string
+	interactive bool
+}
+
+// encryptedCredentials is the content of the encrypted credentials file.
+type encryptedCredentials struct {
+	Version int
+	Salt    []byte
+	Nonce   []byte
+	Data    []byte
+}
+
+func newEncryptedFileBackend(path string, interactive bool) *encryptedFileBackend {
+	return &encryptedFileBackend{plainFileBackend: plainFileBackend{path: path}, interactive: interactive}
+}
+
+func (b *encryptedFileBackend) String() string {
+	return fmt.Sprintf(L("encrypted file %s"), b.path)
+}
+
+func (b *encryptedFileBackend) load() ([]byte, error) {
+	content, err := b.plainFileBackend.load()
+	if err != nil {
+		return nil, err
+	}
+	var encrypted encryptedCredentials
+	if err := json.Unmarshal(content, &encrypted); err != nil {
+		return nil, &undecodableError{utils.Errorf(err, L("unable to decode credentials file"))}
+	}
+
+	passphrase, err := b.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newCipher(passphrase, encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, encrypted.Nonce, encrypted.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf(L("failed to decrypt %s: wrong passphrase?"), b.path)
+	}
+	return data, nil
+}
+
+func (b *encryptedFileBackend) store(data []byte) error {
+	passphrase, err := b.getPassphrase(!b.exists())
+	if err != nil {
+		return err
+	}
+
+	encrypted := encryptedCredentials{
+		Version: 1,
+		Salt:    make([]byte, saltSize),
+	}
+	if _, err := rand.Read(encrypted.Salt); err != nil {
+		return err
+	}
+	aead, err := newCipher(passphrase, encrypted.Salt)
+	if err != nil {
+		return err
+	}
+	encrypted.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(encrypted.Nonce); err != nil {
+		return err
+	}
+	encrypted.Data = aead.Seal(nil, encrypted.Nonce, data, nil)
+
+	content, err := json.Marshal(encrypted)
+	if err != nil {
+		return utils.Errorf(err, L("unable to create credentials json"))
+	}
+	return b.plainFileBackend.store(content)
+}
+
+func (b *encryptedFileBackend) secure() bool {
+	return true
+}
+
+// hasPassphrase returns whether the passphrase is known without asking it.
+func (b *encryptedFileBackend) hasPassphrase() bool {
+	return b.passphrase != "" || os.Getenv(PassphraseEnv) != ""
+}
+
+// getPassphrase returns the passphrase from the environment or asks it if interactive.
+//
+// Set confirm to ask for the passphrase twice when creating the file.
+func (b *encryptedFileBackend) getPassphrase(confirm bool) (string, error) {
+	if b.passphrase == "" {
+		b.passphrase = os.Getenv(PassphraseEnv)
+	}
+	if b.passphrase == "" && b.interactive {
+		prompt := L("Passphrase of the API credentials file")
+		if confirm {
+			utils.AskPasswordIfMissing(&b.passphrase, prompt, 0, 0)
+		} else {
+			utils.AskPasswordIfMissingOnce(&b.passphrase, prompt, 0, 0)
+		}
+	}
+	if b.passphrase == "" {
+		return "", fmt.Errorf(L("a passphrase is required for the API credentials file, it can be set in %s"),
+			PassphraseEnv,
+		)
+	}
+	return b.passphrase, nil
+}
+
+func newCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+func storeTestSession(t *testing.T, store string) {
+	client := APIClient{
+		Details: &ConnectionDetails{
+			User:      user,
+			Password:  password,
+			Server:    server,
+			CredStore: store,
+		},
+		AuthCookie: &http.Cookie{Name: "pxt-session-cookie", Value: cookie},
+	}
+	if err := StoreLoginCreds(&client); err != nil {
+		t.Fatalf("failed to store the credentials: %s", err)
+	}
+}
+
+func TestEncryptedCredentials(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "passphrase")
+	storeTestSession(t, CredStoreFile)
+
+	content, err := os.ReadFile(getAPIEncryptedCredsFile())
+	if err != nil {
+		t.Fatalf("failed to read the encrypted file: %s", err)
+	}
+	testutils.AssertTrue(t, "Session cookie in clear text", !strings.Contains(string(content), cookie))
+	testutils.AssertTrue(t, "Password in clear text", !strings.Contains(string(content), password))
+
+	connection := ConnectionDetails{CredStore: CredStoreFile}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load the credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cookie", cookie, connection.Cookie)
+	testutils.AssertEquals(t, "Wrong user", user, connection.User)
+	testutils.AssertEquals(t, "Wrong password", password, connection.Password)
+
+	// A wrong passphrase does not remove the credentials
+	t.Setenv(PassphraseEnv, "wrong")
+	connection = ConnectionDetails{CredStore: CredStoreFile}
+	testutils.AssertTrue(t, "Expected wrong passphrase error", loadLoginCreds(&connection) != nil)
+	testutils.AssertTrue(t, "Encrypted file removed", utils.FileExists(getAPIEncryptedCredsFile()))
+}
+
+func TestEncryptedCredentialsNonInteractive(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "passphrase")
+	storeTestSession(t, CredStoreFile)
+
+	t.Setenv(PassphraseEnv, "")
+	connection := ConnectionDetails{CredStore: CredStoreFile, NonInteractive: true}
+	err := loadLoginCreds(&connection)
+	testutils.AssertTrue(t, "Expected a missing passphrase error",
+		err != nil && strings.Contains(err.Error(), PassphraseEnv),
+	)
+}
+
+func TestPlainCredentialsWithoutPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	storeTestSession(t, CredStorePlain)
+
+	content, err := os.ReadFile(getAPICredsFile())
+	if err != nil {
+		t.Fatalf("failed to read the credentials file: %s", err)
+	}
+	testutils.AssertTrue(t, "Password stored in plain file", !strings.Contains(string(content), password))
+}
+
+func TestMigratePlainCredentials(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "passphrase")
+	legacy := fmt.Sprintf(`[{"Session": "%s", "Server": "%s", "CApath": ""}]`, cookie, server)
+	if err := os.WriteFile(getAPICredsFile(), []byte(legacy), 0600); err != nil {
+		t.Fatalf("failed to write legacy credentials: %s", err)
+	}
+
+	connection := ConnectionDetails{CredStore: CredStoreFile}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load the credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cookie", cookie, connection.Cookie)
+	testutils.AssertTrue(t, "Plain file not removed", !utils.FileExists(getAPICredsFile()))
+	testutils.AssertTrue(t, "Encrypted file not created", utils.FileExists(getAPIEncryptedCredsFile()))
+}
+
+func TestMigratePlainCredentialsWithoutPassphrase(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "")
+	legacy := fmt.Sprintf(`[{"Session": "%s", "Server": "%s", "CApath": ""}]`, cookie, server)
+	if err := os.WriteFile(getAPICredsFile(), []byte(legacy), 0600); err != nil {
+		t.Fatalf("failed to write legacy credentials: %s", err)
+	}
+
+	// Reading the credentials must not ask for a passphrase
+	connection := ConnectionDetails{CredStore: CredStoreFile}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load the credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cookie", cookie, connection.Cookie)
+	testutils.AssertTrue(t, "Plain file removed", utils.FileExists(getAPICredsFile()))
+	testutils.AssertTrue(t, "Encrypted file created", !utils.FileExists(getAPIEncryptedCredsFile()))
+
+	// The plain file is removed once the credentials are stored in the encrypted file,
+	// like after asking the passphrase at the next login
+	backend := newEncryptedFileBackend(getAPIEncryptedCredsFile(), false)
+	config, err := readAuthConfig(backend)
+	if err != nil {
+		t.Fatalf("failed to read the credentials: %s", err)
+	}
+	backend.passphrase = "passphrase"
+	if err := writeAuthConfig(backend, config); err != nil {
+		t.Fatalf("failed to write the credentials: %s", err)
+	}
+	testutils.AssertTrue(t, "Plain file not removed", !utils.FileExists(getAPICredsFile()))
+	testutils.AssertTrue(t, "Encrypted file not created", utils.FileExists(getAPIEncryptedCredsFile()))
+}
+
+func TestEncryptedCredentialsWrongPassphraseUpdate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "passphrase")
+	storeTestSession(t, CredStoreFile)
+
+	// Neither a login nor a logout with a wrong passphrase can replace the stored contexts
+	t.Setenv(PassphraseEnv, "wrong")
+	client := APIClient{
+		Details:    &ConnectionDetails{Server: "other.example.com", CredStore: CredStoreFile},
+		AuthCookie: &http.Cookie{Name: "pxt-session-cookie", Value: "othercookie"},
+	}
+	testutils.AssertTrue(t, "Expected wrong passphrase error on login", StoreLoginCreds(&client) != nil)
+	err := RemoveLoginCreds(&ConnectionDetails{CredStore: CredStoreFile})
+	testutils.AssertTrue(t, "Expected wrong passphrase error on logout", err != nil)
+
+	t.Setenv(PassphraseEnv, "passphrase")
+	connection := ConnectionDetails{CredStore: CredStoreFile, Server: server}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load the credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cookie", cookie, connection.Cookie)
+}
+
+func TestCorruptedEncryptedCredentialsOverwritten(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "passphrase")
+	if err := os.WriteFile(getAPIEncryptedCredsFile(), []byte("garbage"), 0600); err != nil {
+		t.Fatalf("failed to write the credentials file: %s", err)
+	}
+
+	storeTestSession(t, CredStoreFile)
+	connection := ConnectionDetails{CredStore: CredStoreFile}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load the credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cookie", cookie, connection.Cookie)
+}
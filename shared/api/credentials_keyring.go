@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+	"golang.org/x/sys/unix"
+)
+
+// keyringDescription identifies the API credentials in the keyrings.
+const keyringDescription = "uyuni-tools:api-credentials"
+
+// newKeyringBackend returns the Secret Service or kernel keyring backend, or nil if none is available.
+func newKeyringBackend() credentialsBackend {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" && utils.IsInstalled("secret-tool") {
+		return &secretServiceBackend{}
+	}
+	backend, err := newKernelKeyringBackend()
+	if err != nil {
+		log.Debug().Err(err).Msg("Kernel keyring not available")
+		return nil
+	}
+	return backend
+}
+
+// secretServiceBackend stores the credentials in the desktop keyring using the secret-tool command.
+type secretServiceBackend struct{}
+
+var secretServiceAttributes = []string{"service", "uyuni-tools", "type", "api-credentials"}
+
+func (b *secretServiceBackend) String() string {
+	return L("Secret Service keyring")
+}
+
+func (b *secretServiceBackend) exists() bool {
+	_, err := b.load()
+	return err == nil
+}
+
+func (b *secretServiceBackend) load() ([]byte, error) {
+	args := append([]string{"lookup"}, secretServiceAttributes...)
+	// Do not log the secret
+	out, err := utils.NewRunner("secret-tool", args...).Log(zerolog.Disabled).Exec()
+	if err != nil || len(out) == 0 {
+		return nil, errNotStored
+	}
+	return out, nil
+}
+
+func (b *secretServiceBackend) store(data []byte) error {
+	args := append([]string{"store", "--label", keyringDescription}, secretServiceAttributes...)
+	_, err := utils.NewRunner("secret-tool", args...).Log(zerolog.Disabled).Stdin(bytes.NewReader(data)).Exec()
+	if err != nil {
+		return utils.Errorf(err, L("failed to store the credentials in the Secret Service keyring"))
+	}
+	return nil
+}
+
+func (b *secretServiceBackend) remove() error {
+	args := append([]string{"clear"}, secretServiceAttributes...)
+	if _, err := utils.NewRunner("secret-tool", args...).Exec(); err != nil {
+		return utils.Errorf(err, L("failed to remove the credentials from the Secret Service keyring"))
+	}
+	return nil
+}
+
+func (b *secretServiceBackend) secure() bool {
+	return true
+}
+
+// kernelKeyringBackend stores the credentials in the kernel persistent keyring of the user.
+//
+// The persistent keyring survives the end of the user sessions, but is cleared at reboot.
+type kernelKeyringBackend struct {
+	keyring int
+}
+
+func newKernelKeyringBackend() (*kernelKeyringBackend, error) {
+	keyring, err := unix.KeyctlInt(unix.KEYCTL_GET_PERSISTENT, -1, unix.KEY_SPEC_USER_KEYRING, 0, 0)
+	if err != nil {
+		// Kernels without persistent keyring support
+		keyring, err = unix.KeyctlGetKeyringID(unix.KEY_SPEC_USER_KEYRING, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &kernelKeyringBackend{keyring: keyring}, nil
+}
+
+func (b *kernelKeyringBackend) String() string {
+	return L("kernel keyring")
+}
+
+func (b *kernelKeyringBackend) find() (int, error) {
+	id, err := unix.KeyctlSearch(b.keyring, "user", keyringDescription, 0)
+	if errors.Is(err, unix.ENOKEY) {
+		return 0, errNotStored
+	}
+	return id, err
+}
+
+func (b *kernelKeyringBackend) exists() bool {
+	_, err := b.find()
+	return err == nil
+}
+
+func (b *kernelKeyringBackend) load() ([]byte, error) {
+	id, err := b.find()
+	if err != nil {
+		return nil, err
+	}
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to read the credentials from the kernel keyring"))
+	}
+	data := make([]byte, size)
+	if _, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, data, 0); err != nil {
+		return nil, utils.Errorf(err, L("failed to read the credentials from the kernel keyring"))
+	}
+	return data, nil
+}
+
+func (b *kernelKeyringBackend) store(data []byte) error {
+	if _, err := unix.AddKey("user", keyringDescription, data, b.keyring); err != nil {
+		return utils.Errorf(err, L("failed to store the credentials in the kernel keyring"))
+	}
+	return nil
+}
+
+func (b *kernelKeyringBackend) remove() error {
+	id, err := b.find()
+	if errors.Is(err, errNotStored) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := unix.KeyctlInt(unix.KEYCTL_UNLINK, id, b.keyring, 0, 0); err != nil {
+		return utils.Errorf(err, L("failed to remove the credentials from the kernel keyring"))
+	}
+	return nil
+}
+
+func (b *kernelKeyringBackend) secure() bool {
+	return true
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/uyuni-project/uyuni-tools/shared/api/mocks"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
 )
 
 const user = "mytestuser"
@@ -26,9 +27,10 @@ const cookie = "mytestpxtcookie"
 func TestCredentialsStore(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	connection := ConnectionDetails{
-		User:     user,
-		Password: password,
-		Server:   server,
+		CredStore: CredStorePlain,
+		User:      user,
+		Password:  password,
+		Server:    server,
 	}
 	client, err := Init(&connection)
 	if err != nil {
@@ -47,7 +49,7 @@ func TestCredentialsStore(t *testing.T) {
 		t.Fail()
 	}
 
-	connection2 := ConnectionDetails{}
+	connection2 := ConnectionDetails{CredStore: CredStorePlain}
 	if err := loadLoginCreds(&connection2); err != nil {
 		t.Fail()
 	}
@@ -73,12 +75,13 @@ func TestCredentialsCleanup(t *testing.T) {
 	if err != nil {
 		t.Fail()
 	}
-	if err := RemoveLoginCreds(); err != nil {
+	if err := RemoveLoginCreds(&ConnectionDetails{CredStore: CredStorePlain}); err != nil {
 		t.Fail()
 	}
 
 	connection2 := ConnectionDetails{
-		Server: server,
+		CredStore: CredStorePlain,
+		Server:    server,
 	}
 	err = loadLoginCreds(&connection2)
 	if err == nil {
@@ -97,7 +100,8 @@ func TestAutocleanup(t *testing.T) {
 		t.Fail()
 	}
 	connection := ConnectionDetails{
-		Server: server,
+		CredStore: CredStorePlain,
+		Server:    server,
 	}
 	getStoredConnectionDetails(&connection)
 	if connection.InSession {
@@ -118,7 +122,7 @@ func TestCredentialValidation(t *testing.T) {
 		t.Fail()
 	}
 
-	connection := ConnectionDetails{}
+	connection := ConnectionDetails{CredStore: CredStorePlain}
 	client, err := Init(&connection)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to init connection")
@@ -148,7 +152,7 @@ func TestWrongCredentials(t *testing.T) {
 		t.Fail()
 	}
 
-	connection := ConnectionDetails{}
+	connection := ConnectionDetails{CredStore: CredStorePlain}
 	client, err := Init(&connection)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to init connection")
@@ -181,8 +185,9 @@ func TestWrongCredentials(t *testing.T) {
 func storeTestCredentials() error {
 	client := APIClient{
 		Details: &ConnectionDetails{
-			User:   user,
-			Server: server,
+			CredStore: CredStorePlain,
+			User:      user,
+			Server:    server,
 		},
 		AuthCookie: &http.Cookie{
 			Name:  "pxt-session-cookie",
@@ -196,8 +201,9 @@ func storeTestCredentials() error {
 func storeWrongTestCredentials() error {
 	client := APIClient{
 		Details: &ConnectionDetails{
-			User:   user,
-			Server: server,
+			CredStore: CredStorePlain,
+			User:      user,
+			Server:    server,
 		},
 		AuthCookie: &http.Cookie{
 			Name:  "pxt-session-cookie",
@@ -263,3 +269,125 @@ func userListRolesDo(req *http.Request) (*http.Response, error) {
 		Body:       r,
 	}, nil
 }
+
+// Test storing credentials for several servers.
+func TestCredentialsContexts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{"hub", "peripheral"} {
+		client := APIClient{
+			Details:    &ConnectionDetails{Server: name + ".example.com", Context: name, CredStore: CredStorePlain},
+			AuthCookie: &http.Cookie{Name: "pxt-session-cookie", Value: name + "-cookie"},
+		}
+		if err := StoreLoginCreds(&client); err != nil {
+			t.Fatalf("failed to store %s credentials: %s", name, err)
+		}
+	}
+
+	plain := &ConnectionDetails{CredStore: CredStorePlain}
+	contexts, err := ListContexts(plain)
+	if err != nil {
+		t.Fatalf("failed to list contexts: %s", err)
+	}
+	expected := []Context{
+		{Name: "hub", Server: "hub.example.com"},
+		{Name: "peripheral", Server: "peripheral.example.com", Current: true},
+	}
+	testutils.AssertEquals(t, "Unexpected contexts", expected, contexts)
+
+	// The current context is used by default
+	connection := ConnectionDetails{CredStore: CredStorePlain}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong default server", "peripheral.example.com", connection.Server)
+
+	// The context matching the server is used
+	connection = ConnectionDetails{Server: "hub.example.com", CredStore: CredStorePlain}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong cookie for server", "hub-cookie", connection.Cookie)
+
+	// Switching the current context
+	if err := UseContext(plain, "hub"); err != nil {
+		t.Fatalf("failed to change context: %s", err)
+	}
+	connection = ConnectionDetails{CredStore: CredStorePlain}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong server after use-context", "hub.example.com", connection.Server)
+	testutils.AssertTrue(t, "Unknown context should not be used", UseContext(plain, "missing") != nil)
+
+	// An unknown context makes Init fail
+	_, err = Init(&ConnectionDetails{Context: "missing", CredStore: CredStorePlain})
+	testutils.AssertTrue(t, "Init should fail with an unknown context", err != nil)
+
+	// Removing a context keeps the others
+	if err := RemoveLoginCreds(&ConnectionDetails{Context: "hub", CredStore: CredStorePlain}); err != nil {
+		t.Fatalf("failed to remove hub context: %s", err)
+	}
+	hub := &ConnectionDetails{Context: "hub", CredStore: CredStorePlain}
+	peripheral := &ConnectionDetails{Context: "peripheral", CredStore: CredStorePlain}
+	testutils.AssertTrue(t, "hub context should be removed", !IsAlreadyLoggedIn(hub))
+	testutils.AssertTrue(t, "peripheral context should be kept", IsAlreadyLoggedIn(peripheral))
+}
+
+// Test logging in to another server without naming the context.
+func TestCredentialsContextForServer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store := func(server string, session string) string {
+		client := APIClient{
+			Details:    &ConnectionDetails{Server: server, CredStore: CredStorePlain},
+			AuthCookie: &http.Cookie{Name: "pxt-session-cookie", Value: session},
+		}
+		if err := StoreLoginCreds(&client); err != nil {
+			t.Fatalf("failed to store %s credentials: %s", server, err)
+		}
+		return client.Details.Context
+	}
+
+	testutils.AssertEquals(t, "Wrong first context", defaultContext, store("hub.example.com", "hub-cookie"))
+	peripheral := &ConnectionDetails{Server: "peripheral.example.com", CredStore: CredStorePlain}
+	testutils.AssertTrue(t, "New server should not be logged in", !IsAlreadyLoggedIn(peripheral))
+	testutils.AssertEquals(t, "Wrong second context", "peripheral.example.com",
+		store("peripheral.example.com", "peripheral-cookie"),
+	)
+	testutils.AssertTrue(t, "Server should be logged in", IsAlreadyLoggedIn(peripheral))
+
+	// Logging in again to the first server reuses its context
+	testutils.AssertEquals(t, "Wrong reused context", defaultContext, store("hub.example.com", "new-hub-cookie"))
+
+	contexts, err := ListContexts(&ConnectionDetails{CredStore: CredStorePlain})
+	if err != nil {
+		t.Fatalf("failed to list contexts: %s", err)
+	}
+	expected := []Context{
+		{Name: defaultContext, Server: "hub.example.com", Current: true},
+		{Name: "peripheral.example.com", Server: "peripheral.example.com"},
+	}
+	testutils.AssertEquals(t, "Unexpected contexts", expected, contexts)
+
+	connection := ConnectionDetails{Server: "peripheral.example.com", CredStore: CredStorePlain}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Overwritten session", "peripheral-cookie", connection.Cookie)
+}
+
+// Test reading the credentials file written by older versions.
+func TestLegacyCredentials(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	legacy := fmt.Sprintf(`[{"Session": "%s", "Server": "%s", "CApath": ""}]`, cookie, server)
+	if err := os.WriteFile(getAPICredsFile(), []byte(legacy), 0600); err != nil {
+		t.Fatalf("failed to write legacy credentials: %s", err)
+	}
+
+	connection := ConnectionDetails{CredStore: CredStorePlain}
+	if err := loadLoginCreds(&connection); err != nil {
+		t.Fatalf("failed to load legacy credentials: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong server", server, connection.Server)
+	testutils.AssertEquals(t, "Wrong cookie", cookie, connection.Cookie)
+	testutils.AssertEquals(t, "Wrong context", "default", connection.Context)
+}
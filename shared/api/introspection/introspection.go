@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package introspection wraps the api namespace describing the API of the server.
+package introspection
+
+import (
+	"strings"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// sessionParameters are the names of the parameters holding the session, passed as a cookie to the HTTP API.
+var sessionParameters = []string{"sessionKey", "loggedInUser"}
+
+// ListCalls returns the methods of all the API namespaces.
+//
+// The namespaces are returned in the path form, like system/config.
+func ListCalls(client *api.APIClient) (map[string][]Method, error) {
+	calls, err := api.GetResult[map[string]map[string]Method](client, "api/getApiCallList", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the API methods"))
+	}
+
+	namespaces := map[string][]Method{}
+	for namespace, methods := range calls {
+		path := strings.ReplaceAll(namespace, ".", "/")
+		list := []Method{}
+		for _, method := range methods {
+			list = append(list, method)
+		}
+		namespaces[path] = list
+	}
+	return namespaces, nil
+}
+
+// GetParameters returns the parameters of the method to pass in the HTTP API calls.
+//
+// The parameters are reported either as a type or as a type followed by a name.
+func (m *Method) GetParameters() []Parameter {
+	parameters := []Parameter{}
+	for _, parameter := range m.Parameters {
+		fields := strings.Fields(parameter)
+		if len(fields) == 0 {
+			continue
+		}
+		param := Parameter{Type: fields[0]}
+		if len(fields) > 1 {
+			param.Name = fields[len(fields)-1]
+		}
+		if utils.Contains(sessionParameters, param.Name) {
+			continue
+		}
+		parameters = append(parameters, param)
+	}
+	return parameters
+}
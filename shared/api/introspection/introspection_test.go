@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package introspection
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/mocks"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestListCalls(t *testing.T) {
+	client := &api.APIClient{
+		BaseURL: "https://server/rhn/manager/api",
+		Details: &api.ConnectionDetails{Server: "server"},
+		Client: &mocks.MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				testutils.AssertEquals(t, "Wrong path", "/rhn/manager/api/api/getApiCallList", req.URL.Path)
+				return testutils.GetResponse(200, `{"success": true, "result": {
+					"system.config": {
+						"system.config.listFiles_string_int_int": {
+							"name": "listFiles", "parameters": ["string", "int", "int"], "exceptions": [], "return": "array"
+						}
+					}
+				}}`)
+			},
+		},
+	}
+
+	namespaces, err := ListCalls(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	methods := namespaces["system/config"]
+	testutils.AssertEquals(t, "Wrong methods count", 1, len(methods))
+	testutils.AssertEquals(t, "Wrong method name", "listFiles", methods[0].Name)
+}
+
+func TestGetParameters(t *testing.T) {
+	method := Method{Parameters: []string{"string sessionKey", "int sid", "string"}}
+	parameters := method.GetParameters()
+	testutils.AssertEquals(t, "Session parameter not skipped", 2, len(parameters))
+	testutils.AssertEquals(t, "Wrong name", "sid", parameters[0].Name)
+	testutils.AssertEquals(t, "Wrong type", "int", parameters[0].Type)
+	testutils.AssertEquals(t, "Unexpected name", "", parameters[1].Name)
+}
@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package introspection
+
+// Models/Schemas for the API introspection.
+
+// Method describes an API method as returned by the api namespace.
+type Method struct {
+	Name       string   `json:"name"`
+	Parameters []string `json:"parameters"`
+	Exceptions []string `json:"exceptions"`
+	Return     string   `json:"return"`
+}
+
+// Parameter is a parameter of an API method.
+//
+// The name is empty if the server only reports the parameter types.
+type Parameter struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kickstart
+
+import (
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListKickstarts returns the autoinstallation profiles.
+func ListKickstarts(client *api.APIClient) ([]Profile, error) {
+	profiles, err := api.GetResult[[]Profile](client, "kickstart/listKickstarts", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the autoinstallation profiles"))
+	}
+	return profiles, nil
+}
+
+// CreateProfile creates an autoinstallation profile.
+func CreateProfile(client *api.APIClient, request CreateProfileRequest) error {
+	data := map[string]interface{}{
+		"profileLabel":           request.Label,
+		"virtualizationType":     request.VirtualizationType,
+		"kickstartableTreeLabel": request.TreeLabel,
+		"kickstartHost":          request.KickstartHost,
+		"rootPassword":           request.RootPassword,
+		"updateType":             request.UpdateType,
+	}
+	if _, err := api.PostResult[int](client, "kickstart/createProfile", data); err != nil {
+		return utils.Errorf(err, L("failed to create autoinstallation profile %s"), request.Label)
+	}
+	return nil
+}
+
+// DeleteProfile removes an autoinstallation profile.
+func DeleteProfile(client *api.APIClient, label string) error {
+	data := map[string]interface{}{
+		"ksLabel": label,
+	}
+	if _, err := api.PostResult[int](client, "kickstart/deleteProfile", data); err != nil {
+		return utils.Errorf(err, L("failed to delete autoinstallation profile %s"), label)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kickstart_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/kickstart"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils/apitest"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func TestListKickstarts(t *testing.T) {
+	var path string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return testutils.GetResponse(200, `{"success": true, "result": [
+			{"label": "sles15", "tree_label": "sles15-sp6", "name": "sles15", "active": true, "update_type": "all"}
+		]}`)
+	})
+
+	profiles, err := kickstart.ListKickstarts(client)
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/kickstart/listKickstarts", path)
+	testutils.AssertEquals(t, "Wrong profiles count", 1, len(profiles))
+	testutils.AssertEquals(t, "Wrong tree label", "sles15-sp6", profiles[0].TreeLabel)
+	testutils.AssertEquals(t, "Wrong update type", "all", profiles[0].UpdateType)
+}
+
+func TestCreateProfile(t *testing.T) {
+	var path string
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	err := kickstart.CreateProfile(client, kickstart.CreateProfileRequest{
+		Label:              "sles15",
+		VirtualizationType: "none",
+		TreeLabel:          "sles15-sp6",
+		KickstartHost:      "uyuni.example.com",
+		RootPassword:       "secret",
+		UpdateType:         "all",
+	})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/kickstart/createProfile", path)
+	expected := map[string]interface{}{
+		"profileLabel":           "sles15",
+		"virtualizationType":     "none",
+		"kickstartableTreeLabel": "sles15-sp6",
+		"kickstartHost":          "uyuni.example.com",
+		"rootPassword":           "secret",
+		"updateType":             "all",
+	}
+	testutils.AssertEquals(t, "Wrong data", expected, data)
+}
+
+func TestListTrees(t *testing.T) {
+	var path string
+	var query string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		query = req.URL.RawQuery
+		return testutils.GetResponse(200, `{"success": true, "result": [
+			{"id": 3, "label": "sles15-sp6", "base_path": "/srv/distros/sles15-sp6", "channel_id": 101}
+		]}`)
+	})
+
+	trees, err := kickstart.ListTrees(client, "sles15-sp6-pool-x86_64")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/kickstart/tree/list", path)
+	testutils.AssertEquals(t, "Wrong query", "channelLabel=sles15-sp6-pool-x86_64", query)
+	testutils.AssertEquals(t, "Wrong trees count", 1, len(trees))
+	testutils.AssertEquals(t, "Wrong base path", "/srv/distros/sles15-sp6", trees[0].BasePath)
+	testutils.AssertEquals(t, "Wrong channel ID", 101, trees[0].ChannelID)
+}
+
+func TestCreateTree(t *testing.T) {
+	var path string
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	err := kickstart.CreateTree(client, &types.Distribution{
+		TreeLabel:    "sles15-sp6",
+		BasePath:     "/srv/distros/sles15-sp6",
+		ChannelLabel: "sles15-sp6-pool-x86_64",
+		InstallType:  "sles15generic",
+	})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/kickstart/tree/create", path)
+	expected := map[string]interface{}{
+		"treeLabel":    "sles15-sp6",
+		"basePath":     "/srv/distros/sles15-sp6",
+		"channelLabel": "sles15-sp6-pool-x86_64",
+		"installType":  "sles15generic",
+	}
+	testutils.AssertEquals(t, "Wrong data", expected, data)
+}
+
+func TestDeleteTreeFailure(t *testing.T) {
+	client := apitest.NewMockAPIClient(t, func(_ *http.Request) (*http.Response, error) {
+		return testutils.GetResponse(200, `{"success": false, "message": "No such tree: foo"}`)
+	})
+
+	err := kickstart.DeleteTree(client, "foo")
+	testutils.AssertTrue(t, "Missing error", err != nil)
+	testutils.AssertTrue(t, "Server message missing from error", strings.HasSuffix(err.Error(), "No such tree: foo"))
+}
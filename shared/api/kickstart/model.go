@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kickstart
+
+// Models/Schemas for the kickstart API.
+
+// Profile is an autoinstallation profile as returned by the kickstart/listKickstarts endpoint.
+type Profile struct {
+	Label        string `json:"label"`
+	TreeLabel    string `json:"tree_label"`
+	Name         string `json:"name"`
+	AdvancedMode bool   `json:"advanced_mode"`
+	OrgDefault   bool   `json:"org_default"`
+	Active       bool   `json:"active"`
+	UpdateType   string `json:"update_type"`
+}
+
+// Tree is an autoinstallable distribution.
+type Tree struct {
+	ID        int    `json:"id"`
+	Label     string `json:"label"`
+	BasePath  string `json:"base_path"`
+	ChannelID int    `json:"channel_id"`
+}
+
+// CreateProfileRequest is the request schema of the kickstart/createProfile endpoint.
+type CreateProfileRequest struct {
+	Label string
+	// VirtualizationType is one of none, qemu, para_host, xenpv or xenfv.
+	VirtualizationType string
+	TreeLabel          string
+	// KickstartHost is the FQDN of the server serving the installation files.
+	KickstartHost string
+	RootPassword  string
+	// UpdateType is one of all, red_hat or none.
+	UpdateType string
+}
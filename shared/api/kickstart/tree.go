@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kickstart
+
+import (
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListTrees returns the autoinstallable distributions of a channel.
+func ListTrees(client *api.APIClient, channelLabel string) ([]Tree, error) {
+	trees, err := api.GetResult[[]Tree](client, "kickstart/tree/list", url.Values{"channelLabel": {channelLabel}})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the distributions of channel %s"), channelLabel)
+	}
+	return trees, nil
+}
+
+// CreateTree registers an autoinstallable distribution.
+func CreateTree(client *api.APIClient, distro *types.Distribution) error {
+	data := map[string]interface{}{
+		"treeLabel":    distro.TreeLabel,
+		"basePath":     distro.BasePath,
+		"channelLabel": distro.ChannelLabel,
+		"installType":  distro.InstallType,
+	}
+	if _, err := api.PostResult[int](client, "kickstart/tree/create", data); err != nil {
+		return utils.Errorf(err, L("failed to register distribution %s"), distro.TreeLabel)
+	}
+	return nil
+}
+
+// DeleteTree removes an autoinstallable distribution.
+func DeleteTree(client *api.APIClient, label string) error {
+	data := map[string]interface{}{
+		"treeLabel": label,
+	}
+	if _, err := api.PostResult[int](client, "kickstart/tree/delete", data); err != nil {
+		return utils.Errorf(err, L("failed to delete distribution %s"), label)
+	}
+	return nil
+}
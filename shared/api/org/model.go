@@ -0,0 +1,15 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package org
+
+// Models/Schemas for the org API.
+
+// OrgUser is a user as returned by the org/listUsers endpoint.
+type OrgUser struct {
+	Login      string `json:"login"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	IsOrgAdmin bool   `json:"is_org_admin"`
+}
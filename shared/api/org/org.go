@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package org
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListOrgs returns all the organizations.
+func ListOrgs(client *api.APIClient) ([]types.Organization, error) {
+	orgs, err := api.GetResult[[]types.Organization](client, "org/listOrgs", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the organizations"))
+	}
+	return orgs, nil
+}
+
+// ListUsers returns the users of an organization.
+func ListUsers(client *api.APIClient, orgID int) ([]OrgUser, error) {
+	params := url.Values{"orgId": []string{strconv.Itoa(orgID)}}
+	users, err := api.GetResult[[]OrgUser](client, "org/listUsers", params)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the users of organization %d"), orgID)
+	}
+	return users, nil
+}
+
+// Create creates an organization with its administrator.
+func Create(client *api.APIClient, orgName string, admin *types.User, usePamAuth bool) (*types.Organization, error) {
+	data := map[string]interface{}{
+		"orgName":       orgName,
+		"adminLogin":    admin.Login,
+		"adminPassword": admin.Password,
+		"prefix":        "",
+		"firstName":     admin.FirstName,
+		"lastName":      admin.LastName,
+		"email":         admin.Email,
+		"usePamAuth":    usePamAuth,
+	}
+	org, err := api.PostResult[types.Organization](client, "org/create", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to create organization %s"), orgName)
+	}
+	return &org, nil
+}
+
+// Delete removes an organization with all its users and systems.
+func Delete(client *api.APIClient, orgID int) error {
+	data := map[string]interface{}{
+		"orgId": orgID,
+	}
+	if _, err := api.PostResult[int](client, "org/delete", data); err != nil {
+		return utils.Errorf(err, L("failed to delete organization %d"), orgID)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+const defaultRetryDelay = time.Second
+
+// statusError is the error of a request returning an HTTP error status.
+type statusError struct {
+	StatusCode int
+	message    string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// sendRequestWithRetries sends the request and retries it with an exponential backoff if it may succeed later.
+func (c *APIClient) sendRequestWithRetries(req *http.Request) (*http.Response, error) {
+	delay := c.Details.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := c.doRequest(req)
+		if err == nil || attempt >= c.Details.Retries || !isRetryable(req, err) {
+			return res, err
+		}
+
+		log.Warn().Err(err).Msgf(L("Request to %[1]s failed, retrying in %[2]s"), req.URL.Path, delay)
+		time.Sleep(delay)
+		delay *= 2
+
+		if err := rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryable returns whether a failed request could succeed if sent again.
+//
+// Only the requests without side effects are retried, unless the server could not be reached at all.
+func isRetryable(req *http.Request, err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if req.Method != http.MethodGet {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// Transport errors like timeouts or connection resets
+	return true
+}
+
+// canRelogin returns whether the request failed due to an expired session and the client can login again.
+func (c *APIClient) canRelogin(req *http.Request, err error) bool {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	if c.Details.NoRelogin || c.AuthCookie == nil || strings.HasSuffix(req.URL.Path, "/auth/login") {
+		return false
+	}
+	if c.Details.User == "" || c.Details.Password == "" {
+		log.Debug().Msg("Cannot login again without the user and password")
+		return false
+	}
+	return true
+}
+
+// rewindRequest resets the body of the request to send it again.
+func rewindRequest(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
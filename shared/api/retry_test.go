@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/mocks"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func newRetryTestClient(t *testing.T, retries int, do func(req *http.Request) (*http.Response, error)) *APIClient {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	client, err := Init(&ConnectionDetails{
+		Server:     server,
+		Retries:    retries,
+		RetryDelay: time.Millisecond,
+		CredStore:  CredStorePlain,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize the client: %s", err)
+	}
+	client.Client = &mocks.MockClient{DoFunc: do}
+	return client
+}
+
+func statusResponse(status int) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"success": true, "result": 1}`))),
+	}, nil
+}
+
+func TestRetryGet(t *testing.T) {
+	attempts := 0
+	client := newRetryTestClient(t, 3, func(_ *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return statusResponse(http.StatusServiceUnavailable)
+		}
+		return statusResponse(http.StatusOK)
+	})
+
+	res, err := Get[int](client, "system/listSystems")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong result", 1, res.Result)
+	testutils.AssertEquals(t, "Wrong attempts count", 3, attempts)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	attempts := 0
+	client := newRetryTestClient(t, 2, func(_ *http.Request) (*http.Response, error) {
+		attempts++
+		return statusResponse(http.StatusBadGateway)
+	})
+
+	_, err := Get[int](client, "system/listSystems")
+	testutils.AssertTrue(t, "Expected an error", err != nil)
+	testutils.AssertEquals(t, "Wrong attempts count", 3, attempts)
+}
+
+func TestNoRetry(t *testing.T) {
+	type testCase struct {
+		method string
+		status int
+	}
+	cases := []testCase{
+		// POST requests may have side effects
+		{http.MethodPost, http.StatusServiceUnavailable},
+		// Client errors will not get better
+		{http.MethodGet, http.StatusNotFound},
+	}
+
+	for _, test := range cases {
+		attempts := 0
+		client := newRetryTestClient(t, 3, func(_ *http.Request) (*http.Response, error) {
+			attempts++
+			return statusResponse(test.status)
+		})
+
+		var err error
+		if test.method == http.MethodPost {
+			_, err = Post[int](client, "system/deleteSystem", map[string]interface{}{"sid": 1})
+		} else {
+			_, err = Get[int](client, "system/listSystems")
+		}
+		testutils.AssertTrue(t, "Expected an error", err != nil)
+		testutils.AssertEquals(t, "Wrong attempts count for "+test.method, 1, attempts)
+	}
+}
+
+func TestRelogin(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	client, err := Init(&ConnectionDetails{User: user, Password: password, Server: server, CredStore: CredStorePlain})
+	if err != nil {
+		t.Fatalf("failed to initialize the client: %s", err)
+	}
+	client.AuthCookie = &http.Cookie{Name: "pxt-session-cookie", Value: "expiredcookie"}
+
+	logins := 0
+	client.Client = &mocks.MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/rhn/manager/api/auth/login" {
+				logins++
+				return loginTestDo(req)
+			}
+			if pxt, err := req.Cookie("pxt-session-cookie"); err != nil || pxt.Value != cookie {
+				return statusResponse(http.StatusUnauthorized)
+			}
+			return statusResponse(http.StatusOK)
+		},
+	}
+
+	res, err := Post[int](client, "system/deleteSystem", map[string]interface{}{"sid": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong result", 1, res.Result)
+	testutils.AssertEquals(t, "Wrong logins count", 1, logins)
+
+	// No relogin if disabled
+	client.Details.NoRelogin = true
+	client.AuthCookie.Value = "expiredcookie"
+	_, err = Post[int](client, "system/deleteSystem", map[string]interface{}{"sid": 1})
+	testutils.AssertTrue(t, "Expected an error without relogin", err != nil)
+	testutils.AssertEquals(t, "Unexpected login", 1, logins)
+}
+
+func TestReloginCachedSession(t *testing.T) {
+	for _, store := range []string{CredStoreFile, CredStorePlain} {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		t.Setenv(PassphraseEnv, "passphrase")
+		client := APIClient{
+			Details:    &ConnectionDetails{User: user, Password: password, Server: server, CredStore: store},
+			AuthCookie: &http.Cookie{Name: "pxt-session-cookie", Value: "expiredcookie"},
+		}
+		if err := StoreLoginCreds(&client); err != nil {
+			t.Fatalf("failed to store the credentials: %s", err)
+		}
+
+		// The plain store does not save the password, it has to be provided
+		connection := ConnectionDetails{CredStore: store}
+		if store == CredStorePlain {
+			connection.Password = password
+		}
+		cachedClient, err := Init(&connection)
+		if err != nil {
+			t.Fatalf("failed to initialize the client: %s", err)
+		}
+		testutils.AssertTrue(t, store+": session not loaded", connection.InSession)
+		testutils.AssertEquals(t, store+": wrong user loaded", user, connection.User)
+
+		cachedClient.Client = &mocks.MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/rhn/manager/api/auth/login" {
+					return loginTestDo(req)
+				}
+				if pxt, err := req.Cookie("pxt-session-cookie"); err != nil || pxt.Value != cookie {
+					return statusResponse(http.StatusUnauthorized)
+				}
+				return statusResponse(http.StatusOK)
+			},
+		}
+		if _, err := Post[int](cachedClient, "system/deleteSystem", map[string]interface{}{"sid": 1}); err != nil {
+			t.Fatalf("%s: unexpected error: %s", store, err)
+		}
+
+		// The new session is stored for the next calls
+		stored := ConnectionDetails{CredStore: store}
+		if err := loadLoginCreds(&stored); err != nil {
+			t.Fatalf("failed to load the credentials: %s", err)
+		}
+		testutils.AssertEquals(t, store+": new session not stored", cookie, stored.Cookie)
+	}
+}
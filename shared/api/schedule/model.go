@@ -0,0 +1,16 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+// Models/Schemas for the schedule API.
+
+// ActionSystem is the status of an action on a system.
+type ActionSystem struct {
+	ServerID    int    `json:"server_id"`
+	ServerName  string `json:"server_name"`
+	BaseChannel string `json:"base_channel"`
+	Timestamp   string `json:"timestamp"`
+	Message     string `json:"message"`
+}
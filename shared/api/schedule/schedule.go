@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListInProgressSystems returns the systems on which an action is queued or running.
+func ListInProgressSystems(client *api.APIClient, actionID int) ([]ActionSystem, error) {
+	return listActionSystems(client, "schedule/listInProgressSystems", actionID)
+}
+
+// ListFailedSystems returns the systems on which an action failed.
+func ListFailedSystems(client *api.APIClient, actionID int) ([]ActionSystem, error) {
+	return listActionSystems(client, "schedule/listFailedSystems", actionID)
+}
+
+// ListCompletedSystems returns the systems on which an action succeeded.
+func ListCompletedSystems(client *api.APIClient, actionID int) ([]ActionSystem, error) {
+	return listActionSystems(client, "schedule/listCompletedSystems", actionID)
+}
+
+func listActionSystems(client *api.APIClient, path string, actionID int) ([]ActionSystem, error) {
+	params := url.Values{"actionId": {strconv.Itoa(actionID)}}
+	systems, err := api.GetResult[[]ActionSystem](client, path, params)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the status of action %d"), actionID)
+	}
+	return systems, nil
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+)
+
+// maxTraceSize is the maximum number of bytes of the requests and responses bodies written in the trace logs.
+const maxTraceSize = 4096
+
+// traceBuffer keeps the beginning of the data written to it for the trace logs.
+type traceBuffer struct {
+	data  []byte
+	total int
+}
+
+func (b *traceBuffer) Write(p []byte) (int, error) {
+	if remaining := maxTraceSize - len(b.data); remaining > 0 {
+		b.data = append(b.data, p[:min(remaining, len(p))]...)
+	}
+	b.total += len(p)
+	return len(p), nil
+}
+
+func (b *traceBuffer) String() string {
+	if b.total > len(b.data) {
+		return fmt.Sprintf("%s... (%d bytes truncated)", b.data, b.total-len(b.data))
+	}
+	return string(b.data)
+}
+
+// truncateForTrace returns the data to write in the trace logs.
+func truncateForTrace(data []byte) string {
+	var buf traceBuffer
+	_, _ = buf.Write(data)
+	return buf.String()
+}
+
+// traceBody returns a reader of the response body logging its beginning at trace level once closed.
+func traceBody(body io.ReadCloser) io.ReadCloser {
+	if log.Logger.GetLevel() != zerolog.TraceLevel {
+		return body
+	}
+	buf := &traceBuffer{}
+	return &tracedBody{Reader: io.TeeReader(body, buf), body: body, buf: buf}
+}
+
+type tracedBody struct {
+	io.Reader
+	body io.Closer
+	buf  *traceBuffer
+}
+
+func (b *tracedBody) Close() error {
+	log.Trace().Msgf("response: %s", b.buf)
+	return b.body.Close()
+}
+
+// GetEach issues an HTTP GET request to the API and calls fn for each item of the result array.
+//
+// The response is decoded while being read, without loading the whole result in memory.
+// The iteration stops at the first error returned by fn.
+func GetEach[T interface{}](client *APIClient, path string, params url.Values, fn func(item T) error) error {
+	if len(params) > 0 {
+		path = fmt.Sprintf("%s?%s", path, params.Encode())
+	}
+	res, err := client.Get(path)
+	if err != nil {
+		return err
+	}
+	return decodeEach(res, fn)
+}
+
+// PostEach issues a POST HTTP request to the API and calls fn for each item of the result array.
+//
+// The response is decoded while being read, without loading the whole result in memory.
+// The iteration stops at the first error returned by fn.
+func PostEach[T interface{}](client *APIClient, path string, data map[string]interface{}, fn func(item T) error) error {
+	res, err := client.Post(path, data)
+	if err != nil {
+		return err
+	}
+	return decodeEach(res, fn)
+}
+
+// decodeEach decodes the API response object, calling fn for each item of the result array.
+func decodeEach[T interface{}](res *http.Response, fn func(item T) error) error {
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	success := false
+	message := ""
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "success":
+			err = decoder.Decode(&success)
+		case "message":
+			err = decoder.Decode(&message)
+		case "result":
+			err = decodeArray(decoder, fn)
+		default:
+			var ignored json.RawMessage
+			err = decoder.Decode(&ignored)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !success {
+		return errors.New(message)
+	}
+	return nil
+}
+
+func decodeArray[T interface{}](decoder *json.Decoder, fn func(item T) error) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		var item T
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf(L("unexpected %[1]v in API response instead of %[2]v"), token, expected)
+	}
+	return nil
+}
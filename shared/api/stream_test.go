@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/mocks"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+type streamItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newStreamTestClient(body string) *APIClient {
+	return &APIClient{
+		BaseURL: "https://server/rhn/manager/api",
+		Details: &ConnectionDetails{Server: "server"},
+		Client: &mocks.MockClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				return testutils.GetResponse(200, body)
+			},
+		},
+	}
+}
+
+func TestGetEach(t *testing.T) {
+	client := newStreamTestClient(
+		`{"result": [{"id": 1, "name": "a", "extra": [1, 2]}, {"id": 2, "name": "b"}], "success": true}`,
+	)
+	names := []string{}
+	err := GetEach(client, "system/listSystems", nil, func(item streamItem) error {
+		names = append(names, item.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong items", "a b", strings.Join(names, " "))
+}
+
+func TestGetEachStop(t *testing.T) {
+	client := newStreamTestClient(`{"success": true, "result": [{"id": 1}, {"id": 2}, {"id": 3}]}`)
+	count := 0
+	stop := errors.New("stop")
+	err := GetEach(client, "system/listSystems", nil, func(_ streamItem) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	})
+	testutils.AssertTrue(t, "Callback error not returned", errors.Is(err, stop))
+	testutils.AssertEquals(t, "Iteration not stopped", 2, count)
+}
+
+func TestPostEachFailure(t *testing.T) {
+	client := newStreamTestClient(`{"success": false, "message": "no permission"}`)
+	err := PostEach(client, "system/listSystems", nil, func(_ streamItem) error {
+		t.Error("Unexpected item")
+		return nil
+	})
+	testutils.AssertTrue(t, "Expected the server message", err != nil && err.Error() == "no permission")
+
+	client = newStreamTestClient(`{"success": true, "result": 1}`)
+	err = PostEach(client, "system/listSystems", nil, func(_ streamItem) error { return nil })
+	testutils.AssertTrue(t, "Expected an error for a non array result", err != nil)
+}
+
+func TestTruncateForTrace(t *testing.T) {
+	testutils.AssertEquals(t, "Short data should not be truncated", "short", truncateForTrace([]byte("short")))
+
+	long := strings.Repeat("a", maxTraceSize+10)
+	testutils.AssertEquals(t, "Long data should be truncated",
+		strings.Repeat("a", maxTraceSize)+"... (10 bytes truncated)", truncateForTrace([]byte(long)),
+	)
+}
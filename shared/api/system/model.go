@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+// Models/Schemas for the system API.
+
+// SystemOverview is a system as returned by the system list endpoints.
+type SystemOverview struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	LastCheckin      string `json:"last_checkin"`
+	Created          string `json:"created"`
+	LastBoot         string `json:"last_boot"`
+	ExtraPkgCount    int    `json:"extra_pkg_count"`
+	OutdatedPkgCount int    `json:"outdated_pkg_count"`
+}
+
+// SystemDetails is the response schema of the system/getDetails endpoint.
+type SystemDetails struct {
+	ID                int      `json:"id"`
+	ProfileName       string   `json:"profile_name"`
+	MachineID         string   `json:"machine_id"`
+	MinionID          string   `json:"minion_id"`
+	BaseEntitlement   string   `json:"base_entitlement"`
+	AddonEntitlements []string `json:"addon_entitlements"`
+	AutoUpdate        bool     `json:"auto_update"`
+	Release           string   `json:"release"`
+	Description       string   `json:"description"`
+	Hostname          string   `json:"hostname"`
+	LastBoot          string   `json:"last_boot"`
+	LockStatus        bool     `json:"lock_status"`
+	Virtualization    string   `json:"virtualization"`
+	ContactMethod     string   `json:"contact_method"`
+}
+
+// Erratum is a patch relevant for a system.
+type Erratum struct {
+	ID               int    `json:"id"`
+	Date             string `json:"date"`
+	AdvisoryType     string `json:"advisory_type"`
+	AdvisoryName     string `json:"advisory_name"`
+	AdvisorySynopsis string `json:"advisory_synopsis"`
+}
+
+// PeripheralServerInfo is the request schema of the system/updatePeripheralServerInfo endpoint.
+type PeripheralServerInfo struct {
+	ID               int
+	ReportDBName     string
+	ReportDBHost     string
+	ReportDBPort     int
+	ReportDBUser     string
+	ReportDBPassword string
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListSystems returns the systems visible to the user.
+func ListSystems(client *api.APIClient) ([]SystemOverview, error) {
+	systems, err := api.GetResult[[]SystemOverview](client, "system/listSystems", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the systems"))
+	}
+	return systems, nil
+}
+
+// ForEachSystem calls fn for each system visible to the user, without loading them all in memory.
+func ForEachSystem(client *api.APIClient, fn func(system SystemOverview) error) error {
+	if err := api.GetEach(client, "system/listSystems", nil, fn); err != nil {
+		return utils.Errorf(err, L("failed to list the systems"))
+	}
+	return nil
+}
+
+// ListOutOfDateSystems returns the systems with packages to update.
+func ListOutOfDateSystems(client *api.APIClient) ([]SystemOverview, error) {
+	systems, err := api.GetResult[[]SystemOverview](client, "system/listOutOfDateSystems", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the out of date systems"))
+	}
+	return systems, nil
+}
+
+// SearchByName returns the systems with a name matching the regular expression.
+func SearchByName(client *api.APIClient, regexp string) ([]SystemOverview, error) {
+	systems, err := api.GetResult[[]SystemOverview](client, "system/searchByName", url.Values{"regexp": {regexp}})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to search the systems"))
+	}
+	return systems, nil
+}
+
+// GetID returns the systems with the given profile name.
+func GetID(client *api.APIClient, name string) ([]SystemOverview, error) {
+	systems, err := api.GetResult[[]SystemOverview](client, "system/getId", url.Values{"name": {name}})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to find system %s"), name)
+	}
+	return systems, nil
+}
+
+// GetDetails returns the details of a system.
+func GetDetails(client *api.APIClient, sid int) (*SystemDetails, error) {
+	details, err := api.GetResult[SystemDetails](client, "system/getDetails", url.Values{"sid": {strconv.Itoa(sid)}})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the details of system %d"), sid)
+	}
+	return &details, nil
+}
+
+// GetRelevantErrata returns the patches applicable to a system.
+func GetRelevantErrata(client *api.APIClient, sid int) ([]Erratum, error) {
+	errata, err := api.GetResult[[]Erratum](client, "system/getRelevantErrata", url.Values{"sid": {strconv.Itoa(sid)}})
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the patches of system %d"), sid)
+	}
+	return errata, nil
+}
+
+// DeleteSystem deletes a system, failing if the cleanup of the system fails.
+func DeleteSystem(client *api.APIClient, sid int) error {
+	data := map[string]interface{}{
+		"sid":         sid,
+		"cleanupType": "FAIL_ON_CLEANUP_ERR",
+	}
+	if _, err := api.PostResult[int](client, "system/deleteSystem", data); err != nil {
+		return utils.Errorf(err, L("failed to delete system %d"), sid)
+	}
+	return nil
+}
+
+// ScheduleApplyErrata schedules the installation of patches on systems.
+//
+// Returns the identifiers of the scheduled actions.
+func ScheduleApplyErrata(client *api.APIClient, sids []int, errataIDs []int, earliest time.Time) ([]int, error) {
+	data := map[string]interface{}{
+		"sids":               sids,
+		"errataIds":          errataIDs,
+		"earliestOccurrence": formatDate(earliest),
+	}
+	actions, err := api.PostResult[[]int](client, "system/scheduleApplyErrata", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to schedule the patches installation"))
+	}
+	return actions, nil
+}
+
+// ScheduleReboot schedules the reboot of a system and returns the action identifier.
+func ScheduleReboot(client *api.APIClient, sid int, earliest time.Time) (int, error) {
+	data := map[string]interface{}{
+		"sid":                sid,
+		"earliestOccurrence": formatDate(earliest),
+	}
+	action, err := api.PostResult[int](client, "system/scheduleReboot", data)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to schedule the reboot of system %d"), sid)
+	}
+	return action, nil
+}
+
+// ScheduleScriptRun schedules a script to run as root on systems and returns the action identifier.
+//
+// timeout is the number of seconds after which the script is stopped.
+func ScheduleScriptRun(client *api.APIClient, sids []int, script string, timeout int, earliest time.Time) (int, error) {
+	data := map[string]interface{}{
+		"sids":               sids,
+		"username":           "root",
+		"groupname":          "root",
+		"timeout":            timeout,
+		"script":             script,
+		"earliestOccurrence": formatDate(earliest),
+	}
+	action, err := api.PostResult[int](client, "system/scheduleScriptRun", data)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to schedule the script run"))
+	}
+	return action, nil
+}
+
+// RegisterPeripheralServer registers a peripheral server on a hub and returns its system identifier.
+func RegisterPeripheralServer(client *api.APIClient, fqdn string) (int, error) {
+	data := map[string]interface{}{
+		"fqdn": fqdn,
+	}
+	sid, err := api.PostResult[int](client, "system/registerPeripheralServer", data)
+	if err != nil {
+		return 0, utils.Errorf(err, L("failed to register this peripheral server"))
+	}
+	return sid, nil
+}
+
+// UpdatePeripheralServerInfo sets the report database connection details of a peripheral server.
+func UpdatePeripheralServerInfo(client *api.APIClient, info PeripheralServerInfo) error {
+	data := map[string]interface{}{
+		"sid":              info.ID,
+		"reportDbName":     info.ReportDBName,
+		"reportDbHost":     info.ReportDBHost,
+		"reportDbPort":     info.ReportDBPort,
+		"reportDbUser":     info.ReportDBUser,
+		"reportDbPassword": info.ReportDBPassword,
+	}
+	if _, err := api.PostResult[int](client, "system/updatePeripheralServerInfo", data); err != nil {
+		return utils.Errorf(err, L("failed to update peripheral server info"))
+	}
+	return nil
+}
+
+// formatDate converts a time to the ISO 8601 format expected by the API.
+func formatDate(date time.Time) string {
+	return date.Format(time.RFC3339)
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package system_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils/apitest"
+)
+
+func TestListSystems(t *testing.T) {
+	var path string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return testutils.GetResponse(200, `{"success": true, "result": [
+			{"id": 1000010000, "name": "minion1", "last_checkin": "2025-03-01T10:00:00Z", "outdated_pkg_count": 3},
+			{"id": 1000010001, "name": "minion2", "last_checkin": "2025-03-02T10:00:00Z"}
+		]}`)
+	})
+
+	systems, err := system.ListSystems(client)
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/system/listSystems", path)
+	testutils.AssertEquals(t, "Wrong systems count", 2, len(systems))
+	testutils.AssertEquals(t, "Wrong ID", 1000010000, systems[0].ID)
+	testutils.AssertEquals(t, "Wrong name", "minion1", systems[0].Name)
+	testutils.AssertEquals(t, "Wrong outdated packages count", 3, systems[0].OutdatedPkgCount)
+}
+
+func TestGetDetailsFailure(t *testing.T) {
+	var query string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return testutils.GetResponse(200, `{"success": false, "message": "No such system - sid = 42"}`)
+	})
+
+	details, err := system.GetDetails(client, 42)
+	testutils.AssertEquals(t, "Wrong query", "sid=42", query)
+	testutils.AssertTrue(t, "Details should be nil", details == nil)
+	testutils.AssertTrue(t, "Missing error", err != nil)
+	testutils.AssertTrue(t, "Server message missing from error",
+		strings.HasSuffix(err.Error(), "No such system - sid = 42"))
+}
+
+func TestScheduleApplyErrata(t *testing.T) {
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": [12, 13]}`)
+	})
+
+	earliest := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	actions, err := system.ScheduleApplyErrata(client, []int{1, 2}, []int{100}, earliest)
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong actions", "[12,13]", jsonString(t, actions))
+	testutils.AssertEquals(t, "Wrong systems", "[1,2]", jsonString(t, data["sids"]))
+	testutils.AssertEquals(t, "Wrong errata", "[100]", jsonString(t, data["errataIds"]))
+	testutils.AssertEquals(t, "Wrong earliest date", "2025-03-01T10:00:00Z", data["earliestOccurrence"])
+}
+
+func jsonString(t *testing.T, value interface{}) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
@@ -0,0 +1,16 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package systemgroup
+
+// Models/Schemas for the system group API.
+
+// SystemGroup is a system group as returned by the API.
+type SystemGroup struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	OrgID       int    `json:"org_id"`
+	SystemCount int    `json:"system_count"`
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package systemgroup
+
+import (
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListAllGroups returns the system groups of the organization.
+func ListAllGroups(client *api.APIClient) ([]SystemGroup, error) {
+	groups, err := api.GetResult[[]SystemGroup](client, "systemgroup/listAllGroups", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the system groups"))
+	}
+	return groups, nil
+}
+
+// GetDetails returns a system group.
+func GetDetails(client *api.APIClient, name string) (*SystemGroup, error) {
+	group, err := api.GetResult[SystemGroup](client, "systemgroup/getDetails", nameParam(name))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the details of system group %s"), name)
+	}
+	return &group, nil
+}
+
+// Create creates a system group.
+func Create(client *api.APIClient, name string, description string) (*SystemGroup, error) {
+	data := map[string]interface{}{
+		"name":        name,
+		"description": description,
+	}
+	group, err := api.PostResult[SystemGroup](client, "systemgroup/create", data)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to create system group %s"), name)
+	}
+	return &group, nil
+}
+
+// Update changes the description of a system group.
+func Update(client *api.APIClient, name string, description string) error {
+	data := map[string]interface{}{
+		"systemGroupName": name,
+		"description":     description,
+	}
+	if _, err := api.PostResult[SystemGroup](client, "systemgroup/update", data); err != nil {
+		return utils.Errorf(err, L("failed to update system group %s"), name)
+	}
+	return nil
+}
+
+// Delete removes a system group.
+func Delete(client *api.APIClient, name string) error {
+	data := map[string]interface{}{
+		"systemGroupName": name,
+	}
+	if _, err := api.PostResult[int](client, "systemgroup/delete", data); err != nil {
+		return utils.Errorf(err, L("failed to delete system group %s"), name)
+	}
+	return nil
+}
+
+// ListSystemsMinimal returns the systems of a group.
+func ListSystemsMinimal(client *api.APIClient, name string) ([]system.SystemOverview, error) {
+	systems, err := api.GetResult[[]system.SystemOverview](client, "systemgroup/listSystemsMinimal",
+		nameParam(name),
+	)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the systems of group %s"), name)
+	}
+	return systems, nil
+}
+
+func nameParam(name string) url.Values {
+	return url.Values{"systemGroupName": {name}}
+}
@@ -4,10 +4,14 @@
 
 package api
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 const rootPathApiv1 = "/rhn/manager/api"
 const apiCredentialsStore = ".uyuni-api.json"
+const apiEncryptedCredentialsStore = ".uyuni-api.enc"
 
 // APIClient is the API entrypoint structure.
 type APIClient struct {
@@ -54,6 +58,31 @@ type ConnectionDetails struct {
 
 	// PXE cookie
 	Cookie string
+
+	// API token sent as bearer authorization instead of logging in with the user and password.
+	Token string
+
+	// Name of the stored context to use, the current one if empty.
+	Context string
+
+	// Number of times a request is retried after a transient failure, 0 to disable.
+	// Only the requests without side effects are retried, unless the server could not be reached.
+	Retries int
+
+	// Delay before the first retry, doubled for each new retry. Defaults to one second.
+	RetryDelay time.Duration `mapstructure:"retrydelay"`
+
+	// Disable logging in again with the user and password when the session has expired.
+	NoRelogin bool `mapstructure:"norelogin"`
+
+	// Where to store the credentials: auto, keyring, file or plain. Defaults to auto.
+	CredStore string `mapstructure:"credstore"`
+
+	// Never prompt the user, for example when completing the command line.
+	NonInteractive bool `mapstructure:"-"`
+
+	// Credentials store backend, created on first use.
+	backend credentialsBackend
 }
 
 // APIResponse describes the HTTP response where T is the type of the result.
@@ -65,7 +94,23 @@ type APIResponse[T interface{}] struct {
 
 // Authentication storage.
 type authStorage struct {
-	Session string
-	Server  string
-	CApath  string
+	Name     string
+	Session  string
+	Server   string
+	CApath   string
+	User     string `json:",omitempty"`
+	Password string `json:",omitempty"`
+}
+
+// Authentication storage file content holding several named contexts.
+type authConfig struct {
+	CurrentContext string
+	Contexts       []authStorage
+}
+
+// Context describes a stored API context.
+type Context struct {
+	Name    string `json:"name"`
+	Server  string `json:"server"`
+	Current bool   `json:"current"`
 }
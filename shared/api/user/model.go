@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package user
+
+// Models/Schemas for the user API.
+
+// UserSummary is a user as returned by the user/listUsers endpoint.
+type UserSummary struct {
+	ID      int    `json:"id"`
+	Login   string `json:"login"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UserDetails is the response schema of the user/getDetails endpoint.
+type UserDetails struct {
+	FirstName          string `json:"first_name"`
+	LastName           string `json:"last_name"`
+	Email              string `json:"email"`
+	OrgID              int    `json:"org_id"`
+	OrgName            string `json:"org_name"`
+	Prefix             string `json:"prefix"`
+	LastLoginDate      string `json:"last_login_date"`
+	CreatedDate        string `json:"created_date"`
+	Enabled            bool   `json:"enabled"`
+	UsePam             bool   `json:"use_pam"`
+	ReadOnly           bool   `json:"read_only"`
+	ErrataNotification bool   `json:"errata_notification"`
+}
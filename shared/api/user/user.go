@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package user
+
+import (
+	"net/url"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// ListUsers returns the users of the organization.
+func ListUsers(client *api.APIClient) ([]UserSummary, error) {
+	users, err := api.GetResult[[]UserSummary](client, "user/listUsers", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the users"))
+	}
+	return users, nil
+}
+
+// GetDetails returns the details of a user.
+func GetDetails(client *api.APIClient, login string) (*UserDetails, error) {
+	details, err := api.GetResult[UserDetails](client, "user/getDetails", loginParam(login))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the details of user %s"), login)
+	}
+	return &details, nil
+}
+
+// Create creates a user in the organization.
+//
+// If usePamAuth is true, the password is ignored and the user is authenticated using PAM.
+func Create(client *api.APIClient, user *types.User, usePamAuth bool) error {
+	data := map[string]interface{}{
+		"login":      user.Login,
+		"password":   user.Password,
+		"firstName":  user.FirstName,
+		"lastName":   user.LastName,
+		"email":      user.Email,
+		"usePamAuth": usePamAuth,
+	}
+	if _, err := api.PostResult[int](client, "user/create", data); err != nil {
+		return utils.Errorf(err, L("failed to create user %s"), user.Login)
+	}
+	return nil
+}
+
+// SetDetails updates the names and email of a user and its password if not empty.
+func SetDetails(client *api.APIClient, user *types.User) error {
+	details := map[string]interface{}{
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+		"email":      user.Email,
+	}
+	if user.Password != "" {
+		details["password"] = user.Password
+	}
+	data := map[string]interface{}{
+		"login":   user.Login,
+		"details": details,
+	}
+	if _, err := api.PostResult[int](client, "user/setDetails", data); err != nil {
+		return utils.Errorf(err, L("failed to update user %s"), user.Login)
+	}
+	return nil
+}
+
+// Delete removes a user.
+func Delete(client *api.APIClient, login string) error {
+	return postLogin(client, "user/delete", login, L("failed to delete user %s"))
+}
+
+// Enable enables a disabled user.
+func Enable(client *api.APIClient, login string) error {
+	return postLogin(client, "user/enable", login, L("failed to enable user %s"))
+}
+
+// Disable prevents a user from logging in.
+func Disable(client *api.APIClient, login string) error {
+	return postLogin(client, "user/disable", login, L("failed to disable user %s"))
+}
+
+// ListRoles returns the roles of a user.
+func ListRoles(client *api.APIClient, login string) ([]string, error) {
+	roles, err := api.GetResult[[]string](client, "user/listRoles", loginParam(login))
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the roles of user %s"), login)
+	}
+	return roles, nil
+}
+
+// ListAssignableRoles returns the roles that can be given to the users.
+func ListAssignableRoles(client *api.APIClient) ([]string, error) {
+	roles, err := api.GetResult[[]string](client, "user/listAssignableRoles", nil)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list the assignable roles"))
+	}
+	return roles, nil
+}
+
+// AddRole gives a role to a user.
+func AddRole(client *api.APIClient, login string, role string) error {
+	data := map[string]interface{}{
+		"login": login,
+		"role":  role,
+	}
+	if _, err := api.PostResult[int](client, "user/addRole", data); err != nil {
+		return utils.Errorf(err, L("failed to add role %[1]s to user %[2]s"), role, login)
+	}
+	return nil
+}
+
+// RemoveRole removes a role from a user.
+func RemoveRole(client *api.APIClient, login string, role string) error {
+	data := map[string]interface{}{
+		"login": login,
+		"role":  role,
+	}
+	if _, err := api.PostResult[int](client, "user/removeRole", data); err != nil {
+		return utils.Errorf(err, L("failed to remove role %[1]s from user %[2]s"), role, login)
+	}
+	return nil
+}
+
+func postLogin(client *api.APIClient, path string, login string, message string) error {
+	data := map[string]interface{}{
+		"login": login,
+	}
+	if _, err := api.PostResult[int](client, path, data); err != nil {
+		return utils.Errorf(err, message, login)
+	}
+	return nil
+}
+
+func loginParam(login string) url.Values {
+	return url.Values{"login": {login}}
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package user_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/api/user"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils/apitest"
+)
+
+func TestListUsers(t *testing.T) {
+	var path string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return testutils.GetResponse(200, `{"success": true, "result": [
+			{"id": 1, "login": "admin", "enabled": true},
+			{"id": 2, "login": "jdoe", "enabled": false}
+		]}`)
+	})
+
+	users, err := user.ListUsers(client)
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/user/listUsers", path)
+	testutils.AssertEquals(t, "Wrong users count", 2, len(users))
+	testutils.AssertEquals(t, "Wrong login", "jdoe", users[1].Login)
+	testutils.AssertTrue(t, "User should be disabled", !users[1].Enabled)
+}
+
+func TestGetDetails(t *testing.T) {
+	var query string
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return testutils.GetResponse(200, `{"success": true, "result": {
+			"first_name": "John", "last_name": "Doe", "email": "jdoe@example.com",
+			"org_id": 1, "enabled": true, "use_pam": true
+		}}`)
+	})
+
+	details, err := user.GetDetails(client, "jdoe")
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong query", "login=jdoe", query)
+	testutils.AssertEquals(t, "Wrong first name", "John", details.FirstName)
+	testutils.AssertEquals(t, "Wrong email", "jdoe@example.com", details.Email)
+	testutils.AssertTrue(t, "User should use PAM", details.UsePam)
+}
+
+func TestCreate(t *testing.T) {
+	var path string
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	newUser := types.User{Login: "jdoe", Password: "secret", FirstName: "John", LastName: "Doe", Email: "jdoe@example.com"}
+	err := user.Create(client, &newUser, true)
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong endpoint", "/rhn/manager/api/user/create", path)
+	expected := map[string]interface{}{
+		"login":      "jdoe",
+		"password":   "secret",
+		"firstName":  "John",
+		"lastName":   "Doe",
+		"email":      "jdoe@example.com",
+		"usePamAuth": true,
+	}
+	testutils.AssertEquals(t, "Wrong data", expected, data)
+}
+
+func TestSetDetails(t *testing.T) {
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": true, "result": 1}`)
+	})
+
+	err := user.SetDetails(client, &types.User{Login: "jdoe", FirstName: "John", LastName: "Doe"})
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Wrong login", interface{}("jdoe"), data["login"])
+	details, ok := data["details"].(map[string]interface{})
+	testutils.AssertTrue(t, "Missing details", ok)
+	testutils.AssertEquals(t, "Wrong first name", interface{}("John"), details["first_name"])
+	_, hasPassword := details["password"]
+	testutils.AssertTrue(t, "Empty password should not be sent", !hasPassword)
+}
+
+func TestAddRoleFailure(t *testing.T) {
+	var data map[string]interface{}
+	client := apitest.NewMockAPIClient(t, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return testutils.GetResponse(200, `{"success": false, "message": "Invalid role: foo"}`)
+	})
+
+	err := user.AddRole(client, "jdoe", "foo")
+	testutils.AssertEquals(t, "Wrong role", interface{}("foo"), data["role"])
+	testutils.AssertTrue(t, "Missing error", err != nil)
+	testutils.AssertTrue(t, "Server message missing from error", strings.HasSuffix(err.Error(), "Invalid role: foo"))
+}
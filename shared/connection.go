@@ -8,10 +8,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,7 +34,9 @@ type Connection struct {
 	kubernetesFilter string
 	namespace        string
 	container        string
-	systemd          podman.Systemd
+	// kubernetesContainer is the name of the container in the pod if it differs from the podman one.
+	kubernetesContainer string
+	systemd             podman.Systemd
 }
 
 // NewConnection creates a new connection object.
@@ -141,13 +145,7 @@ func (c *Connection) GetNamespace(appName string) (string, error) {
 
 	// if no appName is provided, we'll assume it based on its filter
 	if appName == "" {
-		switch c.kubernetesFilter {
-		case kubernetes.ProxyFilter:
-			appName = kubernetes.ProxyApp
-		case kubernetes.ServerFilter:
-			appName = kubernetes.ServerApp
-		}
-
+		appName = getFilterApp(c.kubernetesFilter)
 		if appName == "" {
 			return "", errors.New(L("coundn't find app name"))
 		}
@@ -167,6 +165,16 @@ func (c *Connection) GetNamespace(appName string) (string, error) {
 	return c.namespace, nil
 }
 
+// getFilterApp returns the application of a kubernetes filter, like uyuni for the server and its components.
+func getFilterApp(filter string) string {
+	for _, selector := range strings.Split(strings.TrimPrefix(filter, "-l"), ",") {
+		if app, found := strings.CutPrefix(selector, kubernetes.AppLabel+"="); found {
+			return app
+		}
+	}
+	return ""
+}
+
 // GetPodName finds the name of the running pod.
 func (c *Connection) GetPodName() (string, error) {
 	var err error
@@ -181,13 +189,14 @@ func (c *Connection) GetPodName() (string, error) {
 		case "podman-remote":
 			fallthrough
 		case "podman":
-			if out, _ := utils.RunCmdOutput(
-				zerolog.DebugLevel, c.command, "ps", "-q", "-f", "name="+c.container,
-			); len(out) == 0 {
+			out, _ := utils.RunCmdOutput(
+				zerolog.DebugLevel, c.command, "ps", "--format", "{{.Names}}", "-f", "name="+c.container,
+			)
+			if name := matchContainerName(c.container, strings.Fields(string(out))); name == "" {
 				err = fmt.Errorf(L("container %s is not running on podman"), c.container)
 			} else {
-				log.Trace().Msgf("Found container ID '%s'", out)
-				c.podName = c.container
+				log.Trace().Msgf("Found container '%s'", name)
+				c.podName = name
 			}
 		case "kubectl":
 			// We try the first item on purpose to make the command fail if not available
@@ -203,36 +212,94 @@ func (c *Connection) GetPodName() (string, error) {
 	return c.podName, err
 }
 
+// matchContainerName returns the running container matching the name.
+//
+// The podman name filter matches substrings: the container with the exact name is preferred over the instances of
+// templated services named like name-instance. An empty string is returned if nothing matches.
+func matchContainerName(name string, running []string) string {
+	sort.Strings(running)
+	for _, candidate := range running {
+		if candidate == name {
+			return candidate
+		}
+	}
+	for _, candidate := range running {
+		if strings.HasPrefix(candidate, name+"-") {
+			return candidate
+		}
+	}
+	return ""
+}
+
 // Exec runs command inside the container within an sh shell.
 func (c *Connection) Exec(command string, args ...string) ([]byte, error) {
+	cmd, cmdArgs, err := c.execArgs(false, command, args...)
+	if err != nil {
+		return nil, err
+	}
+	return utils.RunCmdOutput(zerolog.DebugLevel, cmd, cmdArgs...)
+}
+
+// ExecStream runs command inside the container, feeding its standard input from stdin if not nil and writing its
+// standard output to stdout.
+//
+// This is meant for large or binary data like archives.
+func (c *Connection) ExecStream(stdin io.Reader, stdout io.Writer, command string, args ...string) error {
+	cmd, cmdArgs, err := c.execArgs(stdin != nil, command, args...)
+	if err != nil {
+		return err
+	}
+	runner := utils.NewRunner(cmd, cmdArgs...).Log(zerolog.DebugLevel).Stdout(stdout)
+	if stdin != nil {
+		runner = runner.Stdin(stdin)
+	}
+	_, err = runner.Exec()
+	return err
+}
+
+// execArgs returns the backend command and its arguments to run command inside the container.
+func (c *Connection) execArgs(interactive bool, command string, args ...string) (string, []string, error) {
 	if c.podName == "" {
 		if _, err := c.GetPodName(); c.podName == "" {
 			commandStr := fmt.Sprintf("%s %s", command, strings.Join(args, " "))
-			return nil, utils.Errorf(err, L("%s command not executed:"), commandStr)
+			return "", nil, utils.Errorf(err, L("%s command not executed:"), commandStr)
 		}
 	}
 
 	cmd, cmdErr := c.GetCommand()
 	if cmdErr != nil {
-		return nil, cmdErr
+		return "", nil, cmdErr
 	}
 
-	cmdArgs := []string{"exec", c.podName}
+	cmdArgs := []string{"exec"}
+	if interactive {
+		cmdArgs = append(cmdArgs, "-i")
+	}
+	cmdArgs = append(cmdArgs, c.podName)
 	if cmd == "kubectl" {
 		if _, err := c.GetNamespace(""); c.namespace == "" {
-			return nil, utils.Errorf(err, L("failed to retrieve namespace "))
+			return "", nil, utils.Errorf(err, L("failed to retrieve namespace "))
 		}
 
-		if c.container == "" {
-			c.container = "uyuni"
-		}
-
-		cmdArgs = append(cmdArgs, "-n", c.namespace, "-c", c.container, "--")
+		cmdArgs = append(cmdArgs, "-n", c.namespace, "-c", c.GetKubernetesContainer(), "--")
 	}
 	shellArgs := append([]string{command}, args...)
 	cmdArgs = append(cmdArgs, shellArgs...)
 
-	return utils.RunCmdOutput(zerolog.DebugLevel, cmd, cmdArgs...)
+	return cmd, cmdArgs, nil
+}
+
+// GetKubernetesContainer returns the name of the container in the kubernetes pod.
+//
+// The server container is named after the application in the pod, not like the podman one.
+func (c *Connection) GetKubernetesContainer() string {
+	if c.kubernetesContainer != "" {
+		return c.kubernetesContainer
+	}
+	if c.container == "" || c.container == podman.ServerContainerName {
+		return kubernetes.ServerApp
+	}
+	return c.container
 }
 
 // Healthcheck runs healthcheck command inside the container.
@@ -367,8 +434,9 @@ func (c *Connection) Copy(src string, dst string, user string, group string) err
 	case "podman":
 		commandArgs = []string{"cp", srcExpanded, dstExpanded}
 	case "kubectl":
-		commandArgs = []string{"cp", "-c", "uyuni", "-n", namespace, srcExpanded, dstExpanded}
-		extraArgs = []string{"-c", "uyuni", "--"}
+		container := c.GetKubernetesContainer()
+		commandArgs = []string{"cp", "-c", container, "-n", namespace, srcExpanded, dstExpanded}
+		extraArgs = []string{"-c", container, "--"}
 	default:
 		return fmt.Errorf(L("unknown container kind: %s"), command)
 	}
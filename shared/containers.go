@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+)
+
+// DefaultContainer is the logical name of the server container.
+const DefaultContainer = "server"
+
+// ContainerTarget describes where a container runs depending on the backend.
+type ContainerTarget struct {
+	// Podman is the name of the podman container.
+	Podman string
+	// KubernetesFilter is the filter matching the kubernetes pod, empty if not deployed on kubernetes.
+	KubernetesFilter string
+	// KubernetesContainer is the name of the container in the kubernetes pod.
+	KubernetesContainer string
+}
+
+// ContainerTargets maps the logical container names to their podman and kubernetes locations.
+var ContainerTargets = map[string]ContainerTarget{
+	DefaultContainer: {podman.ServerContainerName, kubernetes.ServerFilter, kubernetes.ServerApp},
+	"db":             {podman.DBContainerName, serverComponentFilter(kubernetes.DBComponent), "db"},
+	"hub": {podman.HubXmlrpcContainerName, serverComponentFilter(kubernetes.HubAPIComponent),
+		"uyuni-hub-api",
+	},
+	"attestation": {podman.ServerAttestationService, serverComponentFilter(kubernetes.CocoComponent), "coco"},
+	"saline":      {podman.SalineService, "", ""},
+
+	"proxy-httpd":       {"uyuni-proxy-httpd", kubernetes.ProxyFilter, "httpd"},
+	"proxy-salt-broker": {"uyuni-proxy-salt-broker", kubernetes.ProxyFilter, "salt-broker"},
+	"proxy-squid":       {"uyuni-proxy-squid", kubernetes.ProxyFilter, "squid"},
+	"proxy-ssh":         {"uyuni-proxy-ssh", kubernetes.ProxyFilter, "ssh"},
+	"proxy-tftpd":       {"uyuni-proxy-tftpd", kubernetes.ProxyFilter, "tftpd"},
+}
+
+func serverComponentFilter(component string) string {
+	return fmt.Sprintf("-l%s=%s,%s=%s", kubernetes.AppLabel, kubernetes.ServerApp, kubernetes.ComponentLabel, component)
+}
+
+// ContainerNames returns the sorted logical container names.
+func ContainerNames() []string {
+	names := []string{}
+	for name := range ContainerTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewContainerConnection creates a connection to a container given by its logical name.
+func NewContainerConnection(backend string, name string) (*Connection, error) {
+	target, ok := ContainerTargets[name]
+	if !ok {
+		return nil, fmt.Errorf(L("unknown container %[1]s, possible values: %[2]s"),
+			name, strings.Join(ContainerNames(), ", "))
+	}
+	if backend == "kubectl" && target.KubernetesFilter == "" {
+		return nil, fmt.Errorf(L("container %s is not available on kubernetes"), name)
+	}
+	cnx := NewConnection(backend, target.Podman, target.KubernetesFilter)
+	cnx.kubernetesContainer = target.KubernetesContainer
+	return cnx, nil
+}
+
+// AddContainerFlag adds the --container flag to a command.
+func AddContainerFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("container", "C", DefaultContainer,
+		fmt.Sprintf(L("Container to connect to. Possible values: %s"), strings.Join(ContainerNames(), ", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc("container",
+		func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return ContainerNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+	)
+}
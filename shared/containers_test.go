@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/kubernetes"
+	"github.com/uyuni-project/uyuni-tools/shared/podman"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestNewContainerConnection(t *testing.T) {
+	cnx, err := NewContainerConnection("", DefaultContainer)
+	testutils.AssertTrue(t, "unexpected error", err == nil)
+	testutils.AssertEquals(t, "Wrong server container", podman.ServerContainerName, cnx.container)
+	testutils.AssertEquals(t, "Wrong server filter", kubernetes.ServerFilter, cnx.kubernetesFilter)
+	testutils.AssertEquals(t, "Wrong server pod container", "uyuni", cnx.GetKubernetesContainer())
+
+	cnx, err = NewContainerConnection("kubectl", "db")
+	testutils.AssertTrue(t, "unexpected error", err == nil)
+	testutils.AssertEquals(t, "Wrong db container", podman.DBContainerName, cnx.container)
+	testutils.AssertEquals(t, "Wrong db filter",
+		"-lapp.kubernetes.io/part-of=uyuni,app.kubernetes.io/component=db", cnx.kubernetesFilter,
+	)
+	testutils.AssertEquals(t, "Wrong db pod container", "db", cnx.GetKubernetesContainer())
+
+	_, err = NewContainerConnection("kubectl", "saline")
+	testutils.AssertTrue(t, "saline is not on kubernetes", err != nil)
+
+	_, err = NewContainerConnection("", "foo")
+	testutils.AssertTrue(t, "unknown container should fail", err != nil)
+}
+
+func TestGetKubernetesContainer(t *testing.T) {
+	// Connections created without a logical name keep their container
+	cnx := NewConnection("kubectl", "squid", kubernetes.ProxyFilter)
+	testutils.AssertEquals(t, "Wrong proxy container", "squid", cnx.GetKubernetesContainer())
+}
+
+// fakeBackends creates podman and kubectl scripts in the PATH returning the given running containers.
+func fakeBackends(t *testing.T, running []string) {
+	dir := t.TempDir()
+	scripts := map[string]string{
+		// Like podman, the name filter matches substrings
+		"podman": `#!/bin/sh
+if [ "$1" = "ps" ]; then
+	filter=$(echo "$@" | sed 's/.*name=\([^ ]*\).*/\1/')
+	printf '` + strings.Join(running, `\n`) + `\n' | grep -F "$filter"
+fi
+`,
+		"kubectl": `#!/bin/sh
+case "$2" in
+	pod) printf mypod;;
+	all) printf myns;;
+esac
+`,
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(path.Join(dir, name), []byte(content), 0700); err != nil {
+			t.Fatalf("failed to write fake %s: %s", name, err)
+		}
+	}
+	t.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+}
+
+func TestContainerExecArgs(t *testing.T) {
+	fakeBackends(t, []string{
+		"uyuni-server", "uyuni-server-attestation-0", "uyuni-db", "uyuni-hub-xmlrpc-0", "uyuni-saline",
+		"uyuni-proxy-httpd", "uyuni-proxy-salt-broker", "uyuni-proxy-squid", "uyuni-proxy-ssh", "uyuni-proxy-tftpd",
+	})
+
+	podmanData := map[string]string{
+		"server":            "uyuni-server",
+		"db":                "uyuni-db",
+		"hub":               "uyuni-hub-xmlrpc-0",
+		"attestation":       "uyuni-server-attestation-0",
+		"saline":            "uyuni-saline",
+		"proxy-httpd":       "uyuni-proxy-httpd",
+		"proxy-salt-broker": "uyuni-proxy-salt-broker",
+		"proxy-squid":       "uyuni-proxy-squid",
+		"proxy-ssh":         "uyuni-proxy-ssh",
+		"proxy-tftpd":       "uyuni-proxy-tftpd",
+	}
+	kubernetesData := map[string]string{
+		"server":            "uyuni",
+		"db":                "db",
+		"hub":               "uyuni-hub-api",
+		"attestation":       "coco",
+		"proxy-httpd":       "httpd",
+		"proxy-salt-broker": "salt-broker",
+		"proxy-squid":       "squid",
+		"proxy-ssh":         "ssh",
+		"proxy-tftpd":       "tftpd",
+	}
+	testutils.AssertEquals(t, "Missing podman test cases", len(ContainerTargets), len(podmanData))
+
+	for name, container := range podmanData {
+		cnx, err := NewContainerConnection("podman", name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", name, err)
+		}
+		cmd, args, err := cnx.execArgs(false, "true")
+		if err != nil {
+			t.Errorf("unexpected error for %s on podman: %s", name, err)
+			continue
+		}
+		testutils.AssertEquals(t, "Wrong command for "+name, "podman", cmd)
+		testutils.AssertEquals(t, "Wrong podman arguments for "+name,
+			"exec "+container+" true", strings.Join(args, " "),
+		)
+	}
+
+	for name, container := range kubernetesData {
+		cnx, err := NewContainerConnection("kubectl", name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", name, err)
+		}
+		cmd, args, err := cnx.execArgs(true, "true")
+		if err != nil {
+			t.Errorf("unexpected error for %s on kubernetes: %s", name, err)
+			continue
+		}
+		testutils.AssertEquals(t, "Wrong command for "+name, "kubectl", cmd)
+		testutils.AssertEquals(t, "Wrong kubectl arguments for "+name,
+			"exec -i mypod -n myns -c "+container+" -- true", strings.Join(args, " "),
+		)
+	}
+}
+
+func TestMatchContainerName(t *testing.T) {
+	data := []struct {
+		name     string
+		running  []string
+		expected string
+	}{
+		{"uyuni-server", []string{"uyuni-server-attestation-0", "uyuni-server"}, "uyuni-server"},
+		{"uyuni-hub-xmlrpc", []string{"uyuni-hub-xmlrpc-1", "uyuni-hub-xmlrpc-0"}, "uyuni-hub-xmlrpc-0"},
+		{"uyuni-db", []string{"my-uyuni-db2"}, ""},
+		{"uyuni-db", []string{}, ""},
+	}
+	for i, test := range data {
+		testutils.AssertEquals(t, fmt.Sprintf("case #%d: wrong container", i), test.expected,
+			matchContainerName(test.name, test.running),
+		)
+	}
+}
@@ -0,0 +1,310 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"github.com/uyuni-project/uyuni-tools/shared/utils"
+)
+
+// serverPrefix marks the paths inside the container.
+const serverPrefix = "server:"
+
+// progressThreshold is the size of the transfers from which the progress is shown.
+const progressThreshold = 10 * 1024 * 1024
+
+// CopyOptions are the options of the files copy to and from the container.
+type CopyOptions struct {
+	// User or UID to set on the files copied in the container, the owner is unchanged if empty.
+	User string
+	// Group or GID to set with the user on the files copied in the container.
+	Group string
+	// Preserve keeps the permissions and modification times of the files.
+	Preserve bool
+}
+
+// CopyFiles transfers files and directories to or from the container.
+//
+// Either the sources or the destination are prefixed with `server:` to designate paths in the container.
+// The sources may contain glob patterns. Like cp, the sources are copied inside the destination if it is an
+// existing directory or ends with a slash, otherwise a single source is copied as the destination.
+// The data is streamed as a tar archive through the container backend.
+func (c *Connection) CopyFiles(sources []string, dst string, options CopyOptions) error {
+	toServer := strings.HasPrefix(dst, serverPrefix)
+	for _, src := range sources {
+		if strings.HasPrefix(src, serverPrefix) == toServer {
+			return errors.New(L("either the sources or the destination need to be prefixed with server:"))
+		}
+	}
+
+	if toServer {
+		return c.copyToServer(sources, strings.TrimPrefix(dst, serverPrefix), options)
+	}
+	return c.copyFromServer(sources, dst, options)
+}
+
+func (c *Connection) copyToServer(sources []string, dst string, options CopyOptions) error {
+	files, err := expandLocalSources(sources)
+	if err != nil {
+		return err
+	}
+
+	// A failing test means the destination is not an existing directory
+	_, testErr := c.Exec("test", "-d", dst)
+	dstIsDir := testErr == nil
+	if !dstIsDir && strings.HasSuffix(dst, "/") {
+		if _, err := c.Exec("mkdir", "-p", dst); err != nil {
+			return utils.Errorf(err, L("failed to create %s in the container"), dst)
+		}
+		dstIsDir = true
+	}
+	targetDir, names, err := getCopyTargets(files, dst, dstIsDir)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, file := range files {
+		size, err := utils.TreeSize(file)
+		if err != nil {
+			return err
+		}
+		total += size
+	}
+
+	reader, writer := io.Pipe()
+	counter := &countingWriter{writer: writer}
+	go func() {
+		tarWriter := tar.NewWriter(counter)
+		var err error
+		for i, file := range files {
+			if err = utils.WriteTarTree(tarWriter, file, names[i]); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = tarWriter.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	args := []string{"-x", "-f", "-", "-C", targetDir, "--no-same-owner"}
+	if !options.Preserve {
+		args = append(args, "-m", "--no-same-permissions")
+	}
+	err = withProgress(path.Base(dst), total, counter.count.Load, func() error {
+		err := c.ExecStream(reader, nil, "tar", args...)
+		// Unblock the archive writer if tar failed
+		reader.Close()
+		return err
+	})
+	if err != nil {
+		return utils.Errorf(err, L("failed to copy the files to %s in the container"), dst)
+	}
+
+	if options.User == "" {
+		return nil
+	}
+	owner := options.User
+	if options.Group != "" {
+		owner += ":" + options.Group
+	}
+	chownArgs := []string{"-R", owner}
+	for _, name := range names {
+		chownArgs = append(chownArgs, path.Join(targetDir, name))
+	}
+	if _, err := c.Exec("chown", chownArgs...); err != nil {
+		return utils.Errorf(err, L("failed to set the owner of the copied files"))
+	}
+	return nil
+}
+
+func (c *Connection) copyFromServer(sources []string, dst string, options CopyOptions) error {
+	files, err := c.expandServerSources(sources)
+	if err != nil {
+		return err
+	}
+
+	info, statErr := os.Stat(dst)
+	dstIsDir := statErr == nil && info.IsDir()
+	if !dstIsDir && strings.HasSuffix(dst, "/") {
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		dstIsDir = true
+	}
+	targetDir, names, err := getCopyTargets(files, dst, dstIsDir)
+	if err != nil {
+		return err
+	}
+
+	for i, file := range files {
+		reader, writer := io.Pipe()
+		counter := &countingWriter{writer: io.Discard}
+		go func(file string) {
+			writer.CloseWithError(c.ExecStream(nil, writer, "tar", "-c", "-f", "-", "-C", path.Dir(file),
+				path.Base(file)),
+			)
+		}(file)
+
+		err := withProgress(path.Base(file), c.getServerSize(file), counter.count.Load, func() error {
+			err := utils.ExtractTar(io.TeeReader(reader, counter), targetDir, names[i], options.Preserve)
+			// Unblock tar if the extraction failed
+			reader.Close()
+			return err
+		})
+		if err != nil {
+			return utils.Errorf(err, L("failed to copy %s from the container"), file)
+		}
+	}
+	return nil
+}
+
+// getCopyTargets returns the directory to extract the files to and the name to give to each of them.
+func getCopyTargets(files []string, dst string, dstIsDir bool) (string, []string, error) {
+	names := []string{}
+	if dstIsDir {
+		for _, file := range files {
+			names = append(names, path.Base(filepath.ToSlash(file)))
+		}
+		return dst, names, nil
+	}
+	if len(files) > 1 {
+		return "", nil, fmt.Errorf(L("%s is not a directory"), dst)
+	}
+	return path.Dir(dst), []string{path.Base(dst)}, nil
+}
+
+// expandLocalSources replaces the glob patterns of the sources by the matching paths.
+func expandLocalSources(sources []string) ([]string, error) {
+	files := []string{}
+	for _, src := range sources {
+		if !hasGlob(src) {
+			if _, err := os.Lstat(src); err != nil {
+				return nil, err
+			}
+			files = append(files, src)
+			continue
+		}
+		matches, err := filepath.Glob(src)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf(L("no file matches %s"), src)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// expandServerSources replaces the glob patterns of the sources by the matching paths in the container.
+func (c *Connection) expandServerSources(sources []string) ([]string, error) {
+	files := []string{}
+	for _, src := range sources {
+		src = strings.TrimPrefix(src, serverPrefix)
+		if !hasGlob(src) {
+			// A trailing slash would result in wrong folder and name for tar
+			files = append(files, path.Clean(src))
+			continue
+		}
+		script := fmt.Sprintf(`for f in %s; do [ -e "$f" ] && echo "$f"; done; true`, quoteGlob(src))
+		out, err := c.Exec("sh", "-c", script)
+		if err != nil {
+			return nil, utils.Errorf(err, L("failed to expand %s in the container"), src)
+		}
+		matches := []string{}
+		for _, line := range strings.Split(string(out), "\n") {
+			if line != "" {
+				matches = append(matches, path.Clean(line))
+			}
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf(L("no file matches %s in the container"), src)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// getServerSize returns the size of a file or directory in the container, 0 if unknown.
+func (c *Connection) getServerSize(file string) int64 {
+	out, err := c.Exec("du", "-sb", file)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to get the size of %s", file)
+		return 0
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return 0
+	}
+	size, _ := strconv.ParseInt(fields[0], 10, 64)
+	return size
+}
+
+func hasGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// quoteGlob quotes a glob pattern for the shell, leaving the wildcards unquoted.
+func quoteGlob(pattern string) string {
+	var quoted strings.Builder
+	literal := ""
+	flush := func() {
+		if literal != "" {
+			quoted.WriteString("'" + strings.ReplaceAll(literal, "'", `'\''`) + "'")
+			literal = ""
+		}
+	}
+	for _, char := range pattern {
+		if strings.ContainsRune("*?[]", char) {
+			flush()
+			quoted.WriteRune(char)
+		} else {
+			literal += string(char)
+		}
+	}
+	flush()
+	return quoted.String()
+}
+
+// withProgress runs the transfer, showing its progress if large enough.
+func withProgress(name string, total int64, current func() int64, transfer func() error) error {
+	if total < progressThreshold {
+		return transfer()
+	}
+	progress := utils.NewProgressBars()
+	progress.Start()
+	defer progress.Stop()
+
+	item := progress.Add(name, total, current)
+	err := transfer()
+	progress.Done(item, err)
+	return err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	writer io.Writer
+	count  atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.count.Add(int64(n))
+	return n, err
+}
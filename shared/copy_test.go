@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestGetCopyTargets(t *testing.T) {
+	dir, names, err := getCopyTargets([]string{"/a/file1", "/b/dir"}, "/dst", true)
+	testutils.AssertTrue(t, "unexpected error", err == nil)
+	testutils.AssertEquals(t, "Wrong target directory", "/dst", dir)
+	testutils.AssertEquals(t, "Wrong names", "file1,dir", strings.Join(names, ","))
+
+	dir, names, err = getCopyTargets([]string{"/a/file1"}, "/dst/renamed", false)
+	testutils.AssertTrue(t, "unexpected error", err == nil)
+	testutils.AssertEquals(t, "Wrong target directory", "/dst", dir)
+	testutils.AssertEquals(t, "Wrong names", "renamed", strings.Join(names, ","))
+
+	_, _, err = getCopyTargets([]string{"/a/file1", "/b/dir"}, "/dst/renamed", false)
+	testutils.AssertTrue(t, "several files copied to a file should fail", err != nil)
+}
+
+func TestQuoteGlob(t *testing.T) {
+	testutils.AssertEquals(t, "Wrong quoting", `'/srv/my dir/'*'.iso'`, quoteGlob("/srv/my dir/*.iso"))
+	testutils.AssertEquals(t, "Wrong quoting", `'/it'\''s/file'?`, quoteGlob("/it's/file?"))
+
+	// Check the shell really expands the quoted pattern
+	dir := t.TempDir()
+	out, err := exec.Command("sh", "-c", "cd '"+dir+"' && touch 'a b.iso' c.txt && echo "+quoteGlob("a b*")).Output()
+	if err != nil {
+		t.Fatalf("failed to run shell: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong expansion", "a b.iso", strings.TrimSpace(string(out)))
+}
+
+func TestExpandServerSources(t *testing.T) {
+	cnx := Connection{}
+	files, err := cnx.expandServerSources([]string{"server:/srv/www/htdocs/pub/", "server:/etc/rhn//rhn.conf"})
+	testutils.AssertTrue(t, "unexpected error", err == nil)
+	testutils.AssertEquals(t, "Wrong files", "/srv/www/htdocs/pub,/etc/rhn/rhn.conf", strings.Join(files, ","))
+}
@@ -6,6 +6,7 @@ package kubernetes
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"os/exec"
 	"strings"
@@ -97,3 +98,43 @@ func HasHelmRelease(release string, kubeconfig string) bool {
 	}
 	return false
 }
+
+// HelmRelease describes an installed helm release as listed by helm.
+type HelmRelease struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Chart      string `json:"chart"`
+	AppVersion string `json:"app_version"`
+	Status     string `json:"status"`
+}
+
+// GetHelmReleases lists the helm releases installed in a namespace.
+func GetHelmReleases(namespace string, kubeconfig string) ([]HelmRelease, error) {
+	args := []string{"list", "-n", namespace, "-o", "json"}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "helm", args...)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to list helm releases in namespace %s"), namespace)
+	}
+
+	var releases []HelmRelease
+	if err := json.Unmarshal(out, &releases); err != nil {
+		return nil, utils.Errorf(err, L("failed to parse helm releases"))
+	}
+	return releases, nil
+}
+
+// GetHelmValues returns the user-supplied values of a helm release in JSON format.
+func GetHelmValues(namespace string, kubeconfig string, release string) ([]byte, error) {
+	args := []string{"get", "values", "-n", namespace, release, "-o", "json"}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	out, err := utils.RunCmdOutput(zerolog.DebugLevel, "helm", args...)
+	if err != nil {
+		return nil, utils.Errorf(err, L("failed to get the values of helm release %s"), release)
+	}
+	return bytes.TrimSpace(out), nil
+}
@@ -322,6 +322,15 @@ func GetRunningImage(container string) (string, error) {
 	return image, nil
 }
 
+// GetImageDigest returns the digest of a local image.
+func GetImageDigest(image string) (string, error) {
+	out, err := runCmdOutput(zerolog.DebugLevel, "podman", "image", "inspect", "--format", "{{.Digest}}", image)
+	if err != nil {
+		return "", utils.Errorf(err, L("failed to get the digest of image %s"), image)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // HasRemoteImage returns true if the image is available remotely.
 //
 // The image has to be a full image with registry, path and tag.
@@ -69,6 +69,11 @@ func GetServicePath(name string) string {
 	return path.Join(servicesPath, name+".service")
 }
 
+// GetTimerPath return the path for a given timer.
+func GetTimerPath(name string) string {
+	return path.Join(servicesPath, name+".timer")
+}
+
 // GetServiceConfFolder return the conf folder for systemd services.
 func GetServiceConfFolder(name string) string {
 	return path.Join(servicesPath, name+".service.d")
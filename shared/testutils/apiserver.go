@@ -0,0 +1,567 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	fakeAPIRoot       = "/rhn/manager/api/"
+	fakeSessionCookie = "pxt-session-cookie"
+)
+
+const (
+	// FakeOrgName is the name of the organization created by LoggedInArgs.
+	FakeOrgName = "myorg"
+	// FakeAdminLogin is the login of the organization administrator created by LoggedInArgs.
+	FakeAdminLogin = "admin"
+	// FakeAdminPassword is the password of the organization administrator created by LoggedInArgs.
+	FakeAdminPassword = "secret"
+)
+
+// FakeOrg is an organization of the fake API server.
+type FakeOrg struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// FakeUser is a user of the fake API server.
+type FakeUser struct {
+	Login     string
+	Password  string
+	FirstName string
+	LastName  string
+	Email     string
+	OrgID     int
+	Admin     bool
+}
+
+// FakeTree is an autoinstallable distribution of the fake API server.
+type FakeTree struct {
+	Label        string `json:"label"`
+	BasePath     string `json:"abs_path"`
+	ChannelLabel string `json:"channel_label"`
+	InstallType  string `json:"install_type"`
+}
+
+// FakePeripheral is a peripheral server registered on the fake API server.
+type FakePeripheral struct {
+	ID               int
+	FQDN             string
+	ReportDBName     string
+	ReportDBHost     string
+	ReportDBPort     int
+	ReportDBUser     string
+	ReportDBPassword string
+}
+
+// FakeAPIHandler handles a call to the fake API server.
+//
+// user is nil for the calls not requiring authentication.
+// The parameters are the decoded JSON body of POST requests or the query parameters of GET requests.
+// The returned error is sent as a failed call message.
+type FakeAPIHandler func(user *FakeUser, params map[string]interface{}) (interface{}, error)
+
+type fakeEndpoint struct {
+	method  string
+	auth    bool
+	handler FakeAPIHandler
+}
+
+// FakeAPIServer is an in-process Uyuni JSON over HTTP API server using TLS.
+//
+// It implements a subset of the API with an in-memory state. More endpoints can be added using Handle.
+type FakeAPIServer struct {
+	*httptest.Server
+
+	mutex     sync.Mutex
+	endpoints map[string]fakeEndpoint
+	sessions  map[string]*FakeUser
+	tokens    map[string]*FakeUser
+	lastID    int
+
+	Orgs         []*FakeOrg
+	Users        []*FakeUser
+	Trees        []*FakeTree
+	Peripherals  []*FakePeripheral
+	ProxyConfigs []map[string]interface{}
+}
+
+// NewFakeAPIServer starts a fake API server, stopped at the end of the test.
+func NewFakeAPIServer(t *testing.T) *FakeAPIServer {
+	s := &FakeAPIServer{
+		endpoints: map[string]fakeEndpoint{},
+		sessions:  map[string]*FakeUser{},
+		tokens:    map[string]*FakeUser{},
+	}
+	s.registerEndpoints()
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Host returns the host and port to use as API server.
+func (s *FakeAPIServer) Host() string {
+	return s.Listener.Addr().String()
+}
+
+// CACertFile writes the server certificate in a temporary file and returns its path.
+func (s *FakeAPIServer) CACertFile(t *testing.T) string {
+	certPath := path.Join(t.TempDir(), "ca.crt")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	if err := os.WriteFile(certPath, cert, 0600); err != nil {
+		t.Fatalf("failed to write the fake API server certificate: %s", err)
+	}
+	return certPath
+}
+
+// AddOrg creates an organization with its administrator.
+func (s *FakeAPIServer) AddOrg(name string, adminLogin string, adminPassword string) *FakeOrg {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	org, _ := s.createOrg(map[string]interface{}{
+		"orgName":       name,
+		"adminLogin":    adminLogin,
+		"adminPassword": adminPassword,
+	})
+	return org
+}
+
+// LoggedInArgs creates an organization with its administrator and returns the flags to call the API as this user.
+//
+// The credentials are stored in plain text in a temporary configuration folder.
+func (s *FakeAPIServer) LoggedInArgs(t *testing.T) []string {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	s.AddOrg(FakeOrgName, FakeAdminLogin, FakeAdminPassword)
+	return []string{"--api-server", s.Host(), "--api-cacert", s.CACertFile(t),
+		"--api-user", FakeAdminLogin, "--api-password", FakeAdminPassword, "--api-credstore", "plain",
+	}
+}
+
+// AddToken creates an API token for a user, to pass as bearer authorization.
+func (s *FakeAPIServer) AddToken(token string, login string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tokens[token] = s.findUser(login)
+}
+
+// Handle adds or replaces an endpoint.
+//
+// The path is relative to the API root, like user/listUsers.
+// Set auth to false for endpoints callable without logging in.
+func (s *FakeAPIServer) Handle(method string, path string, auth bool, handler FakeAPIHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.endpoints[path] = fakeEndpoint{method: method, auth: auth, handler: handler}
+}
+
+func (s *FakeAPIServer) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	endpoint, ok := s.endpoints[strings.TrimPrefix(req.URL.Path, fakeAPIRoot)]
+	if !ok || !strings.HasPrefix(req.URL.Path, fakeAPIRoot) {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != endpoint.method {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var user *FakeUser
+	if cookie, err := req.Cookie(fakeSessionCookie); err == nil {
+		user = s.sessions[cookie.Value]
+	}
+	if token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); found {
+		user = s.tokens[token]
+	}
+	if endpoint.auth && user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	params := map[string]interface{}{}
+	if req.Method == http.MethodPost {
+		if err := json.NewDecoder(req.Body).Decode(&params); err != nil && req.ContentLength != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	} else {
+		for key, values := range req.URL.Query() {
+			params[key] = values[0]
+		}
+	}
+
+	// The login and logout calls need to manage the session cookie
+	switch req.URL.Path {
+	case fakeAPIRoot + "auth/login":
+		s.login(w, params)
+		return
+	case fakeAPIRoot + "auth/logout":
+		if cookie, err := req.Cookie(fakeSessionCookie); err == nil {
+			delete(s.sessions, cookie.Value)
+		}
+	}
+
+	result, err := endpoint.handler(user, params)
+	response := map[string]interface{}{"success": err == nil}
+	if err != nil {
+		response["message"] = err.Error()
+	} else if result != nil {
+		response["result"] = result
+	}
+	writeFakeResponse(w, response)
+}
+
+func (s *FakeAPIServer) login(w http.ResponseWriter, params map[string]interface{}) {
+	login := fmt.Sprint(params["login"])
+	for _, user := range s.Users {
+		if user.Login == login && user.Password == params["password"] {
+			session := make([]byte, 16)
+			_, _ = rand.Read(session)
+			cookie := hex.EncodeToString(session)
+			s.sessions[cookie] = user
+			http.SetCookie(w, &http.Cookie{
+				Name: fakeSessionCookie, Value: cookie, MaxAge: 3600, Path: "/", Secure: true, HttpOnly: true,
+			})
+			writeFakeResponse(w, map[string]interface{}{"success": true})
+			return
+		}
+	}
+	writeFakeResponse(w, map[string]interface{}{
+		"success": false,
+		"message": "Either the password or username is incorrect.",
+	})
+}
+
+func writeFakeResponse(w http.ResponseWriter, response map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *FakeAPIServer) registerEndpoints() {
+	get := http.MethodGet
+	post := http.MethodPost
+	s.endpoints = map[string]fakeEndpoint{
+		"auth/login":  {post, false, nil},
+		"auth/logout": {post, false, func(_ *FakeUser, _ map[string]interface{}) (interface{}, error) { return 1, nil }},
+
+		"org/createFirst": {post, false, s.createFirstOrg},
+		"org/create":      {post, true, s.adminOnly(s.createOrgHandler)},
+		"org/getDetails":  {get, true, s.getOrgDetails},
+		"org/listOrgs":    {get, true, s.adminOnly(s.listOrgs)},
+		"org/listUsers":   {get, true, s.adminOnly(s.listOrgUsers)},
+
+		"user/create":              {post, true, s.createUser},
+		"user/getDetails":          {get, true, s.getUserDetails},
+		"user/listUsers":           {get, true, s.listUsers},
+		"user/listAssignableRoles": {get, true, s.listAssignableRoles},
+
+		"proxy/containerConfig": {post, true, s.proxyContainerConfig},
+
+		"kickstart/tree/create": {post, true, s.createTree},
+		"kickstart/tree/list":   {get, true, s.listTrees},
+		"kickstart/tree/delete": {post, true, s.deleteTree},
+
+		"system/registerPeripheralServer":   {post, true, s.adminOnly(s.registerPeripheral)},
+		"system/updatePeripheralServerInfo": {post, true, s.adminOnly(s.updatePeripheral)},
+	}
+}
+
+func (s *FakeAPIServer) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *FakeAPIServer) adminOnly(handler FakeAPIHandler) FakeAPIHandler {
+	return func(user *FakeUser, params map[string]interface{}) (interface{}, error) {
+		if !user.Admin {
+			return nil, fakeAPIError("Either you are not an administrator or you are not logged in")
+		}
+		return handler(user, params)
+	}
+}
+
+func (s *FakeAPIServer) findUser(login string) *FakeUser {
+	for _, user := range s.Users {
+		if user.Login == login {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *FakeAPIServer) findOrg(name string) *FakeOrg {
+	for _, org := range s.Orgs {
+		if org.Name == name {
+			return org
+		}
+	}
+	return nil
+}
+
+func (s *FakeAPIServer) createOrg(params map[string]interface{}) (*FakeOrg, error) {
+	name := stringParam(params, "orgName")
+	if name == "" || s.findOrg(name) != nil {
+		return nil, fakeAPIError("Invalid organization name: %s", name)
+	}
+	login := stringParam(params, "adminLogin")
+	if login == "" || s.findUser(login) != nil {
+		return nil, fakeAPIError("Invalid login: %s", login)
+	}
+	org := &FakeOrg{ID: s.nextID(), Name: name}
+	s.Orgs = append(s.Orgs, org)
+	s.Users = append(s.Users, &FakeUser{
+		Login:     login,
+		Password:  stringParam(params, "adminPassword"),
+		FirstName: stringParam(params, "firstName"),
+		LastName:  stringParam(params, "lastName"),
+		Email:     stringParam(params, "email"),
+		OrgID:     org.ID,
+		Admin:     true,
+	})
+	return org, nil
+}
+
+func (s *FakeAPIServer) createFirstOrg(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	if len(s.Orgs) > 0 {
+		return nil, fakeAPIError("The first organization has already been created")
+	}
+	return s.createOrg(params)
+}
+
+func (s *FakeAPIServer) createOrgHandler(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	return s.createOrg(params)
+}
+
+func (s *FakeAPIServer) getOrgDetails(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	name := stringParam(params, "name")
+	id, _ := strconv.Atoi(stringParam(params, "orgId"))
+	for _, org := range s.Orgs {
+		if org.Name == name || org.ID == id {
+			return org, nil
+		}
+	}
+	return nil, fakeAPIError("No such organization: %s", name)
+}
+
+func (s *FakeAPIServer) listOrgs(_ *FakeUser, _ map[string]interface{}) (interface{}, error) {
+	return s.Orgs, nil
+}
+
+func (s *FakeAPIServer) createUser(user *FakeUser, params map[string]interface{}) (interface{}, error) {
+	if !user.Admin {
+		return nil, fakeAPIError("Either you are not an administrator or you are not logged in")
+	}
+	login := stringParam(params, "login")
+	if login == "" || s.findUser(login) != nil {
+		return nil, fakeAPIError("Invalid login: %s", login)
+	}
+	s.Users = append(s.Users, &FakeUser{
+		Login:     login,
+		Password:  stringParam(params, "password"),
+		FirstName: stringParam(params, "firstName"),
+		LastName:  stringParam(params, "lastName"),
+		Email:     stringParam(params, "email"),
+		OrgID:     user.OrgID,
+	})
+	return 1, nil
+}
+
+func (s *FakeAPIServer) getUserDetails(user *FakeUser, params map[string]interface{}) (interface{}, error) {
+	found := s.findUser(stringParam(params, "login"))
+	if found == nil || found.OrgID != user.OrgID {
+		return nil, fakeAPIError("No such user: %s", stringParam(params, "login"))
+	}
+	orgName := ""
+	for _, org := range s.Orgs {
+		if org.ID == found.OrgID {
+			orgName = org.Name
+		}
+	}
+	return map[string]interface{}{
+		"first_name": found.FirstName,
+		"last_name":  found.LastName,
+		"email":      found.Email,
+		"org_id":     found.OrgID,
+		"org_name":   orgName,
+		"enabled":    true,
+	}, nil
+}
+
+func (s *FakeAPIServer) listOrgUsers(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	id, _ := strconv.Atoi(stringParam(params, "orgId"))
+	users := []map[string]interface{}{}
+	for _, other := range s.Users {
+		if other.OrgID == id {
+			users = append(users, map[string]interface{}{
+				"login":        other.Login,
+				"name":         other.LastName + ", " + other.FirstName,
+				"email":        other.Email,
+				"is_org_admin": other.Admin,
+			})
+		}
+	}
+	return users, nil
+}
+
+func (s *FakeAPIServer) listUsers(user *FakeUser, _ map[string]interface{}) (interface{}, error) {
+	users := []map[string]interface{}{}
+	for i, other := range s.Users {
+		if other.OrgID == user.OrgID {
+			users = append(users, map[string]interface{}{"id": i + 1, "login": other.Login, "enabled": true})
+		}
+	}
+	return users, nil
+}
+
+func (s *FakeAPIServer) listAssignableRoles(user *FakeUser, _ map[string]interface{}) (interface{}, error) {
+	if user.Admin {
+		return []string{"org_admin", "channel_admin", "config_admin", "system_group_admin", "activation_key_admin"}, nil
+	}
+	return []string{}, nil
+}
+
+// proxyContainerConfig returns a gzipped tar archive with a config.yaml file like the real endpoint.
+func (s *FakeAPIServer) proxyContainerConfig(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	proxyName := stringParam(params, "proxyName")
+	server := stringParam(params, "server")
+	if proxyName == "" || server == "" {
+		return nil, fakeAPIError("proxyName and server are required")
+	}
+	s.ProxyConfigs = append(s.ProxyConfigs, params)
+
+	config := fmt.Sprintf("server: %s\nproxy_fqdn: %s\nmax_cache_size_mb: %s\nemail: %s\n",
+		server, proxyName, stringParam(params, "maxCache"), stringParam(params, "email"),
+	)
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "config.yaml", Mode: 0644, Size: int64(len(config))}); err != nil {
+		return nil, err
+	}
+	if _, err := tarWriter.Write([]byte(config)); err != nil {
+		return nil, err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	// The API returns the archive as an array of signed bytes
+	result := []int8{}
+	for _, b := range buf.Bytes() {
+		result = append(result, int8(b))
+	}
+	return result, nil
+}
+
+func (s *FakeAPIServer) createTree(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	label := stringParam(params, "treeLabel")
+	if label == "" {
+		return nil, fakeAPIError("treeLabel is required")
+	}
+	for _, tree := range s.Trees {
+		if tree.Label == label {
+			return nil, fakeAPIError("Distribution already exists: %s", label)
+		}
+	}
+	s.Trees = append(s.Trees, &FakeTree{
+		Label:        label,
+		BasePath:     stringParam(params, "basePath"),
+		ChannelLabel: stringParam(params, "channelLabel"),
+		InstallType:  stringParam(params, "installType"),
+	})
+	return 1, nil
+}
+
+func (s *FakeAPIServer) listTrees(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	channel := stringParam(params, "channelLabel")
+	trees := []*FakeTree{}
+	for _, tree := range s.Trees {
+		if tree.ChannelLabel == channel {
+			trees = append(trees, tree)
+		}
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].Label < trees[j].Label })
+	return trees, nil
+}
+
+func (s *FakeAPIServer) deleteTree(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	label := stringParam(params, "treeLabel")
+	for i, tree := range s.Trees {
+		if tree.Label == label {
+			s.Trees = append(s.Trees[:i], s.Trees[i+1:]...)
+			return 1, nil
+		}
+	}
+	return nil, fakeAPIError("No such distribution: %s", label)
+}
+
+func (s *FakeAPIServer) registerPeripheral(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	fqdn := stringParam(params, "fqdn")
+	for _, peripheral := range s.Peripherals {
+		if peripheral.FQDN == fqdn {
+			return nil, fakeAPIError("Server already registered: %s", fqdn)
+		}
+	}
+	peripheral := &FakePeripheral{ID: 1000010000 + s.nextID(), FQDN: fqdn}
+	s.Peripherals = append(s.Peripherals, peripheral)
+	return peripheral.ID, nil
+}
+
+func (s *FakeAPIServer) updatePeripheral(_ *FakeUser, params map[string]interface{}) (interface{}, error) {
+	sid, _ := strconv.Atoi(stringParam(params, "sid"))
+	for _, peripheral := range s.Peripherals {
+		if peripheral.ID == sid {
+			peripheral.ReportDBName = stringParam(params, "reportDbName")
+			peripheral.ReportDBHost = stringParam(params, "reportDbHost")
+			peripheral.ReportDBPort, _ = strconv.Atoi(stringParam(params, "reportDbPort"))
+			peripheral.ReportDBUser = stringParam(params, "reportDbUser")
+			peripheral.ReportDBPassword = stringParam(params, "reportDbPassword")
+			return 1, nil
+		}
+	}
+	return nil, fakeAPIError("No such system: %d", sid)
+}
+
+// stringParam returns a parameter as a string, whatever its JSON type.
+func stringParam(params map[string]interface{}, name string) string {
+	value, ok := params[name]
+	if !ok || value == nil {
+		return ""
+	}
+	if number, isNumber := value.(float64); isNumber {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// fakeAPIError returns the message of a failed call, not localized like the server ones.
+func fakeAPIError(format string, args ...interface{}) error {
+	return fmt.Errorf(format, args...)
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testutils_test
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/kickstart"
+	"github.com/uyuni-project/uyuni-tools/shared/api/org"
+	"github.com/uyuni-project/uyuni-tools/shared/api/system"
+	apiTypes "github.com/uyuni-project/uyuni-tools/shared/api/types"
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+	"github.com/uyuni-project/uyuni-tools/shared/types"
+)
+
+func newConnection(t *testing.T, server *testutils.FakeAPIServer, user string, password string) *api.ConnectionDetails {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	return &api.ConnectionDetails{
+		Server:    server.Host(),
+		User:      user,
+		Password:  password,
+		CApath:    server.CACertFile(t),
+		CredStore: api.CredStorePlain,
+	}
+}
+
+func TestFakeServerLogin(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	server.AddOrg("myorg", "admin", "secret")
+
+	client, err := api.Init(newConnection(t, server, "admin", "wrong"))
+	if err != nil {
+		t.Fatalf("failed to initialize the client: %s", err)
+	}
+	testutils.AssertTrue(t, "Login with a wrong password should fail", client.Login() != nil)
+
+	_, err = api.GetResult[[]string](client, "user/listAssignableRoles", nil)
+	testutils.AssertTrue(t, "Calls without session should fail", err != nil)
+
+	client.Details.Password = "secret"
+	if err := client.Login(); err != nil {
+		t.Fatalf("failed to login: %s", err)
+	}
+	roles, err := api.GetResult[[]string](client, "user/listAssignableRoles", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertTrue(t, "Admin should be org_admin", len(roles) > 0 && roles[0] == "org_admin")
+}
+
+func TestFakeServerCreateFirstOrg(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	conn := newConnection(t, server, "admin", "secret")
+
+	admin := apiTypes.User{Login: "admin", Password: "secret", Email: "admin@example.com"}
+	if _, err := org.CreateFirst(conn, "myorg", &admin); err != nil {
+		t.Fatalf("failed to create the first organization: %s", err)
+	}
+	_, err := org.CreateFirst(conn, "otherorg", &admin)
+	testutils.AssertTrue(t, "Only one first organization can be created", err != nil)
+
+	details, err := org.GetOrganizationDetails(conn, "myorg")
+	if err != nil {
+		t.Fatalf("failed to get the organization: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong organization ID", server.Orgs[0].ID, details.ID)
+}
+
+func TestFakeServerState(t *testing.T) {
+	server := testutils.NewFakeAPIServer(t)
+	server.AddOrg("myorg", "admin", "secret")
+	client, err := api.Init(newConnection(t, server, "admin", "secret"))
+	if err == nil {
+		err = client.Login()
+	}
+	if err != nil {
+		t.Fatalf("failed to login: %s", err)
+	}
+
+	distro := types.Distribution{
+		TreeLabel:    "sles15sp6",
+		BasePath:     "/srv/www/distributions/sles15sp6",
+		ChannelLabel: "sle-product-sles15-sp6-pool-x86_64",
+		InstallType:  "sles15generic",
+	}
+	if err := kickstart.CreateTree(client, &distro); err != nil {
+		t.Fatalf("failed to create the tree: %s", err)
+	}
+	testutils.AssertTrue(t, "Duplicate tree not rejected", kickstart.CreateTree(client, &distro) != nil)
+	testutils.AssertEquals(t, "Wrong trees count", 1, len(server.Trees))
+
+	sid, err := system.RegisterPeripheralServer(client, "peripheral.example.com")
+	if err != nil {
+		t.Fatalf("failed to register the peripheral: %s", err)
+	}
+	info := system.PeripheralServerInfo{ID: sid, ReportDBName: "reportdb", ReportDBPort: 5432}
+	if err := system.UpdatePeripheralServerInfo(client, info); err != nil {
+		t.Fatalf("failed to update the peripheral: %s", err)
+	}
+	testutils.AssertEquals(t, "Wrong report DB port", 5432, server.Peripherals[0].ReportDBPort)
+}
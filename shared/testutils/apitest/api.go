@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package apitest provides helpers to test the API calls.
+//
+// It is not part of the testutils package since the API package depends on the utils one,
+// which tests are using testutils.
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/api"
+	"github.com/uyuni-project/uyuni-tools/shared/api/mocks"
+)
+
+// NewMockAPIClient creates an API client sending the requests to the do function.
+//
+// Use testutils.GetResponse in do to generate the responses.
+func NewMockAPIClient(t *testing.T, do func(req *http.Request) (*http.Response, error)) *api.APIClient {
+	connection := api.ConnectionDetails{User: "testUser", Password: "testPwd", Server: "testServer"}
+	client, err := api.Init(&connection)
+	if err != nil {
+		t.Fatalf("failed to initialize the API client: %s", err)
+	}
+	client.Client = &mocks.MockClient{DoFunc: do}
+	return client
+}
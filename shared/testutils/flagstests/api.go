@@ -18,6 +18,10 @@ var APIFlagsTestArgs = []string{
 	"--api-password", "api-pass",
 	"--api-cacert", "path/to/ca.crt",
 	"--api-insecure",
+	"--context", "mycontext",
+	"--api-retries", "5",
+	"--api-credstore", "file",
+	"--api-token", "mytoken",
 }
 
 // AssertAPIFlags checks that all API parameters are parsed correctly.
@@ -27,4 +31,8 @@ func AssertAPIFlags(t *testing.T, flags *api.ConnectionDetails) {
 	testutils.AssertEquals(t, "Error parsing --api-password", "api-pass", flags.Password)
 	testutils.AssertEquals(t, "Error parsing --api-cacert", "path/to/ca.crt", flags.CApath)
 	testutils.AssertTrue(t, "Error parsing --api-insecure", flags.Insecure)
+	testutils.AssertEquals(t, "Error parsing --context", "mycontext", flags.Context)
+	testutils.AssertEquals(t, "Error parsing --api-retries", 5, flags.Retries)
+	testutils.AssertEquals(t, "Error parsing --api-credstore", "file", flags.CredStore)
+	testutils.AssertEquals(t, "Error parsing --api-token", "mytoken", flags.Token)
 }
@@ -25,6 +25,9 @@ const configFilename = "config.yaml"
 // GlobalConfigFilename is the path for the global configuration.
 const GlobalConfigFilename = "/etc/uyuni/uyuni-tools.yaml"
 
+// ConfigKeyAnnotation is an annotation to bind a flag to a configuration key not matching its name.
+const ConfigKeyAnnotation = "uyuni_annotation_config_key"
+
 func addConfigurationFile(v *viper.Viper, cmd *cobra.Command, configFilename string) error {
 	if FileExists(configFilename) {
 		v.SetConfigFile(configFilename)
@@ -59,6 +62,20 @@ func GetUserConfigDir() string {
 	return xdgConfigHome
 }
 
+// GetUserCacheDir returns the path to the user cache directory.
+func GetUserCacheDir() string {
+	xdgCacheHome := os.Getenv("XDG_CACHE_HOME")
+	if xdgCacheHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			log.Warn().Err(err).Msg(L("Failed to find home directory"))
+		} else {
+			xdgCacheHome = path.Join(home, ".cache")
+		}
+	}
+	return xdgCacheHome
+}
+
 // ReadConfig parse configuration file and env variables a return parameters.
 func ReadConfig(cmd *cobra.Command, configPaths ...string) (*viper.Viper, error) {
 	v := viper.New()
@@ -93,6 +110,9 @@ func bindFlags(cmd *cobra.Command, v *viper.Viper) error {
 	var errors []error
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		configName := strings.ReplaceAll(f.Name, "-", ".")
+		if keys := f.Annotations[ConfigKeyAnnotation]; len(keys) > 0 {
+			configName = keys[0]
+		}
 		if err := v.BindPFlag(configName, f); err != nil {
 			errors = append(errors, Errorf(err, L("failed to bind %[1]s config to parameter %[2]s"), configName, f.Name))
 		}
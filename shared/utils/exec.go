@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -77,6 +78,19 @@ func (r *Runner) StdMapping() *Runner {
 	return r
 }
 
+// Stdout streams the process standard output to the writer instead of returning it.
+// This is useful for commands producing large or binary outputs like archives.
+func (r *Runner) Stdout(writer io.Writer) *Runner {
+	r.cmd.Stdout = writer
+	return r
+}
+
+// Stdin feeds the process standard input from the reader.
+func (r *Runner) Stdin(reader io.Reader) *Runner {
+	r.cmd.Stdin = reader
+	return r
+}
+
 // Env sets environment variables to use for the command.
 func (r *Runner) Env(env []string) *Runner {
 	if r.cmd.Env == nil {
@@ -90,14 +104,18 @@ func (r *Runner) Env(env []string) *Runner {
 // The error output to used as error message if the StdMapping() function wasn't called.
 func (r *Runner) Exec() ([]byte, error) {
 	if r.spinner != nil {
-		r.spinner.Start()
+		startSpinner(r.spinner)
 	}
 
 	r.logger.Debug().Msgf("Running: %s", strings.Join(r.cmd.Args, " "))
 	var out []byte
 	var err error
+	var errBuf bytes.Buffer
 
 	if r.cmd.Stdout != nil {
+		if r.cmd.Stderr == nil {
+			r.cmd.Stderr = &errBuf
+		}
 		err = r.cmd.Run()
 	} else {
 		out, err = r.cmd.Output()
@@ -109,6 +127,9 @@ func (r *Runner) Exec() ([]byte, error) {
 
 	var exitErr *exec.ExitError
 	if errors.As(err, &exitErr) {
+		if len(exitErr.Stderr) == 0 {
+			exitErr.Stderr = errBuf.Bytes()
+		}
 		err = &CmdError{exitErr}
 	}
 
@@ -131,13 +152,20 @@ func (e *CmdError) Error() string {
 func RunCmd(command string, args ...string) error {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond) // Build our new spinner
 	s.Suffix = fmt.Sprintf(" %s %s\n", command, strings.Join(args, " "))
-	s.Start() // Start the spinner
+	startSpinner(s)
 	log.Debug().Msgf("Running: %s %s", command, strings.Join(args, " "))
 	err := exec.Command(command, args...).Run()
 	s.Stop()
 	return err
 }
 
+// startSpinner starts the spinner unless progress bars are shown, as it would be drawn over them.
+func startSpinner(s *spinner.Spinner) {
+	if !consoleOutput.redirected() {
+		s.Start()
+	}
+}
+
 // RunCmdStdMapping execute a shell command mapping the stdout and stderr.
 func RunCmdStdMapping(logLevel zerolog.Level, command string, args ...string) error {
 	localLogger := log.Logger.Level(logLevel)
@@ -156,7 +184,7 @@ func RunCmdOutput(logLevel zerolog.Level, command string, args ...string) ([]byt
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond) // Build our new spinner
 	s.Suffix = fmt.Sprintf(" %s %s\n", command, strings.Join(args, " "))
 	if logLevel != zerolog.Disabled {
-		s.Start() // Start the spinner
+		startSpinner(s)
 	}
 	localLogger.Debug().Msgf("Running: %s %s", command, strings.Join(args, " "))
 	cmd := exec.Command(command, args...)
@@ -178,7 +206,7 @@ func RunCmdOutput(logLevel zerolog.Level, command string, args ...string) ([]byt
 func RunCmdInput(command string, input string, args ...string) error {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond) // Build our new spinner
 	s.Suffix = fmt.Sprintf(" %s %s\n", command, strings.Join(args, " "))
-	s.Start() // Start the spinner
+	startSpinner(s)
 	log.Debug().Msgf("Running: %s %s", command, strings.Join(args, " "))
 	cmd := exec.Command(command, args...)
 	cmd.Stdin = strings.NewReader(input)
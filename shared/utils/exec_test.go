@@ -72,6 +72,22 @@ func TestRunner(t *testing.T) {
 	}
 }
 
+func TestRunnerStreams(t *testing.T) {
+	var output strings.Builder
+	_, err := NewRunner("sh", "-c", "tr a-z A-Z").
+		Stdin(strings.NewReader("streamed data")).
+		Stdout(&output).
+		Exec()
+	testutils.AssertEquals(t, "Unexpected error", nil, err)
+	testutils.AssertEquals(t, "Unexpected streamed output", "STREAMED DATA", output.String())
+
+	_, err = NewRunner("sh", "-c", "echo 'stream failure' >&2; exit 1").Stdout(&output).Exec()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	testutils.AssertEquals(t, "Unexpected error message", "stream failure", err.Error())
+}
+
 func ExampleRunner() {
 	out, err := NewRunner("sh", "-c", `echo "Hello $user"`).
 		Env([]string{"user=world"}).
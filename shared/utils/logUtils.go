@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -26,6 +27,39 @@ const logDir = "/var/log/"
 const logFileName = "uyuni-tools.log"
 const GlobalLogPath = logDir + logFileName
 
+// consoleOutput receives the console log messages.
+//
+// The progress bars redirect it while they are shown to write the messages above them.
+var consoleOutput = &redirectableWriter{out: os.Stdout}
+
+// redirectableWriter is a writer which target can be changed.
+type redirectableWriter struct {
+	mutex sync.Mutex
+	out   io.Writer
+}
+
+func (w *redirectableWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.out.Write(p)
+}
+
+// redirected returns whether the messages are not written to the standard output.
+func (w *redirectableWriter) redirected() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.out != os.Stdout
+}
+
+// redirect changes the target of the writer and returns the previous one.
+func (w *redirectableWriter) redirect(out io.Writer) io.Writer {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	previous := w.out
+	w.out = out
+	return previous
+}
+
 // UyuniLogger is an io.WriteCloser that writes to the specified filename.
 type UyuniLogger struct {
 	logger *lumberjack.Logger
@@ -82,6 +116,7 @@ func LogInit(logToConsole bool) {
 	writers := []io.Writer{fileWriter}
 	if logToConsole {
 		consoleWriter := zerolog.NewConsoleWriter()
+		consoleWriter.Out = consoleOutput
 		consoleWriter.NoColor = !term.IsTerminal(int(os.Stdout.Fd()))
 		uyuniConsoleWriter := UyuniConsoleWriter{
 			consoleWriter: consoleWriter,
@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const jsonPathPrefix = "jsonpath="
+
+// AddOutputFlag adds the --output flag to a command and its children.
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("output", "o", "json",
+		L(`Output format. Possible values: json, yaml, table, jsonpath=<expression>.
+When not set, the commands listing or showing objects use table unless stated otherwise.`),
+	)
+}
+
+// ValidateOutputFormat checks that the value of the --output flag is supported.
+func ValidateOutputFormat(format string) error {
+	switch {
+	case format == "json", format == "yaml", format == "table":
+		return nil
+	case strings.HasPrefix(format, jsonPathPrefix):
+		_, err := parseJSONPath(strings.TrimPrefix(format, jsonPathPrefix))
+		return err
+	}
+	return fmt.Errorf(L("unsupported output format: %s"), format)
+}
+
+// GetOutputFormat returns the validated output format, or defaultFormat if the --output flag is not set.
+func GetOutputFormat(cmd *cobra.Command, format string, defaultFormat string) (string, error) {
+	if !cmd.Flags().Changed("output") {
+		format = defaultFormat
+	}
+	if err := ValidateOutputFormat(format); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+// PrintOutput writes data in the requested format.
+//
+// The table format shows lists of objects as columns, single objects as key and value pairs.
+// Any other data is shown as JSON.
+func PrintOutput(out io.Writer, format string, data interface{}) error {
+	data, err := NormalizeNumbers(data)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case format == "json":
+		result, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(result))
+		return err
+	case format == "yaml":
+		result, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(result)
+		return err
+	case format == "table":
+		return printTable(out, data)
+	case strings.HasPrefix(format, jsonPathPrefix):
+		parser, err := parseJSONPath(strings.TrimPrefix(format, jsonPathPrefix))
+		if err != nil {
+			return err
+		}
+		if err := parser.Execute(out, data); err != nil {
+			return Errorf(err, L("failed to apply the JSONPath expression"))
+		}
+		_, err = fmt.Fprintln(out)
+		return err
+	}
+	return fmt.Errorf(L("unsupported output format: %s"), format)
+}
+
+// parseJSONPath parses a JSONPath expression, the surrounding braces are optional.
+func parseJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	parser := jsonpath.New("output")
+	if err := parser.Parse(expression); err != nil {
+		return nil, Errorf(err, L("invalid JSONPath expression"))
+	}
+	return parser, nil
+}
+
+// NormalizeNumbers converts the numbers to integers when possible.
+//
+// Decoding JSON turns all numbers into floats and large IDs would be printed with an exponent.
+func NormalizeNumbers(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return convertNumbers(decoded), nil
+}
+
+func convertNumbers(data interface{}) interface{} {
+	switch value := data.(type) {
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer
+		}
+		float, _ := value.Float64()
+		return float
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = convertNumbers(item)
+		}
+	}
+	return data
+}
+
+func printTable(out io.Writer, data interface{}) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	switch value := data.(type) {
+	case []interface{}:
+		rows := []map[string]interface{}{}
+		for _, item := range value {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				return printTableFallback(out, data)
+			}
+			rows = append(rows, row)
+		}
+		columns := tableColumns(rows)
+		if len(columns) == 0 {
+			return nil
+		}
+		headers := []string{}
+		for _, column := range columns {
+			headers = append(headers, strings.ToUpper(column))
+		}
+		fmt.Fprintln(writer, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			cells := []string{}
+			for _, column := range columns {
+				cells = append(cells, formatCell(row[column]))
+			}
+			fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		}
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(writer, "%s:\t%s\n", key, formatCell(value[key]))
+		}
+	default:
+		return printTableFallback(out, data)
+	}
+	return writer.Flush()
+}
+
+// printTableFallback prints the data that cannot be shown as a table.
+func printTableFallback(out io.Writer, data interface{}) error {
+	if _, isList := data.([]interface{}); isList {
+		return PrintOutput(out, "json", data)
+	}
+	if _, isMap := data.(map[string]interface{}); isMap {
+		return PrintOutput(out, "json", data)
+	}
+	_, err := fmt.Fprintln(out, formatCell(data))
+	return err
+}
+
+// tableColumns returns the sorted keys of all the rows, with id first if present.
+func tableColumns(rows []map[string]interface{}) []string {
+	keys := map[string]bool{}
+	for _, row := range rows {
+		for key := range row {
+			keys[key] = true
+		}
+	}
+	columns := []string{}
+	for key := range keys {
+		if key != "id" {
+			columns = append(columns, key)
+		}
+	}
+	sort.Strings(columns)
+	if keys["id"] {
+		columns = append([]string{"id"}, columns...)
+	}
+	return columns
+}
+
+func formatCell(value interface{}) string {
+	switch cell := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return cell
+	case int64:
+		return strconv.FormatInt(cell, 10)
+	case float64:
+		return strconv.FormatFloat(cell, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(cell)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
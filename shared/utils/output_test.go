@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+const systemsJSON = `[
+	{"id": 1000010000, "name": "minion1", "last_checkin": "2025-03-01"},
+	{"id": 1000010001, "name": "minion2", "entitlements": ["salt"]}
+]`
+
+func decodeJSON(t *testing.T, data string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("failed to decode test data: %s", err)
+	}
+	return decoded
+}
+
+func TestPrintOutput(t *testing.T) {
+	data := map[string]string{
+		"json": `[
+  {
+    "id": 1000010000,
+    "last_checkin": "2025-03-01",
+    "name": "minion1"
+  },
+  {
+    "entitlements": [
+      "salt"
+    ],
+    "id": 1000010001,
+    "name": "minion2"
+  }
+]
+`,
+		"yaml": `- id: 1000010000
+  last_checkin: "2025-03-01"
+  name: minion1
+- entitlements:
+  - salt
+  id: 1000010001
+  name: minion2
+`,
+		"table": `ID           ENTITLEMENTS   LAST_CHECKIN   NAME
+1000010000                  2025-03-01     minion1
+1000010001   ["salt"]                      minion2
+`,
+		"jsonpath={[*].id}":                                 "1000010000 1000010001\n",
+		"jsonpath={range [*]}{.name}{\"\\n\"}{end}":         "minion1\nminion2\n\n",
+		"jsonpath=[?(@.name==\"minion2\")].entitlements[0]": "salt\n",
+	}
+
+	for format, expected := range data {
+		var out bytes.Buffer
+		if err := PrintOutput(&out, format, decodeJSON(t, systemsJSON)); err != nil {
+			t.Errorf("%s: unexpected error: %s", format, err)
+			continue
+		}
+		testutils.AssertEquals(t, "Wrong "+format+" output", expected, out.String())
+	}
+}
+
+func TestPrintOutputTableObject(t *testing.T) {
+	var out bytes.Buffer
+	data := decodeJSON(t, `{"login": "admin", "enabled": true, "org_id": 1}`)
+	if err := PrintOutput(&out, "table", data); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `enabled:   true
+login:     admin
+org_id:    1
+`
+	testutils.AssertEquals(t, "Wrong table output", expected, out.String())
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	valid := []string{"json", "yaml", "table", "jsonpath={.id}", "jsonpath=.id"}
+	for _, format := range valid {
+		testutils.AssertEquals(t, "Unexpected error for "+format, nil, ValidateOutputFormat(format))
+	}
+
+	invalid := []string{"", "xml", "jsonpath={.id"}
+	for _, format := range invalid {
+		testutils.AssertTrue(t, "Expected error for "+format, ValidateOutputFormat(format) != nil)
+	}
+}
@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
+	"golang.org/x/term"
+)
+
+const progressBarWidth = 25
+
+// ProgressBars shows the progress of tasks running concurrently.
+//
+// On a terminal the bars are redrawn below the log messages, otherwise the progress is regularly logged.
+// While the bars are shown on a terminal, the console log messages are written through them.
+type ProgressBars struct {
+	out       io.Writer
+	terminal  bool
+	interval  time.Duration
+	mutex     sync.Mutex
+	items     []*ProgressItem
+	stop      chan bool
+	stopped   chan bool
+	logOutput io.Writer
+}
+
+// ProgressItem is a task of the progress bars.
+type ProgressItem struct {
+	name    string
+	total   int64
+	current func() int64
+	done    bool
+	err     error
+}
+
+// NewProgressBars creates progress bars writing to the standard output.
+func NewProgressBars() *ProgressBars {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		return newProgressBars(os.Stdout, true, 500*time.Millisecond)
+	}
+	return newProgressBars(nil, false, time.Minute)
+}
+
+func newProgressBars(out io.Writer, terminal bool, interval time.Duration) *ProgressBars {
+	return &ProgressBars{out: out, terminal: terminal, interval: interval}
+}
+
+// FileSizeProgress returns a function giving the size of a file being written.
+func FileSizeProgress(file string) func() int64 {
+	return func() int64 {
+		info, err := os.Stat(file)
+		if err != nil {
+			return 0
+		}
+		return info.Size()
+	}
+}
+
+// Add starts showing the progress of a task.
+//
+// total is the expected size of the task and current is called to get the size already processed.
+func (p *ProgressBars) Add(name string, total int64, current func() int64) *ProgressItem {
+	item := ProgressItem{name: name, total: total, current: current}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.items = append(p.items, &item)
+	return &item
+}
+
+// Done marks the task as finished, err is the error the task may have ended with.
+func (p *ProgressBars) Done(item *ProgressItem, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	item.done = true
+	item.err = err
+}
+
+// Start regularly refreshes the progress until Stop is called.
+func (p *ProgressBars) Start() {
+	if p.terminal {
+		p.logOutput = consoleOutput.redirect(p)
+	}
+	p.stop = make(chan bool)
+	p.stopped = make(chan bool)
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				p.render(true)
+				close(p.stopped)
+				return
+			case <-ticker.C:
+				p.render(false)
+			}
+		}
+	}()
+}
+
+// Stop shows the final state of the progress bars.
+func (p *ProgressBars) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+	p.stop = nil
+	if p.logOutput != nil {
+		consoleOutput.redirect(p.logOutput)
+		p.logOutput = nil
+	}
+}
+
+// Write shows a log message above the progress bars and redraws them.
+func (p *ProgressBars) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var buffer bytes.Buffer
+	// The cursor is on the first line of the bars: clear them before writing the message
+	buffer.WriteString("\033[J")
+	buffer.Write(data)
+	for _, item := range p.items {
+		buffer.WriteString(item.line())
+		buffer.WriteString("\n")
+	}
+	if len(p.items) > 0 {
+		fmt.Fprintf(&buffer, "\033[%dA", len(p.items))
+	}
+	if _, err := p.out.Write(buffer.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// render shows the progress of the running tasks, the finished ones are shown one last time.
+func (p *ProgressBars) render(final bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	running := []*ProgressItem{}
+	var buffer bytes.Buffer
+	// Clear the previous bars as the cursor was moved back to their first line
+	buffer.WriteString("\033[J")
+	for _, item := range p.items {
+		if !item.done {
+			running = append(running, item)
+		}
+		if p.terminal {
+			buffer.WriteString(item.line())
+			buffer.WriteString("\n")
+		} else if !item.done {
+			log.Info().Msgf(L("Progress of %[1]s: %[2]s of %[3]s"),
+				item.name, formatSize(item.current()), formatSize(item.total),
+			)
+		}
+	}
+	// The finished tasks stay above the redrawn bars
+	p.items = running
+
+	if !p.terminal {
+		return
+	}
+	if !final && len(running) > 0 {
+		fmt.Fprintf(&buffer, "\033[%dA", len(running))
+	}
+	_, _ = p.out.Write(buffer.Bytes())
+}
+
+func (i *ProgressItem) line() string {
+	current := i.current()
+	if i.done && i.err == nil {
+		current = max(current, i.total)
+	}
+	percent := int64(0)
+	if i.total > 0 {
+		percent = min(current*100/i.total, 100)
+	}
+	if !i.done {
+		percent = min(percent, 99)
+	}
+	filled := int(percent * progressBarWidth / 100)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+
+	status := fmt.Sprintf("%3d%%", percent)
+	if i.err != nil {
+		status = L("failed")
+	}
+	return fmt.Sprintf("%-30s [%s] %s %s / %s", i.name, bar, status, formatSize(current), formatSize(i.total))
+}
+
+// formatSize returns a human readable size.
+func formatSize(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", size, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestProgressBarsRender(t *testing.T) {
+	var out bytes.Buffer
+	progress := newProgressBars(&out, true, time.Hour)
+
+	volume := progress.Add("var-spacewalk", 2048, func() int64 { return 1024 })
+	image := progress.Add("server", 100, func() int64 { return 10 })
+
+	progress.render(false)
+	lines := strings.Split(out.String(), "\n")
+	testutils.AssertEquals(t, "wrong number of lines", 3, len(lines))
+	testutils.AssertTrue(t, "missing volume progress: "+lines[0], strings.Contains(lines[0], " 50% 1.0 KiB / 2.0 KiB"))
+	testutils.AssertTrue(t, "missing image progress: "+lines[1], strings.Contains(lines[1], " 10% 10 B / 100 B"))
+	testutils.AssertEquals(t, "cursor should move up to the first bar", "\033[2A", lines[2])
+
+	out.Reset()
+	progress.Done(volume, nil)
+	progress.Done(image, errors.New("failed"))
+	progress.render(false)
+	testutils.AssertTrue(t, "volume should be complete: "+out.String(),
+		strings.Contains(out.String(), "[#########################] 100% 2.0 KiB / 2.0 KiB"),
+	)
+	testutils.AssertTrue(t, "image should have failed", strings.Contains(out.String(), "failed"))
+	testutils.AssertTrue(t, "finished bars should stay", !strings.Contains(out.String(), "\033[2A"))
+
+	out.Reset()
+	progress.render(false)
+	testutils.AssertEquals(t, "finished bars should not be redrawn", "\033[J", out.String())
+}
+
+func TestProgressBarsLogs(t *testing.T) {
+	var out bytes.Buffer
+	progress := newProgressBars(&out, true, time.Hour)
+	progress.Add("var-spacewalk", 2048, func() int64 { return 1024 })
+
+	previous := consoleOutput.redirect(&bytes.Buffer{})
+	defer consoleOutput.redirect(previous)
+
+	progress.Start()
+	if _, err := consoleOutput.Write([]byte("Run podman image save\n")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	testutils.AssertEquals(t, "wrong number of lines", 3, len(lines))
+	testutils.AssertEquals(t, "log not written above the bars", "\033[JRun podman image save", lines[0])
+	testutils.AssertTrue(t, "bar not redrawn: "+lines[1], strings.Contains(lines[1], " 50% 1.0 KiB / 2.0 KiB"))
+	testutils.AssertEquals(t, "cursor should move up to the first bar", "\033[1A", lines[2])
+
+	progress.Stop()
+	out.Reset()
+	if _, err := consoleOutput.Write([]byte("done\n")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.AssertEquals(t, "logs still written through the bars", "", out.String())
+}
+
+func TestFormatSize(t *testing.T) {
+	testutils.AssertEquals(t, "wrong bytes size", "512 B", formatSize(512))
+	testutils.AssertEquals(t, "wrong MiB size", "1.5 MiB", formatSize(1536*1024))
+	testutils.AssertEquals(t, "wrong GiB size", "3.0 GiB", formatSize(3*1024*1024*1024))
+}
@@ -8,10 +8,13 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	. "github.com/uyuni-project/uyuni-tools/shared/l10n"
@@ -130,3 +133,231 @@ func (t *TarGz) AddFile(filepath string, entrypath string) error {
 	}
 	return nil
 }
+
+// WriteTarTree adds the file, symbolic link or directory tree at srcPath to the archive as entryName.
+//
+// Only the permissions and modification times are stored, the ownership is left to the extracting side.
+func WriteTarTree(tarWriter *tar.Writer, srcPath string, entryName string) error {
+	return filepath.Walk(srcPath, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcPath, file)
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(entryName, filepath.ToSlash(relPath))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		header.Uid = 0
+		header.Gid = 0
+		header.Uname = ""
+		header.Gname = ""
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		reader, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		_, err = io.Copy(tarWriter, reader)
+		return err
+	})
+}
+
+// TreeSize returns the size of the regular files in the tree at srcPath.
+func TreeSize(srcPath string) (int64, error) {
+	var size int64
+	err := filepath.Walk(srcPath, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return err
+	})
+	return size, err
+}
+
+// ExtractTar extracts an uncompressed tar stream to dstPath.
+//
+// If rootName is not empty, it replaces the first component of the entries names to extract under another name.
+// If preserve is true, the permissions and modification times of the entries are restored, otherwise the
+// permissions are filtered by the umask and the modification time is the extraction one.
+func ExtractTar(reader io.Reader, dstPath string, rootName string, preserve bool) error {
+	dstPath, err := filepath.Abs(dstPath)
+	if err != nil {
+		return err
+	}
+
+	type dirTimes struct {
+		path    string
+		modTime time.Time
+	}
+	dirs := []dirTimes{}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		target, err := getExtractPath(dstPath, header.Name, rootName)
+		if err != nil {
+			return err
+		}
+		mode := header.FileInfo().Mode()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := RemoveSymlink(target); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(target, mode.Perm()|0700); err != nil {
+				return err
+			}
+			if preserve {
+				dirs = append(dirs, dirTimes{target, header.ModTime})
+			}
+		case tar.TypeReg:
+			if err := extractFile(tarReader, target, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			continue
+		case tar.TypeLink:
+			linkTarget, err := getExtractPath(dstPath, header.Linkname, rootName)
+			if err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			if err := os.Link(linkTarget, target); err != nil {
+				return err
+			}
+			continue
+		default:
+			log.Warn().Msgf(L("Skipping %s as its type is not supported"), header.Name)
+			continue
+		}
+
+		if preserve {
+			if err := os.Chmod(target, mode.Perm()|mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+				return err
+			}
+			if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Extracting the content of the directories changed their modification time
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chtimes(dirs[i].path, dirs[i].modTime, dirs[i].modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getExtractPath computes the path to extract an entry to, refusing those resolving outside dstPath.
+//
+// Entries going through a symbolic link, like one extracted earlier from the same archive, are refused too.
+func getExtractPath(dstPath string, name string, rootName string) (string, error) {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if rootName != "" {
+		parts := strings.SplitN(name, "/", 2)
+		parts[0] = rootName
+		name = path.Join(parts...)
+	}
+	target, err := JoinInRoot(dstPath, name)
+	if err != nil {
+		return "", err
+	}
+	if target != dstPath && !strings.HasPrefix(target, dstPath+string(filepath.Separator)) {
+		return "", fmt.Errorf(L("%s resolves outside the target path"), name)
+	}
+	return target, nil
+}
+
+func extractFile(reader io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	// Replace an existing link rather than writing to the file it points to
+	if err := RemoveSymlink(target); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+// JoinInRoot joins name to the root folder without going outside of it.
+//
+// The existing parents of the resulting path are checked to be real directories: a symbolic link in the way would
+// redirect the writes outside of root. The last component is not checked.
+func JoinInRoot(root string, name string) (string, error) {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if name == "" {
+		return root, nil
+	}
+
+	parent := root
+	parts := strings.Split(name, "/")
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		info, err := os.Lstat(parent)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf(L("refusing to write through symbolic link %s"), parent)
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf(L("%s is not a directory"), parent)
+		}
+	}
+	return filepath.Join(root, filepath.FromSlash(name)), nil
+}
+
+// RemoveSymlink removes the file at target if it is a symbolic link.
+//
+// This avoids following a link when writing to or changing the attributes of target.
+func RemoveSymlink(target string) error {
+	info, err := os.Lstat(target)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	return os.Remove(target)
+}
@@ -5,10 +5,13 @@
 package utils
 
 import (
+	"archive/tar"
+	"bytes"
 	"os"
 	"os/exec"
 	"path"
 	"testing"
+	"time"
 )
 
 const dataDir = "data"
@@ -113,3 +116,120 @@ func TestExtractTarGz(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteAndExtractTarTree(t *testing.T) {
+	tmpDir := setup(t)
+	dataPath := path.Join(tmpDir, dataDir)
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path.Join(dataPath, "file1"), modTime, modTime); err != nil {
+		t.Fatalf("failed to set test file time: %s", err)
+	}
+	if err := os.Symlink("file1", path.Join(dataPath, "link")); err != nil {
+		t.Fatalf("failed to create test link: %s", err)
+	}
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	if err := WriteTarTree(tarWriter, dataPath, "data"); err != nil {
+		t.Fatalf("failed to write tar: %s", err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar: %s", err)
+	}
+
+	// Extract with another root name and preserving the times
+	testDir := path.Join(tmpDir, outDir)
+	if err := ExtractTar(bytes.NewReader(buf.Bytes()), testDir, "copy", true); err != nil {
+		t.Fatalf("failed to extract tar: %s", err)
+	}
+
+	for name, content := range filesData {
+		if out, err := os.ReadFile(path.Join(testDir, "copy", name)); err != nil {
+			t.Errorf("failed to read %s: %s", name, err)
+		} else if string(out) != content {
+			t.Errorf("expected %s content %s, but got %s", name, content, string(out))
+		}
+	}
+
+	info, err := os.Stat(path.Join(testDir, "copy", "file1"))
+	if err != nil {
+		t.Fatalf("failed to stat extracted file: %s", err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("expected modification time %s, got %s", modTime, info.ModTime())
+	}
+	if link, err := os.Readlink(path.Join(testDir, "copy", "link")); err != nil || link != "file1" {
+		t.Errorf("expected link to file1, got %s: %v", link, err)
+	}
+
+	size, err := TreeSize(dataPath)
+	if err != nil {
+		t.Fatalf("failed to compute size: %s", err)
+	}
+	if size != int64(len(file1Content)+len(filesData["sub/file2"])) {
+		t.Errorf("unexpected tree size %d", size)
+	}
+}
+
+func TestExtractTarOutside(t *testing.T) {
+	dstPath := t.TempDir()
+	data := map[string]string{
+		"../outside":   path.Join(dstPath, "outside"),
+		"a/../../b":    path.Join(dstPath, "b"),
+		"dir/file":     path.Join(dstPath, "dir/file"),
+		"/etc/passwd":  path.Join(dstPath, "etc/passwd"),
+		"renamed/file": path.Join(dstPath, "root/file"),
+	}
+	for name, expected := range data {
+		rootName := ""
+		if name == "renamed/file" {
+			rootName = "root"
+		}
+		actual, err := getExtractPath(dstPath, name, rootName)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", name, err)
+		} else if actual != expected {
+			t.Errorf("expected %s to be extracted to %s, got %s", name, expected, actual)
+		}
+	}
+}
+
+func TestExtractTarSymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(path.Join(outside, "authorized_keys"), []byte("good key"), 0600); err != nil {
+		t.Fatalf("failed to write test file: %s", err)
+	}
+
+	link := tar.Header{Name: "x", Linkname: outside, Typeflag: tar.TypeSymlink, Mode: 0777}
+	data := map[string][]tar.Header{
+		"symlink then child": {link, {Name: "x/authorized_keys", Size: 8, Typeflag: tar.TypeReg, Mode: 0600}},
+		"symlink then dir":   {link, {Name: "x/sub/", Typeflag: tar.TypeDir, Mode: 0755}},
+		"hard link":          {link, {Name: "keys", Linkname: "x/authorized_keys", Typeflag: tar.TypeLink}},
+	}
+
+	for testCase, headers := range data {
+		var buf bytes.Buffer
+		tarWriter := tar.NewWriter(&buf)
+		for _, header := range headers {
+			if err := tarWriter.WriteHeader(&header); err != nil {
+				t.Fatalf("failed to write header: %s", err)
+			}
+			if header.Size > 0 {
+				if _, err := tarWriter.Write([]byte("evil key")); err != nil {
+					t.Fatalf("failed to write content: %s", err)
+				}
+			}
+		}
+		tarWriter.Close()
+
+		if err := ExtractTar(bytes.NewReader(buf.Bytes()), t.TempDir(), "", true); err == nil {
+			t.Errorf("%s: extracting through a symbolic link should fail", testCase)
+		}
+		if out, err := os.ReadFile(path.Join(outside, "authorized_keys")); err != nil || string(out) != "good key" {
+			t.Errorf("%s: file outside the target changed: %s, %v", testCase, string(out), err)
+		}
+		if FileExists(path.Join(outside, "sub")) {
+			t.Errorf("%s: directory created outside the target", testCase)
+		}
+	}
+}
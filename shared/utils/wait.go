@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import "time"
+
+// WaitFor calls check every interval until it returns true or an error.
+//
+// Returns false if the timeout expired before, a timeout of 0 waits forever.
+func WaitFor(timeout time.Duration, interval time.Duration, check func() (bool, error)) (bool, error) {
+	start := time.Now()
+	for {
+		done, err := check()
+		if err != nil || done {
+			return done, err
+		}
+		if timeout > 0 && time.Since(start)+interval > timeout {
+			return false, nil
+		}
+		time.Sleep(interval)
+	}
+}
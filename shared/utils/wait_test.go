@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2025 SUSE LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/uyuni-tools/shared/testutils"
+)
+
+func TestWaitFor(t *testing.T) {
+	calls := 0
+	done, err := WaitFor(time.Second, time.Millisecond, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	testutils.AssertTrue(t, "Expected to be done", done && err == nil)
+	testutils.AssertEquals(t, "Wrong calls count", 3, calls)
+
+	done, err = WaitFor(5*time.Millisecond, time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	testutils.AssertTrue(t, "Expected a timeout", !done && err == nil)
+
+	failure := errors.New("failure")
+	_, err = WaitFor(0, time.Millisecond, func() (bool, error) {
+		return false, failure
+	})
+	testutils.AssertTrue(t, "Expected the check error", errors.Is(err, failure))
+}